@@ -0,0 +1,65 @@
+package activity
+
+import (
+	"time"
+)
+
+// Day is the time a user was active during one day
+type Day struct {
+	Date   time.Time // midnight, in the location of the tracker
+	Active time.Duration
+}
+
+// ActiveTime returns the total time user was active, up to now
+func (tracker *Tracker) ActiveTime(user string) time.Duration {
+	var total time.Duration
+	for _, session := range tracker.Sessions(user) {
+		total += session.Duration()
+	}
+	return total
+}
+
+// ActiveBetween returns the time user was active from from to to, excluded
+func (tracker *Tracker) ActiveBetween(user string, from, to time.Time) time.Duration {
+	var total time.Duration
+	for _, session := range tracker.Sessions(user) {
+		start, end := session.Start, session.End
+		if start.Before(from) {
+			start = from
+		}
+		if end.After(to) {
+			end = to
+		}
+		if end.After(start) {
+			total += end.Sub(start)
+		}
+	}
+	return total
+}
+
+// Daily returns the time user was active on each day with some activity,
+// oldest first; a session across midnight counts on both days
+func (tracker *Tracker) Daily(user string) []Day {
+	var days []Day
+	for _, session := range tracker.Sessions(user) {
+		start := session.Start.In(tracker.location)
+		for start.Before(session.End) {
+			date := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, tracker.location)
+			// not date.Add(24 * time.Hour): days are 23 or 25 hours long when
+			// daylight saving time starts or ends
+			next := time.Date(start.Year(), start.Month(), start.Day()+1, 0, 0, 0, 0, tracker.location)
+			end := session.End
+			if next.Before(end) {
+				end = next
+			}
+
+			if len(days) > 0 && days[len(days)-1].Date.Equal(date) {
+				days[len(days)-1].Active += end.Sub(start)
+			} else {
+				days = append(days, Day{Date: date, Active: end.Sub(start)})
+			}
+			start = end
+		}
+	}
+	return days
+}
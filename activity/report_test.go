@@ -0,0 +1,62 @@
+package activity
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestReports(t *testing.T) {
+	tracker, _ := NewTracker(time.Hour, time.UTC)
+	tracker.now = func() time.Time { return at(23, 59).Add(24 * time.Hour) }
+	events := []Event{
+		{User: "ann", Kind: Login, At: at(9, 0)},
+		{User: "ann", Kind: Logout, At: at(10, 0)},
+		{User: "ann", Kind: Login, At: at(23, 30)},
+		{User: "ann", Kind: Heartbeat, At: at(0, 15).Add(24 * time.Hour)},
+		{User: "ann", Kind: Logout, At: at(0, 45).Add(24 * time.Hour)},
+	}
+	for _, event := range events {
+		tracker.Record(event)
+	}
+
+	if got := tracker.ActiveTime("ann"); got != 2*time.Hour+15*time.Minute {
+		t.Errorf("ActiveTime = %v, want 2h15m", got)
+	}
+	if got := tracker.ActiveBetween("ann", at(9, 30), at(23, 45)); got != 45*time.Minute {
+		t.Errorf("ActiveBetween = %v, want 45m", got)
+	}
+	want := []Day{
+		{Date: at(0, 0), Active: 90 * time.Minute},
+		{Date: at(0, 0).Add(24 * time.Hour), Active: 45 * time.Minute},
+	}
+	if got := tracker.Daily("ann"); !slices.Equal(got, want) {
+		t.Errorf("Daily = %v, want %v", got, want)
+	}
+}
+
+func TestDailyDST(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	if err != nil {
+		t.Skip(err)
+	}
+	// 29 October 2023 has 25 hours in Paris
+	start := time.Date(2023, time.October, 28, 23, 0, 0, 0, paris)
+	end := time.Date(2023, time.October, 30, 1, 0, 0, 0, paris)
+	tracker, _ := NewTracker(time.Hour, paris)
+	tracker.now = func() time.Time { return end }
+	tracker.Record(Event{User: "ann", Kind: Login, At: start})
+	for beat := start.Add(30 * time.Minute); beat.Before(end); beat = beat.Add(30 * time.Minute) {
+		tracker.Record(Event{User: "ann", Kind: Heartbeat, At: beat})
+	}
+	tracker.Record(Event{User: "ann", Kind: Logout, At: end})
+
+	want := []time.Duration{time.Hour, 25 * time.Hour, time.Hour}
+	var got []time.Duration
+	for _, day := range tracker.Daily("ann") {
+		got = append(got, day.Active)
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("Daily = %v, want %v", got, want)
+	}
+}
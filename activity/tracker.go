@@ -0,0 +1,222 @@
+package activity
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+/*
+
+Tracker turns login, logout and heartbeat events into the time users were
+active, instead of an activeTime counter someone has to remember to update.
+
+A session starts at a login and ends at the logout, or when the user goes
+quiet for longer than the idle timeout: every event proves the user was there
+at that moment, and the session lasts until idleTimeout after the last one.
+
+	login 9:00, heartbeat 9:10, heartbeat 9:20, logout 9:25     9:00 to 9:25
+	login 9:00, heartbeat 9:10, nothing else, idle timeout 15m  9:00 to 9:25
+
+A session that timed out is over: heartbeats after it are ignored until the
+next login, as are heartbeats without a login and a second login in the same
+session. So clients should send heartbeats well within the idle timeout.
+
+Nothing is computed when the events come in, every report replays the
+history of the user, so events can be recorded out of order (from a log, for
+instance) and the reports are always consistent with the history. The history
+is kept in memory until Forget.
+
+*/
+
+var (
+	ErrUser        = errors.New("activity: empty user")
+	ErrKind        = errors.New("activity: unknown event kind")
+	ErrIdleTimeout = errors.New("activity: idle timeout must be positive")
+)
+
+type Kind int
+
+const (
+	Login Kind = iota
+	Logout
+	Heartbeat
+)
+
+func (kind Kind) String() string {
+	switch kind {
+	case Login:
+		return "login"
+	case Logout:
+		return "logout"
+	case Heartbeat:
+		return "heartbeat"
+	}
+	return fmt.Sprintf("Kind(%d)", int(kind))
+}
+
+type Event struct {
+	User string
+	Kind Kind
+	At   time.Time
+}
+
+// Interval is a session, from Start to End excluded
+type Interval struct {
+	Start, End time.Time
+}
+
+func (interval Interval) Duration() time.Duration {
+	return interval.End.Sub(interval.Start)
+}
+
+type Tracker struct {
+	idleTimeout time.Duration
+	location    *time.Location // where days start and end, for Daily
+	now         func() time.Time
+
+	mu     sync.RWMutex
+	events map[string][]Event // by user, sorted by time
+}
+
+// NewTracker returns a tracker ending sessions after idleTimeout without
+// events, and splitting days at midnight in location (time.Local when nil)
+func NewTracker(idleTimeout time.Duration, location *time.Location) (*Tracker, error) {
+	if idleTimeout <= 0 {
+		return nil, ErrIdleTimeout
+	}
+	if location == nil {
+		location = time.Local
+	}
+	return &Tracker{
+		idleTimeout: idleTimeout,
+		location:    location,
+		now:         time.Now,
+		events:      make(map[string][]Event),
+	}, nil
+}
+
+// Record adds an event to the history of its user, whenever it happened
+func (tracker *Tracker) Record(event Event) error {
+	if event.User == "" {
+		return ErrUser
+	}
+	if event.Kind < Login || event.Kind > Heartbeat {
+		return fmt.Errorf("%w: %v", ErrKind, event.Kind)
+	}
+
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+	events := tracker.events[event.User]
+	// after the events at the same time, so those keep the order they were recorded in
+	index, _ := slices.BinarySearchFunc(events, event.At, func(recorded Event, at time.Time) int {
+		if recorded.At.After(at) {
+			return 1
+		}
+		return -1
+	})
+	tracker.events[event.User] = slices.Insert(events, index, event)
+	return nil
+}
+
+// Login records that user logged in now
+func (tracker *Tracker) Login(user string) error {
+	return tracker.Record(Event{User: user, Kind: Login, At: tracker.now()})
+}
+
+// Logout records that user logged out now
+func (tracker *Tracker) Logout(user string) error {
+	return tracker.Record(Event{User: user, Kind: Logout, At: tracker.now()})
+}
+
+// Heartbeat records that user is still there now
+func (tracker *Tracker) Heartbeat(user string) error {
+	return tracker.Record(Event{User: user, Kind: Heartbeat, At: tracker.now()})
+}
+
+// Forget removes the history of user, it reports whether there was one
+func (tracker *Tracker) Forget(user string) bool {
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+	_, ok := tracker.events[user]
+	delete(tracker.events, user)
+	return ok
+}
+
+// Known reports whether there are events for user
+func (tracker *Tracker) Known(user string) bool {
+	tracker.mu.RLock()
+	defer tracker.mu.RUnlock()
+	return len(tracker.events[user]) > 0
+}
+
+// Sessions returns the sessions of user, oldest first, the last one still
+// open when it ends now
+func (tracker *Tracker) Sessions(user string) []Interval {
+	now := tracker.now()
+	tracker.mu.RLock()
+	defer tracker.mu.RUnlock()
+	intervals, _ := sessions(tracker.events[user], tracker.idleTimeout, now)
+	return intervals
+}
+
+// IsActive reports whether user is in a session now
+func (tracker *Tracker) IsActive(user string) bool {
+	now := tracker.now()
+	tracker.mu.RLock()
+	defer tracker.mu.RUnlock()
+	_, active := sessions(tracker.events[user], tracker.idleTimeout, now)
+	return active
+}
+
+// sessions replays events, sorted by time, up to now; active reports whether
+// the last session is still open
+func sessions(events []Event, idleTimeout time.Duration, now time.Time) (intervals []Interval, active bool) {
+	var (
+		open     bool
+		start    time.Time
+		lastSeen time.Time
+	)
+	end := func(at time.Time) {
+		if at.After(start) {
+			intervals = append(intervals, Interval{Start: start, End: at})
+		}
+		open = false
+	}
+
+	for _, event := range events {
+		if event.At.After(now) {
+			break
+		}
+		if open && event.At.Sub(lastSeen) > idleTimeout {
+			end(lastSeen.Add(idleTimeout))
+		}
+		switch event.Kind {
+		case Login:
+			if !open {
+				open, start = true, event.At
+			}
+			lastSeen = event.At
+		case Heartbeat:
+			if open {
+				lastSeen = event.At
+			}
+		case Logout:
+			if open {
+				end(event.At)
+			}
+		}
+	}
+	if !open {
+		return intervals, false
+	}
+	timeout := lastSeen.Add(idleTimeout)
+	if now.Before(timeout) {
+		end(now)
+		return intervals, true
+	}
+	end(timeout)
+	return intervals, false
+}
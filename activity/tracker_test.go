@@ -0,0 +1,84 @@
+package activity
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+// at returns 9 October 2023 at hour:minute in UTC
+func at(hour, minute int) time.Time {
+	return time.Date(2023, time.October, 9, hour, minute, 0, 0, time.UTC)
+}
+
+func newTestTracker(t *testing.T, now time.Time) *Tracker {
+	t.Helper()
+	tracker, err := NewTracker(15*time.Minute, time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracker.now = func() time.Time { return now }
+	return tracker
+}
+
+func TestSessions(t *testing.T) {
+	type event struct {
+		kind         Kind
+		hour, minute int
+	}
+	tests := []struct {
+		name   string
+		events []event
+		want   []Interval
+		active bool
+	}{
+		{"logout", []event{{Login, 9, 0}, {Heartbeat, 9, 10}, {Heartbeat, 9, 20}, {Logout, 9, 25}},
+			[]Interval{{at(9, 0), at(9, 25)}}, false},
+		{"idle timeout", []event{{Login, 9, 0}, {Heartbeat, 9, 10}},
+			[]Interval{{at(9, 0), at(9, 25)}}, false},
+		{"heartbeat after the timeout", []event{{Login, 9, 0}, {Heartbeat, 9, 30}, {Logout, 9, 40}},
+			[]Interval{{at(9, 0), at(9, 15)}}, false},
+		{"heartbeat without login", []event{{Heartbeat, 9, 0}, {Logout, 9, 5}}, nil, false},
+		{"second login", []event{{Login, 9, 0}, {Login, 9, 10}, {Logout, 9, 20}},
+			[]Interval{{at(9, 0), at(9, 20)}}, false},
+		{"two sessions", []event{{Login, 9, 0}, {Logout, 9, 5}, {Login, 11, 0}, {Heartbeat, 11, 15}, {Logout, 11, 30}},
+			[]Interval{{at(9, 0), at(9, 5)}, {at(11, 0), at(11, 30)}}, false},
+		{"open", []event{{Login, 11, 50}}, []Interval{{at(11, 50), at(12, 0)}}, true},
+		{"future events", []event{{Login, 11, 0}, {Logout, 11, 10}, {Login, 13, 0}},
+			[]Interval{{at(11, 0), at(11, 10)}}, false},
+	}
+	for _, test := range tests {
+		tracker := newTestTracker(t, at(12, 0))
+		// recorded backwards, the order of the history is the order of the times
+		for _, e := range slices.Backward(test.events) {
+			if err := tracker.Record(Event{User: "ann", Kind: e.kind, At: at(e.hour, e.minute)}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if got := tracker.Sessions("ann"); !slices.Equal(got, test.want) {
+			t.Errorf("%s: Sessions = %v, want %v", test.name, got, test.want)
+		}
+		if got := tracker.IsActive("ann"); got != test.active {
+			t.Errorf("%s: IsActive = %v, want %v", test.name, got, test.active)
+		}
+	}
+}
+
+func TestRecordErrors(t *testing.T) {
+	tracker := newTestTracker(t, at(12, 0))
+	if err := tracker.Record(Event{Kind: Login}); !errors.Is(err, ErrUser) {
+		t.Errorf("Record without user error %v, want ErrUser", err)
+	}
+	if err := tracker.Record(Event{User: "ann", Kind: Kind(7)}); !errors.Is(err, ErrKind) {
+		t.Errorf("Record(Kind(7)) error %v, want ErrKind", err)
+	}
+	if _, err := NewTracker(0, nil); !errors.Is(err, ErrIdleTimeout) {
+		t.Errorf("NewTracker(0) error %v, want ErrIdleTimeout", err)
+	}
+
+	tracker.Login("ann")
+	if !tracker.Known("ann") || !tracker.Forget("ann") || tracker.Known("ann") || tracker.Forget("ann") {
+		t.Error("Forget didn't remove the history of ann")
+	}
+}
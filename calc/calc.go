@@ -0,0 +1,328 @@
+package main
+
+/*
+
+calc evaluates integer arithmetic expressions.
+
+	go run ./calc                  interactive REPL
+	echo "1 + 2" | go run ./calc   non-interactive, one expression per line
+
+Everything the expr package understands is accepted, plus:
+
+	x = 3 * 4             assign a variable
+	area(w, h) = w * h    define a function
+	sq = self(mult)       define sq(x) = mult(x, x), like selfMath in main
+	ans                   the result of the last expression
+
+In the REPL, lines starting with ':' are commands (see :help), and !! or !n
+re-run the last or the n-th history entry.
+
+In non-interactive mode only the results of expressions are printed, one per
+line, so the output can be consumed by other programs. Errors go to stderr
+prefixed with the line number, and the exit status is 1 if any line failed.
+
+*/
+
+import (
+	"bufio"
+	"errors"
+	"first/expr"
+	mathutil "first/mathUtil"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const helpText = `statements:
+  <expr>                 evaluate an expression, e.g. sums(1, 2, 3) * square(4) - 7
+  <name> = <expr>        assign a variable
+  <name>(a, b) = <expr>  define a function
+  <name> = self(<func>)  define <name>(x) = <func>(x, x)
+commands:
+  :help                  show this help
+  :vars                  list the variables
+  :funcs                 list the functions
+  :history               list the history
+  :quit                  exit (Ctrl-D works too)
+  !!  or  !<n>           re-run the last or the n-th history entry`
+
+func add(a, b int) (int, error) {
+	return mathutil.AddChecked(a, b)
+}
+
+func mult(a, b int) (int, error) {
+	return mathutil.MulChecked(a, b)
+}
+
+func sums(nums ...int) (int, error) {
+	total := 0
+	for _, num := range nums {
+		var err error
+		if total, err = mathutil.AddChecked(total, num); err != nil {
+			return 0, err
+		}
+	}
+	return total, nil
+}
+
+func pow(base, exp int) (int, error) {
+	if exp < 0 {
+		return 0, mathutil.ErrNegativeExponent
+	}
+	return mathutil.PowChecked(base, uint(exp))
+}
+
+// newEnv returns an environment holding the builtin functions
+func newEnv() *expr.Env {
+	env := expr.NewEnv()
+	builtins := map[string]any{
+		"add":    add,
+		"mult":   mult,
+		"sums":   sums,
+		"pow":    pow,
+		"square": mathutil.GetSquareChecked[int],
+		"double": mathutil.GetDoubleChecked[int],
+		"neg":    mathutil.NegChecked[int],
+	}
+	for name, fn := range builtins {
+		if err := env.RegisterFunc(name, fn); err != nil {
+			panic(err)
+		}
+	}
+	return env
+}
+
+type session struct {
+	env         *expr.Env
+	interactive bool
+	history     []string
+	historyFile io.Writer // nil if the history isn't persisted
+	out         io.Writer
+}
+
+var errQuit = errors.New("quit")
+
+// self defines name(x) = fn(x, x), the expression language version of selfMath
+// it is defined like any other function of the language, so that calls through
+// it count towards expr.MaxCallDepth and fn is looked up at call time
+func (s *session) self(name string, call *expr.CallExpr) error {
+	if len(call.Args) != 1 {
+		return &expr.Error{Pos: call.Pos(), Msg: "self expects a single function name"}
+	}
+	ident, ok := call.Args[0].(*expr.Ident)
+	if !ok {
+		return &expr.Error{Pos: call.Args[0].Pos(), Msg: "self expects a function name"}
+	}
+	fn, ok := s.env.Func(ident.Name)
+	if !ok {
+		return &expr.Error{Pos: ident.Pos(), Msg: fmt.Sprintf("undefined function %s", ident.Name)}
+	}
+	if fn.Arity != 2 && !(fn.Variadic && fn.Arity <= 2) {
+		return &expr.Error{Pos: ident.Pos(), Msg: fmt.Sprintf("self needs a function of two arguments, %s takes %d", fn.Name, fn.Arity)}
+	}
+
+	param := &expr.Ident{Column: ident.Column, Name: "x"}
+	s.env.DefineExpr(name, []string{param.Name}, &expr.CallExpr{
+		Column: ident.Column,
+		Name:   ident.Name,
+		Args:   []expr.Node{param, param},
+	})
+	return nil
+}
+
+// expandHistory replaces !! and !n with the matching history entry
+func (s *session) expandHistory(line string) (string, error) {
+	if !strings.HasPrefix(line, "!") {
+		return line, nil
+	}
+	if len(s.history) == 0 {
+		return "", errors.New("history is empty")
+	}
+	if line == "!!" {
+		return s.history[len(s.history)-1], nil
+	}
+	index, err := strconv.Atoi(line[1:])
+	if err != nil || index < 1 || index > len(s.history) {
+		return "", fmt.Errorf("no history entry %s", line[1:])
+	}
+	return s.history[index-1], nil
+}
+
+func (s *session) command(line string) error {
+	switch line {
+	case ":help", ":h":
+		fmt.Fprintln(s.out, helpText)
+	case ":vars":
+		for _, name := range s.env.VarNames() {
+			value, _ := s.env.Var(name)
+			fmt.Fprintf(s.out, "%s = %d\n", name, value)
+		}
+	case ":funcs":
+		fmt.Fprintln(s.out, strings.Join(s.env.FuncNames(), " "))
+	case ":history":
+		for index, entry := range s.history {
+			fmt.Fprintf(s.out, "%4d  %s\n", index+1, entry)
+		}
+	case ":quit", ":q":
+		return errQuit
+	default:
+		return fmt.Errorf("unknown command %s, try :help", line)
+	}
+	return nil
+}
+
+// execute runs a single line
+func (s *session) execute(line string) error {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return nil
+	}
+	if strings.HasPrefix(line, ":") {
+		return s.command(line)
+	}
+
+	expanded, err := s.expandHistory(line)
+	if err != nil {
+		return err
+	}
+	if expanded != line {
+		// show what is actually run, like shells do
+		fmt.Fprintln(s.out, expanded)
+		line = expanded
+	}
+	if s.interactive {
+		s.record(line)
+	}
+
+	stmt, err := expr.ParseStatement(line)
+	if err != nil {
+		return err
+	}
+
+	if assign, ok := stmt.(*expr.AssignStmt); ok {
+		if call, ok := assign.Value.(*expr.CallExpr); ok && call.Name == "self" {
+			return s.self(assign.Name, call)
+		}
+	}
+
+	value, err := expr.Exec(stmt, s.env)
+	if err != nil {
+		return err
+	}
+
+	switch stmt := stmt.(type) {
+	case *expr.ExprStmt:
+		s.env.SetVar("ans", value)
+		fmt.Fprintln(s.out, value)
+	case *expr.AssignStmt:
+		if s.interactive {
+			fmt.Fprintf(s.out, "%s = %d\n", stmt.Name, value)
+		}
+	}
+	return nil
+}
+
+// record appends line to the history, and to the history file if there is one
+func (s *session) record(line string) {
+	s.history = append(s.history, line)
+	if s.historyFile != nil {
+		fmt.Fprintln(s.historyFile, line)
+	}
+}
+
+// loadHistory reads the previous entries from path and opens it for appending
+func (s *session) loadHistory(path string) (*os.File, error) {
+	if data, err := os.ReadFile(path); err == nil {
+		for _, line := range strings.Split(string(data), "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				s.history = append(s.history, line)
+			}
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	s.historyFile = file
+	return file, nil
+}
+
+// run reads lines from in until EOF or :quit and returns the number of lines that failed
+func (s *session) run(in io.Reader, errOut io.Writer) (failures int) {
+	scanner := bufio.NewScanner(in)
+	lineNo := 0
+	for {
+		if s.interactive {
+			fmt.Fprint(s.out, "> ")
+		}
+		if !scanner.Scan() {
+			break
+		}
+		lineNo++
+
+		err := s.execute(scanner.Text())
+		if errors.Is(err, errQuit) {
+			return failures
+		}
+		if err != nil {
+			failures++
+			if s.interactive {
+				fmt.Fprintln(errOut, "error:", err)
+			} else {
+				fmt.Fprintf(errOut, "line %d: %v\n", lineNo, err)
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		fmt.Fprintln(errOut, "error reading input:", err)
+		failures++
+	}
+	if s.interactive {
+		fmt.Fprintln(s.out)
+	}
+	return failures
+}
+
+// stdinIsTerminal reports whether stdin is a terminal rather than a pipe or a file
+func stdinIsTerminal() bool {
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+func main() {
+	forceInteractive := flag.Bool("i", false, "run the REPL even if stdin is not a terminal")
+	historyPath := flag.String("history", "", "file to load the REPL history from and append it to")
+	flag.Parse()
+
+	s := &session{
+		env:         newEnv(),
+		interactive: *forceInteractive || stdinIsTerminal(),
+		out:         os.Stdout,
+	}
+
+	if s.interactive && *historyPath != "" {
+		file, err := s.loadHistory(*historyPath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "error opening history:", err)
+			os.Exit(1)
+		}
+		defer file.Close()
+	}
+
+	if s.interactive {
+		fmt.Println("calc, type :help for help")
+	}
+
+	failures := s.run(os.Stdin, os.Stderr)
+	if !s.interactive && failures > 0 {
+		// os.Exit skips the deferred calls, but in this mode no file is open
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"errors"
+	"first/expr"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name, input, out, errOut string
+		failures                 int
+	}{
+		{"expressions", "1 + 2\nsums(1, 2, 3) * square(4) - 7\n", "3\n89\n", "", 0},
+		{"ans", "6 * 7\nans + 1\n", "42\n43\n", "", 0},
+		{"variables", "x = 3 * 4\nx + 1\n", "13\n", "", 0},
+		{"functions", "area(w, h) = w * h\narea(2, 3)\n", "6\n", "", 0},
+		{"self", "sq = self(mult)\nsq(9)\n", "81\n", "", 0},
+		{"comments", "# nothing\n\n1\n", "1\n", "", 0},
+		{"errors", "1 +\n2\nundefined(1)\n", "2\n", "line 1: ", 2},
+		{"overflow", "pow(2, 63)\n", "", "line 1: ", 1},
+		{"self arity", "n = self(neg)\n", "", "line 1: ", 1},
+		{"self redefinition", "m(a, b) = a * b\nsq = self(m)\nsq(3)\nm(a, b) = a + b\nsq(3)\n", "9\n6\n", "", 0},
+		// calls through self count towards the maximum depth instead of
+		// overflowing the stack
+		{"self recursion", "f(x) = g(x)\nh(a, b) = f(a)\ng = self(h)\nf(1)\n", "",
+			"line 4: column 1: in f: maximum call depth exceeded", 1},
+		{"quit", "1\n:quit\n2\n", "1\n", "", 0},
+	}
+	for _, test := range tests {
+		var out, errOut strings.Builder
+		s := &session{env: newEnv(), out: &out}
+		failures := s.run(strings.NewReader(test.input), &errOut)
+		if out.String() != test.out || !strings.HasPrefix(errOut.String(), test.errOut) || failures != test.failures {
+			t.Errorf("%s: output %q, errors %q, %d failures, want %q, %q..., %d failures",
+				test.name, out.String(), errOut.String(), failures, test.out, test.errOut, test.failures)
+		}
+	}
+}
+
+func TestSelfMaxDepth(t *testing.T) {
+	s := &session{env: newEnv(), out: io.Discard}
+	for _, line := range []string{"f(x) = g(x)", "h(a, b) = f(a)", "g = self(h)"} {
+		if err := s.execute(line); err != nil {
+			t.Fatalf("%s: %v", line, err)
+		}
+	}
+	if err := s.execute("f(1)"); !errors.Is(err, expr.ErrMaxDepth) {
+		t.Errorf("f(1) error %v, want ErrMaxDepth", err)
+	}
+}
+
+func TestHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history")
+	os.WriteFile(path, []byte("1 + 1\n"), 0o600)
+
+	var out, errOut strings.Builder
+	s := &session{env: newEnv(), interactive: true, out: &out}
+	file, err := s.loadHistory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.run(strings.NewReader("2 * 3\n!!\n!1\n!9\n"), &errOut)
+	file.Close()
+
+	if want := "> 6\n> 2 * 3\n6\n> 1 + 1\n2\n> > \n"; out.String() != want {
+		t.Errorf("output %q, want %q", out.String(), want)
+	}
+	if !strings.Contains(errOut.String(), "no history entry 9") {
+		t.Errorf("errors %q, want a missing history entry", errOut.String())
+	}
+	data, _ := os.ReadFile(path)
+	if want := "1 + 1\n2 * 3\n2 * 3\n1 + 1\n"; string(data) != want {
+		t.Errorf("history file %q, want %q", data, want)
+	}
+}
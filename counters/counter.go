@@ -0,0 +1,161 @@
+package counters
+
+import (
+	"errors"
+	mathutil "first/mathUtil"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+/*
+
+Counter is the goroutine safe version of the closure counter() returns in main.
+The closure increments a plain int, so two goroutines calling it at the same
+time race on it and lose increments; a Counter updates its value atomically.
+
+The zero value is an unbounded counter starting at 0, ready to use:
+
+	var requests counters.Counter
+	requests.Inc()
+
+A bounded counter never goes above its maximum, and what happens when an
+increment would cross it is up to its OverflowPolicy:
+
+	Saturate  the counter stops at the maximum
+	Wrap      the counter starts again from 0, like an odometer
+	Reject    the increment fails with ErrLimit and the counter doesn't move
+
+An unbounded counter rejects increments past math.MaxInt64 with mathutil.ErrOverflow.
+Counters only count up: Add fails with ErrNegative for a negative delta.
+
+*/
+
+var (
+	ErrLimit    = errors.New("counters: counter limit reached")
+	ErrNegative = errors.New("counters: negative delta")
+	ErrMax      = errors.New("counters: maximum must be positive")
+)
+
+type OverflowPolicy int
+
+const (
+	Saturate OverflowPolicy = iota
+	Wrap
+	Reject
+)
+
+func (policy OverflowPolicy) String() string {
+	switch policy {
+	case Saturate:
+		return "saturate"
+	case Wrap:
+		return "wrap"
+	case Reject:
+		return "reject"
+	}
+	return fmt.Sprintf("OverflowPolicy(%d)", int(policy))
+}
+
+type Counter struct {
+	value atomic.Int64
+	// max is 0 for an unbounded counter
+	max    int64
+	policy OverflowPolicy
+}
+
+// Snapshot is the value of a counter at a point in time
+type Snapshot struct {
+	Value int64
+	At    time.Time
+}
+
+// NewBounded returns a counter that never goes above max
+func NewBounded(max int64, policy OverflowPolicy) (*Counter, error) {
+	if max <= 0 {
+		return nil, ErrMax
+	}
+	if policy < Saturate || policy > Reject {
+		return nil, fmt.Errorf("counters: unknown overflow policy %v", policy)
+	}
+	return &Counter{max: max, policy: policy}, nil
+}
+
+// Max returns the maximum of the counter, 0 for an unbounded counter
+func (counter *Counter) Max() int64 {
+	return counter.max
+}
+
+// Policy returns what the counter does when an increment crosses its maximum
+func (counter *Counter) Policy() OverflowPolicy {
+	return counter.policy
+}
+
+// Inc adds 1 to the counter and returns the new value
+func (counter *Counter) Inc() (int64, error) {
+	return counter.Add(1)
+}
+
+// Add adds delta to the counter and returns the new value
+// when the increment fails, the returned value is the current one
+func (counter *Counter) Add(delta int64) (int64, error) {
+	if delta < 0 {
+		return counter.Get(), ErrNegative
+	}
+	for {
+		current := counter.value.Load()
+		next, err := counter.next(current, delta)
+		if err != nil {
+			return current, err
+		}
+		if counter.value.CompareAndSwap(current, next) {
+			return next, nil
+		}
+	}
+}
+
+// next returns the value after adding delta to current, following the overflow policy
+func (counter *Counter) next(current, delta int64) (int64, error) {
+	if counter.max == 0 {
+		return mathutil.AddChecked(current, delta)
+	}
+	// current <= max and delta >= 0, so the headroom can't overflow
+	if delta <= counter.max-current {
+		return current + delta, nil
+	}
+
+	switch counter.policy {
+	case Saturate:
+		return counter.max, nil
+	case Wrap:
+		// the counter has max+1 states, 0 to max; max+1 fits in an uint64
+		// even for max == MaxInt64, and so does the sum
+		states := uint64(counter.max) + 1
+		return int64((uint64(current) + uint64(delta)%states) % states), nil
+	}
+	return current, ErrLimit
+}
+
+// Get returns the current value
+func (counter *Counter) Get() int64 {
+	return counter.value.Load()
+}
+
+// Snapshot returns the current value with the time it was read at
+func (counter *Counter) Snapshot() Snapshot {
+	return Snapshot{Value: counter.value.Load(), At: time.Now()}
+}
+
+// Reset sets the counter back to 0 and returns the value it had, atomically:
+// no increment is lost between reading the value and resetting it
+func (counter *Counter) Reset() Snapshot {
+	return Snapshot{Value: counter.value.Swap(0), At: time.Now()}
+}
+
+// String returns the value, with the maximum for a bounded counter: "42" or "42/100"
+func (counter *Counter) String() string {
+	if counter.max == 0 {
+		return fmt.Sprint(counter.Get())
+	}
+	return fmt.Sprintf("%d/%d", counter.Get(), counter.max)
+}
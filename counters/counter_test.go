@@ -0,0 +1,103 @@
+package counters
+
+import (
+	"errors"
+	mathutil "first/mathUtil"
+	"math"
+	"sync"
+	"testing"
+)
+
+func TestBounded(t *testing.T) {
+	tests := []struct {
+		max    int64
+		policy OverflowPolicy
+		start  int64
+		delta  int64
+		want   int64
+		err    error
+	}{
+		{10, Saturate, 8, 2, 10, nil},
+		{10, Saturate, 8, 5, 10, nil},
+		{10, Wrap, 8, 2, 10, nil},
+		{10, Wrap, 8, 3, 0, nil},
+		{10, Wrap, 8, 25, 0, nil}, // 33 mod 11
+		{10, Reject, 8, 3, 8, ErrLimit},
+		{10, Reject, 8, 2, 10, nil},
+		{math.MaxInt64, Wrap, math.MaxInt64, math.MaxInt64, math.MaxInt64 - 1, nil},
+		{math.MaxInt64, Saturate, 1, math.MaxInt64, math.MaxInt64, nil},
+		{10, Saturate, 3, -1, 3, ErrNegative},
+	}
+	for _, test := range tests {
+		counter, err := NewBounded(test.max, test.policy)
+		if err != nil {
+			t.Fatal(err)
+		}
+		counter.Add(test.start)
+		got, err := counter.Add(test.delta)
+		if got != test.want || !errors.Is(err, test.err) || counter.Get() != test.want {
+			t.Errorf("%v counter to %d at %d: Add(%d) = %d, %v, want %d, %v",
+				test.policy, test.max, test.start, test.delta, got, err, test.want, test.err)
+		}
+	}
+
+	if _, err := NewBounded(0, Saturate); !errors.Is(err, ErrMax) {
+		t.Errorf("NewBounded(0) error %v, want ErrMax", err)
+	}
+	if _, err := NewBounded(10, OverflowPolicy(7)); err == nil {
+		t.Error("NewBounded with an unknown policy succeeded")
+	}
+}
+
+func TestUnbounded(t *testing.T) {
+	var counter Counter
+	if value, err := counter.Inc(); value != 1 || err != nil {
+		t.Errorf("Inc = %d, %v", value, err)
+	}
+	counter.Add(math.MaxInt64 - 1)
+	if value, err := counter.Inc(); value != math.MaxInt64 || !errors.Is(err, mathutil.ErrOverflow) {
+		t.Errorf("Inc at MaxInt64 = %d, %v, want ErrOverflow", value, err)
+	}
+	if snapshot := counter.Reset(); snapshot.Value != math.MaxInt64 || counter.Get() != 0 {
+		t.Errorf("Reset = %+v, then %d", snapshot, counter.Get())
+	}
+	if counter.String() != "0" {
+		t.Errorf("String = %q", counter.String())
+	}
+	bounded, _ := NewBounded(100, Reject)
+	bounded.Add(42)
+	if bounded.String() != "42/100" {
+		t.Errorf("String = %q, want 42/100", bounded.String())
+	}
+}
+
+// the point of Counter: no increment is lost between goroutines
+func TestConcurrentInc(t *testing.T) {
+	tests := []struct {
+		policy OverflowPolicy
+		max    int64
+		want   int64
+	}{
+		{Saturate, 1 << 40, 8000},
+		{Saturate, 5000, 5000},
+		{Wrap, 999, 8000 % 1000},
+		{Reject, 5000, 5000},
+	}
+	for _, test := range tests {
+		counter, _ := NewBounded(test.max, test.policy)
+		var wait sync.WaitGroup
+		for range 8 {
+			wait.Add(1)
+			go func() {
+				defer wait.Done()
+				for range 1000 {
+					counter.Inc()
+				}
+			}()
+		}
+		wait.Wait()
+		if counter.Get() != test.want {
+			t.Errorf("%v to %d: %d after 8000 increments, want %d", test.policy, test.max, counter.Get(), test.want)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package counters
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+/*
+
+RateCounter counts events and reports how many happened per second over
+a sliding window, the last minute for instance.
+
+The window is split into buckets: every increment goes to the bucket of the
+current time slot, and the rate sums the buckets still inside the window.
+Older buckets are recycled as time moves on, so the memory used doesn't
+depend on the number of events. More buckets make the window slide more
+smoothly, at the cost of a longer sum on every Rate.
+
+	window 1m, 60 buckets    each bucket holds one second of increments
+
+The oldest bucket is only partly inside the window. Rate counts it whole
+and divides by the time its buckets cover, so the reported rate lags by at most
+one bucket. Before a full window has gone by, Rate divides by the time
+elapsed since the counter was created instead of by the whole window.
+
+*/
+
+var ErrWindow = errors.New("counters: invalid rate window")
+
+type RateCounter struct {
+	total Counter
+
+	mu      sync.Mutex
+	width   time.Duration // time slot of a bucket
+	buckets []rateBucket
+	created time.Time
+	now     func() time.Time
+}
+
+type rateBucket struct {
+	slot  int64 // index of the time slot the count belongs to
+	count int64
+}
+
+// NewRate returns a counter measuring its rate over window, split into buckets
+func NewRate(window time.Duration, buckets int) (*RateCounter, error) {
+	if buckets <= 0 || window < time.Duration(buckets) {
+		return nil, ErrWindow
+	}
+	rate := &RateCounter{
+		width:   window / time.Duration(buckets),
+		buckets: make([]rateBucket, buckets),
+		now:     time.Now,
+	}
+	rate.created = rate.now()
+	// slot -1 marks the buckets as unused, slots start from 0
+	for index := range rate.buckets {
+		rate.buckets[index].slot = -1
+	}
+	return rate, nil
+}
+
+// Inc counts one event
+func (rate *RateCounter) Inc() (int64, error) {
+	return rate.Add(1)
+}
+
+// Add counts n events and returns the total number of events counted so far
+func (rate *RateCounter) Add(n int64) (int64, error) {
+	// the total is updated under the lock too, so Reset never sees it
+	// counting events the buckets don't have yet
+	rate.mu.Lock()
+	defer rate.mu.Unlock()
+	total, err := rate.total.Add(n)
+	if err != nil {
+		return total, err
+	}
+
+	slot := rate.slot(rate.now())
+	bucket := &rate.buckets[slot%int64(len(rate.buckets))]
+	if bucket.slot != slot {
+		// the bucket held an older slot that has left the window
+		bucket.slot = slot
+		bucket.count = 0
+	}
+	bucket.count += n
+	return total, nil
+}
+
+// slot returns the index of the time slot of at, counted from the creation of the counter
+func (rate *RateCounter) slot(at time.Time) int64 {
+	return int64(at.Sub(rate.created) / rate.width)
+}
+
+// Rate returns the number of events per second over the window
+func (rate *RateCounter) Rate() float64 {
+	rate.mu.Lock()
+	defer rate.mu.Unlock()
+
+	now := rate.now()
+	current := rate.slot(now)
+	oldest := current - int64(len(rate.buckets)) + 1
+	var count int64
+	for _, bucket := range rate.buckets {
+		if bucket.slot >= oldest && bucket.slot <= current {
+			count += bucket.count
+		}
+	}
+
+	// the buckets cover the full slots before the current one and the part
+	// of the current slot that has elapsed, or less for a recent counter
+	covered := time.Duration(len(rate.buckets)-1)*rate.width + now.Sub(rate.created)%rate.width
+	covered = min(covered, now.Sub(rate.created))
+	if covered <= 0 {
+		return 0
+	}
+	return float64(count) / covered.Seconds()
+}
+
+// Window returns the duration Rate is computed over
+func (rate *RateCounter) Window() time.Duration {
+	return rate.width * time.Duration(len(rate.buckets))
+}
+
+// Total returns the number of events counted since the creation or the last Reset
+func (rate *RateCounter) Total() int64 {
+	return rate.total.Get()
+}
+
+// Reset forgets every event, and returns the total counted before
+func (rate *RateCounter) Reset() Snapshot {
+	rate.mu.Lock()
+	defer rate.mu.Unlock()
+	for index := range rate.buckets {
+		rate.buckets[index] = rateBucket{slot: -1}
+	}
+	return rate.total.Reset()
+}
@@ -0,0 +1,72 @@
+package counters
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func newFakeRate(t *testing.T, window time.Duration, buckets int) (*RateCounter, *time.Time) {
+	t.Helper()
+	rate, err := NewRate(window, buckets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+	rate.created = now
+	rate.now = func() time.Time { return now }
+	return rate, &now
+}
+
+func TestRate(t *testing.T) {
+	rate, now := newFakeRate(t, 10*time.Second, 10)
+	steps := []struct {
+		advance time.Duration
+		add     int64
+		want    float64
+	}{
+		{0, 5, 0}, // no time elapsed yet
+		{500 * time.Millisecond, 0, 10},
+		{1500 * time.Millisecond, 15, 10},      // 20 events in 2s
+		{7500 * time.Millisecond, 0, 20 / 9.5}, // every event still in the window
+		{500 * time.Millisecond, 0, 15.0 / 9},  // the first bucket left, the current one just started
+		{500 * time.Millisecond, 0, 15 / 9.5},
+		{20 * time.Second, 0, 0},
+	}
+	for index, step := range steps {
+		*now = now.Add(step.advance)
+		if step.add > 0 {
+			rate.Add(step.add)
+		}
+		if got := rate.Rate(); got != step.want {
+			t.Errorf("step %d: Rate = %v, want %v", index, got, step.want)
+		}
+	}
+	if rate.Total() != 20 {
+		t.Errorf("Total = %d, want 20", rate.Total())
+	}
+	if snapshot := rate.Reset(); snapshot.Value != 20 || rate.Total() != 0 {
+		t.Errorf("Reset = %+v, then Total %d", snapshot, rate.Total())
+	}
+}
+
+func TestNewRate(t *testing.T) {
+	tests := []struct {
+		window  time.Duration
+		buckets int
+		err     error
+	}{
+		{time.Minute, 60, nil},
+		{time.Minute, 0, ErrWindow},
+		{time.Nanosecond, 2, ErrWindow},
+	}
+	for _, test := range tests {
+		rate, err := NewRate(test.window, test.buckets)
+		if !errors.Is(err, test.err) {
+			t.Errorf("NewRate(%v, %d) error %v, want %v", test.window, test.buckets, err, test.err)
+		}
+		if err == nil && rate.Window() != test.window {
+			t.Errorf("Window = %v, want %v", rate.Window(), test.window)
+		}
+	}
+}
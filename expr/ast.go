@@ -0,0 +1,110 @@
+package expr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+/*
+
+The parser turns a source string into a tree of Nodes (the AST):
+
+	sums(1, 2, 3) * square(4) - 7
+
+becomes
+
+	BinaryExpr{-}
+	├── BinaryExpr{*}
+	│   ├── CallExpr{sums}
+	│   │   ├── NumberLit{1}
+	│   │   ├── NumberLit{2}
+	│   │   └── NumberLit{3}
+	│   └── CallExpr{square}
+	│       └── NumberLit{4}
+	└── NumberLit{7}
+
+Every node remembers the column it starts at, so evaluation errors
+can point at the exact part of the input that failed.
+
+*/
+
+// Node is implemented by every AST node
+type Node interface {
+	Pos() int       // 1-based column where the node starts in the source
+	String() string // the node printed back as a fully parenthesized expression
+}
+
+// NumberLit is an integer literal such as 42
+type NumberLit struct {
+	Column int
+	Value  int
+}
+
+// Ident is a reference to a variable
+type Ident struct {
+	Column int
+	Name   string
+}
+
+// UnaryExpr is a prefix operator applied to an operand, such as -x
+type UnaryExpr struct {
+	Column  int
+	Op      byte // '+' or '-'
+	Operand Node
+}
+
+// BinaryExpr is an infix operator applied to two operands, such as a * b
+type BinaryExpr struct {
+	Column int // column of the operator, which is where errors such as division by zero are reported
+	Op     byte
+	Left   Node
+	Right  Node
+}
+
+// CallExpr is a function call such as add(1, 2)
+type CallExpr struct {
+	Column int
+	Name   string
+	Args   []Node
+}
+
+func (node *NumberLit) Pos() int  { return node.Column }
+func (node *Ident) Pos() int      { return node.Column }
+func (node *UnaryExpr) Pos() int  { return node.Column }
+func (node *BinaryExpr) Pos() int { return node.Left.Pos() }
+func (node *CallExpr) Pos() int   { return node.Column }
+
+func (node *NumberLit) String() string { return strconv.Itoa(node.Value) }
+func (node *Ident) String() string     { return node.Name }
+
+func (node *UnaryExpr) String() string {
+	return fmt.Sprintf("(%c%s)", node.Op, node.Operand)
+}
+
+func (node *BinaryExpr) String() string {
+	return fmt.Sprintf("(%s %c %s)", node.Left, node.Op, node.Right)
+}
+
+func (node *CallExpr) String() string {
+	args := make([]string, len(node.Args))
+	for index, arg := range node.Args {
+		args[index] = arg.String()
+	}
+	return fmt.Sprintf("%s(%s)", node.Name, strings.Join(args, ", "))
+}
+
+// Error is returned for every lexing, parsing and evaluation failure
+type Error struct {
+	Pos int // 1-based column of the offending part of the input
+	Msg string
+	Err error // underlying error, if any (for example mathutil.ErrOverflow)
+}
+
+func (err *Error) Error() string {
+	return fmt.Sprintf("column %d: %s", err.Pos, err.Msg)
+}
+
+func (err *Error) Unwrap() error {
+	return err.Err
+}
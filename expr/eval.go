@@ -0,0 +1,311 @@
+package expr
+
+import (
+	"errors"
+	mathutil "first/mathUtil"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+var (
+	// ErrDivideByZero is mathutil's, so one errors.Is matches it from every package
+	ErrDivideByZero = mathutil.ErrDivideByZero
+	errType         = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// Func is a function that can be called from an expression
+type Func struct {
+	Name     string
+	Arity    int  // number of parameters, for a variadic function the number of fixed ones
+	Variadic bool // accepts any number of extra arguments after the fixed ones
+	Call     func(args []int) (int, error)
+
+	// call is set by DefineExpr, and used instead of Call from expressions:
+	// it gets the call depth of the caller to enforce MaxCallDepth
+	call func(depth int, args []int) (int, error)
+}
+
+// Env holds the functions and variables visible to an expression
+// environments can be nested: lookups that fail in a child environment
+// continue in its parent, while definitions always go to the child
+// the zero value is not usable, create one with NewEnv or Child
+type Env struct {
+	parent *Env
+	funcs  map[string]Func
+	vars   map[string]int
+	depth  int // of calls to functions defined with DefineExpr, 0 at the top level
+}
+
+// NewEnv returns an empty environment
+func NewEnv() *Env {
+	return &Env{funcs: make(map[string]Func), vars: make(map[string]int)}
+}
+
+// Child returns a new empty environment nested inside env
+func (env *Env) Child() *Env {
+	child := NewEnv()
+	child.parent = env
+	child.depth = env.depth
+	return child
+}
+
+// Define makes fn callable from expressions under fn.Name, replacing any
+// function with the same name
+func (env *Env) Define(fn Func) {
+	env.funcs[fn.Name] = fn
+}
+
+/*
+RegisterFunc makes a plain Go function callable from expressions under name.
+fn must be a function whose parameters are all int (the last one may be ...int)
+and that returns either int or (int, error), so all of these work:
+
+	func add(a, b int) int
+	func sums(nums ...int) int
+	mathutil.GetSquare
+	func(a, b int) (int, error)
+
+The function is called through reflection, which is slower than a direct call
+but lets us reuse existing functions without writing adapters by hand.
+*/
+func (env *Env) RegisterFunc(name string, fn any) error {
+	value := reflect.ValueOf(fn)
+	// a nil fn has no type at all, check before asking for it
+	if !value.IsValid() || value.Kind() != reflect.Func || value.IsNil() {
+		return fmt.Errorf("expr: %s: %T is not a function", name, fn)
+	}
+	fnType := value.Type()
+
+	intType := reflect.TypeOf(0)
+	for index := 0; index < fnType.NumIn(); index++ {
+		in := fnType.In(index)
+		if fnType.IsVariadic() && index == fnType.NumIn()-1 {
+			in = in.Elem()
+		}
+		if in != intType {
+			return fmt.Errorf("expr: %s: parameter %d is %s, only int is supported", name, index+1, in)
+		}
+	}
+
+	returnsErr := false
+	switch {
+	case fnType.NumOut() == 1 && fnType.Out(0) == intType:
+	case fnType.NumOut() == 2 && fnType.Out(0) == intType && fnType.Out(1) == errType:
+		returnsErr = true
+	default:
+		return fmt.Errorf("expr: %s: must return int or (int, error)", name)
+	}
+
+	arity := fnType.NumIn()
+	if fnType.IsVariadic() {
+		arity--
+	}
+
+	env.Define(Func{
+		Name:     name,
+		Arity:    arity,
+		Variadic: fnType.IsVariadic(),
+		Call: func(args []int) (int, error) {
+			in := make([]reflect.Value, len(args))
+			for index, arg := range args {
+				in[index] = reflect.ValueOf(arg)
+			}
+			out := value.Call(in)
+			if returnsErr && !out[1].IsNil() {
+				return 0, out[1].Interface().(error)
+			}
+			return int(out[0].Int()), nil
+		},
+	})
+	return nil
+}
+
+// Func returns the function registered under name in env or one of its parents
+func (env *Env) Func(name string) (fn Func, ok bool) {
+	for scope := env; scope != nil; scope = scope.parent {
+		if fn, ok = scope.funcs[name]; ok {
+			return fn, true
+		}
+	}
+	return Func{}, false
+}
+
+// FuncNames returns the names of all the functions visible in env, sorted
+func (env *Env) FuncNames() []string {
+	seen := make(map[string]bool)
+	for scope := env; scope != nil; scope = scope.parent {
+		for name := range scope.funcs {
+			seen[name] = true
+		}
+	}
+	return sortedKeys(seen)
+}
+
+// SetVar sets the variable name to value in env
+func (env *Env) SetVar(name string, value int) {
+	env.vars[name] = value
+}
+
+// Var returns the value of the variable name in env or one of its parents
+func (env *Env) Var(name string) (value int, ok bool) {
+	for scope := env; scope != nil; scope = scope.parent {
+		if value, ok = scope.vars[name]; ok {
+			return value, true
+		}
+	}
+	return 0, false
+}
+
+// VarNames returns the names of all the variables visible in env, sorted
+func (env *Env) VarNames() []string {
+	seen := make(map[string]bool)
+	for scope := env; scope != nil; scope = scope.parent {
+		for name := range scope.vars {
+			seen[name] = true
+		}
+	}
+	return sortedKeys(seen)
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Evaluate parses and evaluates src in env
+func Evaluate(src string, env *Env) (int, error) {
+	node, err := Parse(src)
+	if err != nil {
+		return 0, err
+	}
+	return Eval(node, env)
+}
+
+// Eval evaluates the AST rooted at node in env
+// integer overflow is reported as an error wrapping mathutil.ErrOverflow
+func Eval(node Node, env *Env) (int, error) {
+	switch node := node.(type) {
+	case *NumberLit:
+		return node.Value, nil
+
+	case *Ident:
+		value, ok := env.Var(node.Name)
+		if !ok {
+			return 0, &Error{Pos: node.Column, Msg: fmt.Sprintf("undefined variable %s", node.Name)}
+		}
+		return value, nil
+
+	case *UnaryExpr:
+		operand, err := Eval(node.Operand, env)
+		if err != nil {
+			return 0, err
+		}
+		if node.Op == '+' {
+			return operand, nil
+		}
+		negation, err := mathutil.NegChecked(operand)
+		if err != nil {
+			return 0, &Error{Pos: node.Column, Msg: fmt.Sprintf("-%d overflows", operand), Err: err}
+		}
+		return negation, nil
+
+	case *BinaryExpr:
+		return evalBinary(node, env)
+
+	case *CallExpr:
+		return evalCall(node, env)
+	}
+	return 0, fmt.Errorf("expr: unknown node type %T", node)
+}
+
+func evalBinary(node *BinaryExpr, env *Env) (int, error) {
+	left, err := Eval(node.Left, env)
+	if err != nil {
+		return 0, err
+	}
+	right, err := Eval(node.Right, env)
+	if err != nil {
+		return 0, err
+	}
+
+	var result int
+	switch node.Op {
+	case '+':
+		result, err = mathutil.AddChecked(left, right)
+	case '-':
+		result, err = mathutil.SubChecked(left, right)
+	case '*':
+		result, err = mathutil.MulChecked(left, right)
+	case '/', '%':
+		if right == 0 {
+			return 0, &Error{Pos: node.Column, Msg: "division by zero", Err: ErrDivideByZero}
+		}
+		// MinInt / -1 is the only quotient that doesn't fit
+		if right == -1 && left != 0 && -left == left {
+			return 0, &Error{Pos: node.Column, Msg: fmt.Sprintf("%d %c %d overflows", left, node.Op, right), Err: mathutil.ErrOverflow}
+		}
+		if node.Op == '/' {
+			result = left / right
+		} else {
+			result = left % right
+		}
+	default:
+		return 0, &Error{Pos: node.Column, Msg: fmt.Sprintf("unknown operator %c", node.Op)}
+	}
+
+	if err != nil {
+		return 0, &Error{Pos: node.Column, Msg: fmt.Sprintf("%d %c %d overflows", left, node.Op, right), Err: err}
+	}
+	return result, nil
+}
+
+func evalCall(node *CallExpr, env *Env) (int, error) {
+	fn, ok := env.Func(node.Name)
+	if !ok {
+		return 0, &Error{Pos: node.Column, Msg: fmt.Sprintf("undefined function %s", node.Name)}
+	}
+
+	if len(node.Args) < fn.Arity || (!fn.Variadic && len(node.Args) > fn.Arity) {
+		want := fmt.Sprint(fn.Arity)
+		if fn.Variadic {
+			want = "at least " + want
+		}
+		return 0, &Error{Pos: node.Column, Msg: fmt.Sprintf("%s expects %s arguments, got %d", node.Name, want, len(node.Args))}
+	}
+
+	args := make([]int, len(node.Args))
+	for index, arg := range node.Args {
+		value, err := Eval(arg, env)
+		if err != nil {
+			return 0, err
+		}
+		args[index] = value
+	}
+
+	var result int
+	var err error
+	if fn.call != nil {
+		result, err = fn.call(env.depth, args)
+	} else {
+		result, err = fn.Call(args)
+	}
+	if err != nil {
+		var exprErr *Error
+		if errors.As(err, &exprErr) {
+			// the position of an error in the body of a defined function is in
+			// its definition, point at the call in the input being evaluated
+			if fn.call != nil && env.depth == 0 {
+				return 0, &Error{Pos: node.Column, Msg: fmt.Sprintf("in %s: %s", node.Name, exprErr.Msg), Err: err}
+			}
+			// errors coming from nested evaluations already carry their position
+			return 0, err
+		}
+		return 0, &Error{Pos: node.Column, Msg: fmt.Sprintf("%s: %v", node.Name, err), Err: err}
+	}
+	return result, nil
+}
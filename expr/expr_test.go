@@ -0,0 +1,218 @@
+package expr
+
+import (
+	"errors"
+	mathutil "first/mathUtil"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func newTestEnv(t *testing.T) *Env {
+	t.Helper()
+	env := NewEnv()
+	env.SetVar("x", 7)
+	for name, fn := range map[string]any{
+		"square": func(n int) int { return n * n },
+		"sums": func(nums ...int) int {
+			total := 0
+			for _, num := range nums {
+				total += num
+			}
+			return total
+		},
+		"checked": func(a, b int) (int, error) { return mathutil.AddChecked(a, b) },
+	} {
+		if err := env.RegisterFunc(name, fn); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return env
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		src, want string
+	}{
+		{"1 + 2 * 3", "(1 + (2 * 3))"},
+		{"10 - 2 - 3", "((10 - 2) - 3)"},
+		{"(1 + 2) * 3", "((1 + 2) * 3)"},
+		{"-x % 4", "((-x) % 4)"},
+		{"--1", "(-(-1))"},
+		{"sums(1, 2, 3) * square(4) - 7", "((sums(1, 2, 3) * square(4)) - 7)"},
+		{"f()", "f()"},
+	}
+	for _, test := range tests {
+		node, err := Parse(test.src)
+		if err != nil || node.String() != test.want {
+			t.Errorf("Parse(%q) = %v, %v, want %s", test.src, node, err, test.want)
+		}
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	tests := []struct {
+		src  string
+		want int
+	}{
+		{"1 + 2 * 3", 7},
+		{"10 - 2 - 3", 5},
+		{"-7 / 2", -3},
+		{"-7 % 2", -1},
+		{"x * x", 49},
+		{"sums(1, 2, 3) * square(4) - 7", 89},
+		{"sums()", 0},
+		{"checked(x, -x)", 0},
+		{"-9223372036854775807 - 1", -9223372036854775808},
+	}
+	env := newTestEnv(t)
+	for _, test := range tests {
+		if got, err := Evaluate(test.src, env); err != nil || got != test.want {
+			t.Errorf("Evaluate(%q) = %d, %v, want %d", test.src, got, err, test.want)
+		}
+	}
+}
+
+func TestErrors(t *testing.T) {
+	tests := []struct {
+		src string
+		pos int
+		err error
+	}{
+		{"1 +", 4, nil},
+		{"(1 + 2", 7, nil},
+		{"1 $ 2", 3, nil},
+		{"1 = 2", 3, nil},
+		{"y + 1", 1, nil},
+		{"nope(1)", 1, nil},
+		{"square(1, 2)", 1, nil},
+		{"1 + 2 / (x - 7)", 7, ErrDivideByZero},
+		{"x % 0", 3, mathutil.ErrDivideByZero},
+		{"9223372036854775807 + 1", 21, mathutil.ErrOverflow},
+		{"-(-9223372036854775807 - 1)", 1, mathutil.ErrOverflow},
+		{"(-9223372036854775807 - 1) / -1", 28, mathutil.ErrOverflow},
+		{"1 + checked(9223372036854775807, 1)", 5, mathutil.ErrOverflow},
+	}
+	env := newTestEnv(t)
+	for _, test := range tests {
+		_, err := Evaluate(test.src, env)
+		var exprErr *Error
+		if !errors.As(err, &exprErr) || exprErr.Pos != test.pos || (test.err != nil && !errors.Is(err, test.err)) {
+			t.Errorf("Evaluate(%q) error %v, want one at column %d wrapping %v", test.src, err, test.pos, test.err)
+		}
+	}
+}
+
+func TestStatements(t *testing.T) {
+	env := newTestEnv(t)
+	steps := []struct {
+		src  string
+		want int
+	}{
+		{"w = 3 * 4", 12},
+		{"area(a, b) = a * b", 0},
+		{"area(w, 2) + 1", 25},
+		{"double(n) = area(n, 2)", 0},
+		{"double(double(x))", 28},
+		// the body sees the variables at call time
+		{"scaled(n) = n * w", 0},
+		{"w = 10", 10},
+		{"scaled(3)", 30},
+	}
+	for _, step := range steps {
+		stmt, err := ParseStatement(step.src)
+		if err != nil {
+			t.Fatalf("ParseStatement(%q): %v", step.src, err)
+		}
+		if got, err := Exec(stmt, env); err != nil || got != step.want {
+			t.Errorf("Exec(%q) = %d, %v, want %d", step.src, got, err, step.want)
+		}
+	}
+	if names := env.VarNames(); !slices.Equal(names, []string{"w", "x"}) {
+		t.Errorf("VarNames = %v", names)
+	}
+
+	for _, src := range []string{"1 = 2", "f(1) = 2", "f(a, a) = a", "x = ", "a = b = 1"} {
+		if _, err := ParseStatement(src); err == nil {
+			t.Errorf("ParseStatement(%q) succeeded", src)
+		}
+	}
+}
+
+func TestDefinedFunctionErrors(t *testing.T) {
+	env := NewEnv()
+	for _, src := range []string{"inv(n) = 100 / n", "loop(n) = loop(n + 1)"} {
+		stmt, _ := ParseStatement(src)
+		Exec(stmt, env)
+	}
+
+	tests := []struct {
+		src string
+		pos int
+		err error
+	}{
+		// reported at the call in the input, not at the column of the body
+		{"1 + inv(0)", 5, ErrDivideByZero},
+		{"inv(5) + loop(0)", 10, ErrMaxDepth},
+	}
+	for _, test := range tests {
+		_, err := Evaluate(test.src, env)
+		var exprErr *Error
+		if !errors.As(err, &exprErr) || exprErr.Pos != test.pos || !errors.Is(err, test.err) {
+			t.Errorf("Evaluate(%q) error %v, want one at column %d wrapping %v", test.src, err, test.pos, test.err)
+		}
+	}
+
+	// the depth travels with the calls, concurrent evaluations don't add up
+	var wait sync.WaitGroup
+	for range 8 {
+		wait.Add(1)
+		go func() {
+			defer wait.Done()
+			node, _ := Parse("inv(4)")
+			for range 100 {
+				if got, err := Eval(node, env); err != nil || got != 25 {
+					t.Errorf("inv(4) = %d, %v", got, err)
+					return
+				}
+			}
+		}()
+	}
+	wait.Wait()
+}
+
+func TestRegisterFunc(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   any
+		ok   bool
+	}{
+		{"add", func(a, b int) int { return a + b }, true},
+		{"itoa", strconv.Itoa, false},
+		{"float", func(f float64) int { return int(f) }, false},
+		{"noResult", func(int) {}, false},
+		{"twoInts", func(int) (int, int) { return 0, 0 }, false},
+		{"notFunc", 42, false},
+		{"nil", nil, false},
+		{"nilFunc", (func(int) int)(nil), false},
+	}
+	env := NewEnv()
+	for _, test := range tests {
+		if err := env.RegisterFunc(test.name, test.fn); (err == nil) != test.ok {
+			t.Errorf("RegisterFunc(%s) error %v", test.name, err)
+		}
+	}
+	if names := env.FuncNames(); !slices.Equal(names, []string{"add"}) {
+		t.Errorf("FuncNames = %v, want [add]", names)
+	}
+
+	child := env.Child()
+	child.SetVar("a", 1)
+	if got, err := Evaluate("add(a, 2)", child); err != nil || got != 3 {
+		t.Errorf("add(a, 2) in a child = %d, %v", got, err)
+	}
+	if _, ok := env.Var("a"); ok {
+		t.Error("a variable of the child is visible in the parent")
+	}
+}
@@ -0,0 +1,172 @@
+package expr
+
+import (
+	"fmt"
+	"strconv"
+)
+
+/*
+
+The grammar, from the lowest to the highest precedence:
+
+	expr    = term { ("+" | "-") term }
+	term    = unary { ("*" | "/" | "%") unary }
+	unary   = ("+" | "-") unary | primary
+	primary = number | ident | ident "(" [ expr { "," expr } ] ")" | "(" expr ")"
+
+All binary operators are left associative, so 10 - 2 - 3 is (10 - 2) - 3.
+The parser is a precedence climbing parser: parseBinary parses operators
+whose precedence is at least minPrec and recurses with a higher minimum
+for the right hand side.
+
+*/
+
+var precedence = map[string]int{
+	"+": 1,
+	"-": 1,
+	"*": 2,
+	"/": 2,
+	"%": 2,
+}
+
+type parser struct {
+	tokens []token
+	index  int
+}
+
+// Parse parses src into an AST
+func Parse(src string) (Node, error) {
+	tokens, err := tokenize(src)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	node, err := p.parseBinary(1)
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, p.unexpected(tok)
+	}
+	return node, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.index]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.index]
+	if tok.kind != tokenEOF {
+		p.index++
+	}
+	return tok
+}
+
+func (p *parser) expect(kind tokenKind) (token, error) {
+	tok := p.next()
+	if tok.kind != kind {
+		return tok, &Error{Pos: tok.pos, Msg: fmt.Sprintf("expected %s, found %s", kind, tok)}
+	}
+	return tok, nil
+}
+
+func (p *parser) unexpected(tok token) error {
+	return &Error{Pos: tok.pos, Msg: fmt.Sprintf("unexpected %s", tok)}
+}
+
+func (p *parser) parseBinary(minPrec int) (Node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		tok := p.peek()
+		prec, isOp := precedence[tok.text]
+		if tok.kind != tokenOperator || !isOp || prec < minPrec {
+			return left, nil
+		}
+		p.next()
+
+		right, err := p.parseBinary(prec + 1)
+		if err != nil {
+			return nil, err
+		}
+		left = &BinaryExpr{Column: tok.pos, Op: tok.text[0], Left: left, Right: right}
+	}
+}
+
+func (p *parser) parseUnary() (Node, error) {
+	tok := p.peek()
+	if tok.kind == tokenOperator && (tok.text == "-" || tok.text == "+") {
+		p.next()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &UnaryExpr{Column: tok.pos, Op: tok.text[0], Operand: operand}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (Node, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokenNumber:
+		value, err := strconv.Atoi(tok.text)
+		if err != nil {
+			return nil, &Error{Pos: tok.pos, Msg: fmt.Sprintf("number %s is out of range", tok.text), Err: err}
+		}
+		return &NumberLit{Column: tok.pos, Value: value}, nil
+
+	case tokenIdent:
+		if p.peek().kind != tokenLParen {
+			return &Ident{Column: tok.pos, Name: tok.text}, nil
+		}
+		p.next()
+		args, err := p.parseArgs()
+		if err != nil {
+			return nil, err
+		}
+		return &CallExpr{Column: tok.pos, Name: tok.text, Args: args}, nil
+
+	case tokenLParen:
+		node, err := p.parseBinary(1)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(tokenRParen); err != nil {
+			return nil, err
+		}
+		return node, nil
+	}
+	return nil, p.unexpected(tok)
+}
+
+// parseArgs parses a call's argument list, the opening parenthesis has already been consumed
+func (p *parser) parseArgs() ([]Node, error) {
+	var args []Node
+	if p.peek().kind == tokenRParen {
+		p.next()
+		return args, nil
+	}
+
+	for {
+		arg, err := p.parseBinary(1)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+
+		tok := p.next()
+		switch tok.kind {
+		case tokenRParen:
+			return args, nil
+		case tokenComma:
+			continue
+		}
+		return nil, &Error{Pos: tok.pos, Msg: fmt.Sprintf("expected ',' or ')', found %s", tok)}
+	}
+}
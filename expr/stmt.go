@@ -0,0 +1,206 @@
+package expr
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+/*
+
+Statements extend expressions with definitions:
+
+	x = 3 * 4             assigns a variable
+	area(w, h) = w * h    defines a function, the body is evaluated on every call
+	area(x, 2) + 1        a plain expression
+
+Only ParseStatement accepts '=', Parse still rejects it.
+
+*/
+
+// Stmt is implemented by *AssignStmt, *FuncDefStmt and *ExprStmt
+type Stmt interface {
+	Pos() int
+	String() string
+}
+
+// AssignStmt assigns the value of an expression to a variable
+type AssignStmt struct {
+	Column int
+	Name   string
+	Value  Node
+}
+
+// FuncDefStmt defines a function whose body is an expression over its parameters
+type FuncDefStmt struct {
+	Column int
+	Name   string
+	Params []string
+	Body   Node
+}
+
+// ExprStmt is an expression used as a statement
+type ExprStmt struct {
+	Expr Node
+}
+
+func (stmt *AssignStmt) Pos() int  { return stmt.Column }
+func (stmt *FuncDefStmt) Pos() int { return stmt.Column }
+func (stmt *ExprStmt) Pos() int    { return stmt.Expr.Pos() }
+
+func (stmt *AssignStmt) String() string {
+	return fmt.Sprintf("%s = %s", stmt.Name, stmt.Value)
+}
+
+func (stmt *FuncDefStmt) String() string {
+	return fmt.Sprintf("%s(%s) = %s", stmt.Name, strings.Join(stmt.Params, ", "), stmt.Body)
+}
+
+func (stmt *ExprStmt) String() string {
+	return stmt.Expr.String()
+}
+
+// ParseStatement parses an assignment, a function definition or an expression
+func ParseStatement(src string) (Stmt, error) {
+	tokens, err := tokenize(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+
+	hasAssign := false
+	for _, tok := range tokens {
+		if tok.kind == tokenAssign {
+			hasAssign = true
+			break
+		}
+	}
+	if !hasAssign {
+		node, err := p.parseBinary(1)
+		if err != nil {
+			return nil, err
+		}
+		if tok := p.peek(); tok.kind != tokenEOF {
+			return nil, p.unexpected(tok)
+		}
+		return &ExprStmt{Expr: node}, nil
+	}
+
+	name, err := p.expect(tokenIdent)
+	if err != nil {
+		return nil, &Error{Pos: name.pos, Msg: "left side of '=' must be a variable or a function signature"}
+	}
+
+	var stmt Stmt
+	var params []string
+	isFunc := p.peek().kind == tokenLParen
+	if isFunc {
+		p.next()
+		if params, err = p.parseParams(); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := p.expect(tokenAssign); err != nil {
+		return nil, err
+	}
+	body, err := p.parseBinary(1)
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, p.unexpected(tok)
+	}
+
+	if isFunc {
+		stmt = &FuncDefStmt{Column: name.pos, Name: name.text, Params: params, Body: body}
+	} else {
+		stmt = &AssignStmt{Column: name.pos, Name: name.text, Value: body}
+	}
+	return stmt, nil
+}
+
+// parseParams parses a parameter list, the opening parenthesis has already been consumed
+func (p *parser) parseParams() ([]string, error) {
+	var params []string
+	seen := make(map[string]bool)
+	if p.peek().kind == tokenRParen {
+		p.next()
+		return params, nil
+	}
+
+	for {
+		param, err := p.expect(tokenIdent)
+		if err != nil {
+			return nil, err
+		}
+		if seen[param.text] {
+			return nil, &Error{Pos: param.pos, Msg: fmt.Sprintf("duplicate parameter %s", param.text)}
+		}
+		seen[param.text] = true
+		params = append(params, param.text)
+
+		tok := p.next()
+		switch tok.kind {
+		case tokenRParen:
+			return params, nil
+		case tokenComma:
+			continue
+		}
+		return nil, &Error{Pos: tok.pos, Msg: fmt.Sprintf("expected ',' or ')', found %s", tok)}
+	}
+}
+
+// MaxCallDepth bounds the nesting of calls to functions defined with DefineExpr
+// the language has no conditionals, so a recursive definition would never stop
+const MaxCallDepth = 1000
+
+var ErrMaxDepth = errors.New("maximum call depth exceeded")
+
+// DefineExpr defines the function name(params...) = body in env
+// on every call the arguments are bound to the parameters in a child of env,
+// so the body sees the variables of env as they are at call time
+// the call depth travels with the scopes of the calls rather than with the
+// function, so goroutines evaluating in the same env don't share it
+func (env *Env) DefineExpr(name string, params []string, body Node) {
+	call := func(depth int, args []int) (int, error) {
+		if depth >= MaxCallDepth {
+			return 0, &Error{Pos: body.Pos(), Msg: ErrMaxDepth.Error(), Err: ErrMaxDepth}
+		}
+		scope := env.Child()
+		scope.depth = depth + 1
+		for index, param := range params {
+			scope.SetVar(param, args[index])
+		}
+		return Eval(body, scope)
+	}
+	env.Define(Func{
+		Name:  name,
+		Arity: len(params),
+		// called from Go, outside of any expression
+		Call: func(args []int) (int, error) { return call(0, args) },
+		call: call,
+	})
+}
+
+// Exec executes stmt in env and returns the value it produced
+// for a FuncDefStmt the value is always 0
+func Exec(stmt Stmt, env *Env) (int, error) {
+	switch stmt := stmt.(type) {
+	case *ExprStmt:
+		return Eval(stmt.Expr, env)
+
+	case *AssignStmt:
+		value, err := Eval(stmt.Value, env)
+		if err != nil {
+			return 0, err
+		}
+		env.SetVar(stmt.Name, value)
+		return value, nil
+
+	case *FuncDefStmt:
+		env.DefineExpr(stmt.Name, stmt.Params, stmt.Body)
+		return 0, nil
+	}
+	return 0, fmt.Errorf("expr: unknown statement type %T", stmt)
+}
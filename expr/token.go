@@ -0,0 +1,122 @@
+package expr
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// kinds of tokens produced by the lexer
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenNumber
+	tokenIdent
+	tokenOperator // one of + - * / %
+	tokenLParen
+	tokenRParen
+	tokenComma
+	tokenAssign // only valid in statements, see ParseStatement
+)
+
+func (kind tokenKind) String() string {
+	switch kind {
+	case tokenEOF:
+		return "end of input"
+	case tokenNumber:
+		return "number"
+	case tokenIdent:
+		return "identifier"
+	case tokenOperator:
+		return "operator"
+	case tokenLParen:
+		return "'('"
+	case tokenRParen:
+		return "')'"
+	case tokenComma:
+		return "','"
+	case tokenAssign:
+		return "'='"
+	}
+	return fmt.Sprintf("token(%d)", int(kind))
+}
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int // 1-based column of the first character of the token
+}
+
+func (tok token) String() string {
+	if tok.kind == tokenEOF {
+		return tok.kind.String()
+	}
+	return fmt.Sprintf("%s %q", tok.kind, tok.text)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isIdentPart(c byte) bool {
+	return isIdentStart(c) || isDigit(c)
+}
+
+// tokenize splits src into tokens, the last token is always tokenEOF
+func tokenize(src string) ([]token, error) {
+	var tokens []token
+	index := 0
+	for index < len(src) {
+		c := src[index]
+		start := index
+
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			index++
+			continue
+		case isDigit(c):
+			for index < len(src) && isDigit(src[index]) {
+				index++
+			}
+			// "12abc" is almost certainly a typo, report it here instead of
+			// as a confusing "unexpected identifier" later
+			if index < len(src) && isIdentStart(src[index]) {
+				return nil, &Error{Pos: index + 1, Msg: fmt.Sprintf("unexpected character %q in number", src[index])}
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: src[start:index], pos: start + 1})
+			continue
+		case isIdentStart(c):
+			for index < len(src) && isIdentPart(src[index]) {
+				index++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: src[start:index], pos: start + 1})
+			continue
+		}
+
+		var kind tokenKind
+		switch c {
+		case '+', '-', '*', '/', '%':
+			kind = tokenOperator
+		case '(':
+			kind = tokenLParen
+		case ')':
+			kind = tokenRParen
+		case ',':
+			kind = tokenComma
+		case '=':
+			kind = tokenAssign
+		default:
+			r, _ := utf8.DecodeRuneInString(src[start:])
+			return nil, &Error{Pos: start + 1, Msg: fmt.Sprintf("unexpected character %q", r)}
+		}
+		tokens = append(tokens, token{kind: kind, text: src[start : start+1], pos: start + 1})
+		index++
+	}
+
+	tokens = append(tokens, token{kind: tokenEOF, pos: len(src) + 1})
+	return tokens, nil
+}
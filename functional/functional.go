@@ -0,0 +1,116 @@
+package functional
+
+/*
+
+Generic versions of the higher order functions main writes by hand for
+func(int, int) int: selfMath builds a func(int) int out of a func(int, int) int,
+aggregate folds three values with one. The helpers below work with any
+parameter and result types.
+
+Go has no variadic type parameters, so the helpers that depend on the number
+of parameters come in one version per arity (Curry2, Curry3, ...). Functions
+with more parameters are rare enough that a closure written by hand is clearer.
+
+	Compose(f, g)   x -> f(g(x))      read right to left, like math
+	Pipe(g, f)      x -> f(g(x))      read left to right, like a shell pipeline
+	Curry2(f)       a -> b -> f(a, b)
+	Uncurry2(f)     (a, b) -> f(a)(b)
+	Partial2(f, a)  b -> f(a, b)
+	Flip(f)         (b, a) -> f(a, b)
+	Self(f)         x -> f(x, x)      what selfMath does
+
+*/
+
+// Compose returns the function x -> f(g(x))
+func Compose[A, B, C any](f func(B) C, g func(A) B) func(A) C {
+	return func(a A) C {
+		return f(g(a))
+	}
+}
+
+// Pipe returns the function x -> g(f(x)), f runs first
+func Pipe[A, B, C any](f func(A) B, g func(B) C) func(A) C {
+	return func(a A) C {
+		return g(f(a))
+	}
+}
+
+// Pipe3 returns the function x -> h(g(f(x))), f runs first
+func Pipe3[A, B, C, D any](f func(A) B, g func(B) C, h func(C) D) func(A) D {
+	return func(a A) D {
+		return h(g(f(a)))
+	}
+}
+
+// Chain pipes any number of functions with the same parameter and result
+// type, from the first to the last; with no functions it is the identity
+func Chain[T any](fns ...func(T) T) func(T) T {
+	return func(value T) T {
+		for _, fn := range fns {
+			value = fn(value)
+		}
+		return value
+	}
+}
+
+// Curry2 turns f(a, b) into f(a)(b)
+func Curry2[A, B, R any](f func(A, B) R) func(A) func(B) R {
+	return func(a A) func(B) R {
+		return func(b B) R {
+			return f(a, b)
+		}
+	}
+}
+
+// Curry3 turns f(a, b, c) into f(a)(b)(c)
+func Curry3[A, B, C, R any](f func(A, B, C) R) func(A) func(B) func(C) R {
+	return func(a A) func(B) func(C) R {
+		return func(b B) func(C) R {
+			return func(c C) R {
+				return f(a, b, c)
+			}
+		}
+	}
+}
+
+// Uncurry2 turns f(a)(b) back into f(a, b)
+func Uncurry2[A, B, R any](f func(A) func(B) R) func(A, B) R {
+	return func(a A, b B) R {
+		return f(a)(b)
+	}
+}
+
+// Uncurry3 turns f(a)(b)(c) back into f(a, b, c)
+func Uncurry3[A, B, C, R any](f func(A) func(B) func(C) R) func(A, B, C) R {
+	return func(a A, b B, c C) R {
+		return f(a)(b)(c)
+	}
+}
+
+// Partial2 fixes the first argument of f
+func Partial2[A, B, R any](f func(A, B) R, a A) func(B) R {
+	return func(b B) R {
+		return f(a, b)
+	}
+}
+
+// Partial3 fixes the first argument of f
+func Partial3[A, B, C, R any](f func(A, B, C) R, a A) func(B, C) R {
+	return func(b B, c C) R {
+		return f(a, b, c)
+	}
+}
+
+// Flip swaps the two parameters of f
+func Flip[A, B, R any](f func(A, B) R) func(B, A) R {
+	return func(b B, a A) R {
+		return f(a, b)
+	}
+}
+
+// Self passes its single argument twice to f, Self(mult) squares and Self(add) doubles
+func Self[A, R any](f func(A, A) R) func(A) R {
+	return func(a A) R {
+		return f(a, a)
+	}
+}
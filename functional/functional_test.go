@@ -0,0 +1,132 @@
+package functional
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func sub(a, b int) int    { return a - b }
+func double(n int) int    { return 2 * n }
+func increment(n int) int { return n + 1 }
+
+func TestCombinators(t *testing.T) {
+	sum3 := func(a, b, c int) int { return 100*a + 10*b + c }
+	tests := []struct {
+		name      string
+		got, want any
+	}{
+		{"Compose", Compose(double, increment)(5), 12},
+		{"Pipe", Pipe(double, increment)(5), 11},
+		{"Pipe3", Pipe3(double, increment, strconv.Itoa)(5), "11"},
+		{"Chain", Chain(double, increment, double)(5), 22},
+		{"empty Chain", Chain[int]()(5), 5},
+		{"Curry2", Curry2(sub)(10)(3), 7},
+		{"Curry3", Curry3(sum3)(1)(2)(3), 123},
+		{"Uncurry2", Uncurry2(Curry2(sub))(10, 3), 7},
+		{"Uncurry3", Uncurry3(Curry3(sum3))(1, 2, 3), 123},
+		{"Partial2", Partial2(sub, 10)(3), 7},
+		{"Partial3", Partial3(sum3, 1)(2, 3), 123},
+		{"Flip", Flip(sub)(10, 3), -7},
+		{"Self", Self(func(a, b int) int { return a * b })(7), 49},
+	}
+	for _, test := range tests {
+		if test.got != test.want {
+			t.Errorf("%s = %v, want %v", test.name, test.got, test.want)
+		}
+	}
+}
+
+func TestMemoize(t *testing.T) {
+	calls := 0
+	upper := Memoize(func(s string) string { calls++; return strings.ToUpper(s) })
+	for _, s := range []string{"a", "b", "a", "a", "b"} {
+		if got := upper(s); got != strings.ToUpper(s) {
+			t.Errorf("upper(%q) = %q", s, got)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("Memoize called f %d times, want 2", calls)
+	}
+
+	calls = 0
+	power := Memoize2(func(base, exp int) int {
+		calls++
+		result := 1
+		for range exp {
+			result *= base
+		}
+		return result
+	})
+	if power(2, 10) != 1024 || power(10, 2) != 100 || power(2, 10) != 1024 || calls != 2 {
+		t.Errorf("Memoize2 called f %d times, want 2", calls)
+	}
+}
+
+func TestMemoizeRecursive(t *testing.T) {
+	calls := 0
+	fib := MemoizeRecursive(func(fib func(int) int, n int) int {
+		calls++
+		if n < 2 {
+			return n
+		}
+		return fib(n-1) + fib(n-2)
+	})
+	if got := fib(90); got != 2880067194370816120 {
+		t.Errorf("fib(90) = %d", got)
+	}
+	// once per argument, where the plain recursion takes about 10^19 calls
+	if calls != 91 {
+		t.Errorf("f called %d times, want 91", calls)
+	}
+}
+
+func TestMemoizeErr(t *testing.T) {
+	failures := 1
+	calls := 0
+	parse := MemoizeErr(func(s string) (int, error) {
+		calls++
+		if failures > 0 {
+			failures--
+			return 7, errors.New("transient")
+		}
+		return strconv.Atoi(s)
+	})
+
+	if value, err := parse("42"); err == nil || value != 0 {
+		t.Errorf("first call = %d, %v, want the error and a zero value", value, err)
+	}
+	for range 3 {
+		if value, err := parse("42"); err != nil || value != 42 {
+			t.Errorf("retry = %d, %v, want 42", value, err)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("f called %d times, want 2: the failure is retried, the success cached", calls)
+	}
+}
+
+func TestMemoizeConcurrent(t *testing.T) {
+	var calls atomic.Int64
+	square := Memoize(func(n int) int { calls.Add(1); return n * n })
+	var wait sync.WaitGroup
+	for range 8 {
+		wait.Add(1)
+		go func() {
+			defer wait.Done()
+			for n := range 100 {
+				if got := square(n); got != n*n {
+					t.Errorf("square(%d) = %d", n, got)
+				}
+			}
+		}()
+	}
+	wait.Wait()
+	// a missing argument may be computed by several goroutines at once, never more than once each
+	if calls.Load() < 100 || calls.Load() > 800 {
+		t.Errorf("f called %d times", calls.Load())
+	}
+}
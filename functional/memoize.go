@@ -0,0 +1,109 @@
+package functional
+
+import "sync"
+
+/*
+
+Memoize caches the results of a pure function, so that calling it again with
+the same argument returns the cached result instead of computing it again.
+
+The memoized functions are safe for concurrent use. The lock is not held while
+f runs, so that slow calls with different arguments don't wait for each other
+and recursive functions can call themselves; two goroutines asking for the
+same missing argument at the same time may both compute it, and the first
+result stored wins.
+
+The cache never shrinks: memoize functions whose arguments come from a small set.
+
+*/
+
+// memo is a concurrency safe cache of computed results
+type memo[K comparable, V any] struct {
+	mu      sync.Mutex
+	results map[K]V
+}
+
+// get returns the cached result for key, or computes it; the result is only
+// stored when compute reports it as cacheable
+func (cache *memo[K, V]) get(key K, compute func() (V, bool)) V {
+	cache.mu.Lock()
+	value, ok := cache.results[key]
+	cache.mu.Unlock()
+	if ok {
+		return value
+	}
+
+	value, cacheable := compute()
+	if !cacheable {
+		return value
+	}
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	if stored, ok := cache.results[key]; ok {
+		return stored
+	}
+	cache.results[key] = value
+	return value
+}
+
+// Memoize returns f with a cache of its results
+func Memoize[K comparable, V any](f func(K) V) func(K) V {
+	cache := &memo[K, V]{results: make(map[K]V)}
+	return func(key K) V {
+		return cache.get(key, func() (V, bool) { return f(key), true })
+	}
+}
+
+// Memoize2 returns f with a cache of its results, keyed by both arguments
+func Memoize2[A, B comparable, V any](f func(A, B) V) func(A, B) V {
+	type key struct {
+		a A
+		b B
+	}
+	cache := &memo[key, V]{results: make(map[key]V)}
+	return func(a A, b B) V {
+		return cache.get(key{a, b}, func() (V, bool) { return f(a, b), true })
+	}
+}
+
+/*
+MemoizeRecursive memoizes a recursive function. A plain Memoize doesn't help
+there, since the recursive calls go to the original function and not to the
+memoized one, so f gets the memoized function as its first parameter and
+must make its recursive calls through it:
+
+	fib := functional.MemoizeRecursive(func(fib func(int) int, n int) int {
+		if n < 2 {
+			return n
+		}
+		return fib(n-1) + fib(n-2)
+	})
+*/
+func MemoizeRecursive[K comparable, V any](f func(self func(K) V, key K) V) func(K) V {
+	cache := &memo[K, V]{results: make(map[K]V)}
+	var self func(K) V
+	self = func(key K) V {
+		return cache.get(key, func() (V, bool) { return f(self, key), true })
+	}
+	return self
+}
+
+// MemoizeErr memoizes a function that can fail, only the successful results
+// are cached so that a failed call is retried the next time
+func MemoizeErr[K comparable, V any](f func(K) (V, error)) func(K) (V, error) {
+	cache := &memo[K, V]{results: make(map[K]V)}
+	return func(key K) (V, error) {
+		var err error
+		value := cache.get(key, func() (V, bool) {
+			var value V
+			value, err = f(key)
+			return value, err == nil
+		})
+		if err != nil {
+			var zero V
+			return zero, err
+		}
+		return value, nil
+	}
+}
@@ -0,0 +1,185 @@
+package idgen
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+/*
+
+Blocks hands out IDs 1, 2, 3... like counter(), but remembers in a file where
+it got to, so a restarted process doesn't hand out the same IDs again.
+
+Writing the file for every ID would be slow, so the generator reserves a
+block of IDs at a time: the file holds the first ID nobody has reserved yet,
+and the generator moves it forward by BlockSize before handing out the IDs of
+the block from memory. The IDs left in a block when the process stops are
+never used, so a restart leaves a gap, but never a duplicate.
+
+The file is replaced atomically (written to a temporary file, synced, then
+renamed over the old one), so a crash in the middle leaves either the old or
+the new reservation, both safe. Several processes can share the file: a
+reservation holds a lock on a file next to it (the path with ".lock"
+appended) for the few milliseconds it takes. On Unix that is an flock, which
+the kernel releases when a process dies, on a file that is never removed, so
+two processes can't each hold a lock on a different file. Elsewhere the lock
+is the file itself, only ever removed by the process that created it; one left
+behind by a crash has to be removed by hand.
+
+*/
+
+var (
+	ErrCorrupt = errors.New("idgen: corrupt reservation file")
+	ErrLocked  = errors.New("idgen: reservation file locked")
+
+	// errBusy is what tryLock returns when another process holds the lock
+	errBusy = errors.New("idgen: lock busy")
+)
+
+const (
+	DefaultBlockSize = 1000
+
+	lockTimeout    = 10 * time.Second
+	lockRetryDelay = 5 * time.Millisecond
+)
+
+type Blocks struct {
+	path      string
+	blockSize int64
+
+	mu   sync.Mutex
+	next int64 // next ID to hand out
+	end  int64 // end of the reserved block, excluded
+}
+
+// NewBlocks returns a generator keeping its reservations in the file at path,
+// created on first use, and reserving blockSize IDs at a time
+// (DefaultBlockSize when blockSize is 0)
+func NewBlocks(path string, blockSize int64) (*Blocks, error) {
+	if blockSize == 0 {
+		blockSize = DefaultBlockSize
+	}
+	if blockSize < 0 {
+		return nil, fmt.Errorf("idgen: invalid block size %d", blockSize)
+	}
+	// the first reservation happens now, so a bad path or a corrupt file are
+	// reported here rather than by the first Next
+	blocks := &Blocks{path: path, blockSize: blockSize}
+	if err := blocks.reserve(); err != nil {
+		return nil, err
+	}
+	return blocks, nil
+}
+
+// Next returns the next ID, reserving a new block first when the current one is used up
+func (blocks *Blocks) Next() (int64, error) {
+	blocks.mu.Lock()
+	defer blocks.mu.Unlock()
+	if blocks.next == blocks.end {
+		if err := blocks.reserve(); err != nil {
+			return 0, err
+		}
+	}
+	id := blocks.next
+	blocks.next++
+	return id, nil
+}
+
+// reserve moves the reservation in the file forward by a block, and makes it the current block
+func (blocks *Blocks) reserve() error {
+	unlock, err := blocks.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	start, err := blocks.read()
+	if err != nil {
+		return err
+	}
+	if start > math.MaxInt64-blocks.blockSize {
+		return ErrExhausted
+	}
+	end := start + blocks.blockSize
+	if err := blocks.write(end); err != nil {
+		return err
+	}
+	blocks.next, blocks.end = start, end
+	return nil
+}
+
+// read returns the first unreserved ID, 1 when the file doesn't exist yet
+func (blocks *Blocks) read() (int64, error) {
+	content, err := os.ReadFile(blocks.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return 1, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	start, err := strconv.ParseInt(strings.TrimSpace(string(content)), 10, 64)
+	if err != nil || start < 1 {
+		return 0, fmt.Errorf("%w: %s: %q", ErrCorrupt, blocks.path, content)
+	}
+	return start, nil
+}
+
+// write replaces the content of the file with next, atomically and durably
+func (blocks *Blocks) write(next int64) error {
+	dir := filepath.Dir(blocks.path)
+	temp, err := os.CreateTemp(dir, filepath.Base(blocks.path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name()) // fails harmlessly once renamed
+
+	if _, err := temp.WriteString(strconv.FormatInt(next, 10) + "\n"); err != nil {
+		temp.Close()
+		return err
+	}
+	if err := temp.Sync(); err != nil {
+		temp.Close()
+		return err
+	}
+	if err := temp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(temp.Name(), blocks.path); err != nil {
+		return err
+	}
+
+	// the rename itself is only durable once the directory is synced
+	// (not supported everywhere, Windows for instance, hence no error)
+	if dirFile, err := os.Open(dir); err == nil {
+		dirFile.Sync()
+		dirFile.Close()
+	}
+	return nil
+}
+
+// lock takes the lock of the reservation file, waiting up to lockTimeout for
+// other processes to release it
+func (blocks *Blocks) lock() (unlock func(), err error) {
+	lockPath := blocks.path + ".lock"
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		unlock, err := tryLock(lockPath)
+		if err == nil {
+			return unlock, nil
+		}
+		if !errors.Is(err, errBusy) {
+			return nil, err
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("%w: %s", ErrLocked, lockPath)
+		}
+		time.Sleep(lockRetryDelay)
+	}
+}
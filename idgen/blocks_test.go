@@ -0,0 +1,103 @@
+package idgen
+
+import (
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestBlocksRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ids")
+	tests := []struct {
+		count int
+		first int64
+		file  string // content after the IDs were handed out
+	}{
+		{5, 1, "11\n"},
+		{12, 11, "31\n"}, // the 5 left in the first block are skipped, never repeated
+		{1, 31, "41\n"},
+	}
+	for _, test := range tests {
+		blocks, err := NewBlocks(path, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for index := range test.count {
+			id, err := blocks.Next()
+			if want := test.first + int64(index); err != nil || id != want {
+				t.Fatalf("Next = %d, %v, want %d", id, err, want)
+			}
+		}
+		if content, _ := os.ReadFile(path); string(content) != test.file {
+			t.Errorf("file holds %q, want %q", content, test.file)
+		}
+	}
+}
+
+func TestBlocksErrors(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		content string
+		want    error
+	}{
+		{"abc", ErrCorrupt},
+		{"0", ErrCorrupt},
+		{"-5\n", ErrCorrupt},
+		{strconv.FormatInt(math.MaxInt64-5, 10), ErrExhausted},
+	}
+	for index, test := range tests {
+		path := filepath.Join(dir, strconv.Itoa(index))
+		os.WriteFile(path, []byte(test.content), 0o644)
+		if _, err := NewBlocks(path, 10); !errors.Is(err, test.want) {
+			t.Errorf("NewBlocks with %q error %v, want %v", test.content, err, test.want)
+		}
+	}
+
+	if _, err := NewBlocks(filepath.Join(dir, "x"), -1); err == nil {
+		t.Error("NewBlocks with a negative block size succeeded")
+	}
+	if _, err := NewBlocks(filepath.Join(dir, "missing", "ids"), 10); err == nil {
+		t.Error("NewBlocks in a missing directory succeeded")
+	}
+}
+
+// generators sharing a file never hand out the same ID, as if in different processes
+func TestBlocksShared(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ids")
+	const generators, perGenerator = 4, 500
+
+	var wait sync.WaitGroup
+	var mu sync.Mutex
+	seen := make(map[int64]bool)
+	for range generators {
+		blocks, err := NewBlocks(path, 7)
+		if err != nil {
+			t.Fatal(err)
+		}
+		wait.Add(1)
+		go func() {
+			defer wait.Done()
+			for range perGenerator {
+				id, err := blocks.Next()
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				mu.Lock()
+				if seen[id] {
+					t.Errorf("ID %d handed out twice", id)
+				}
+				seen[id] = true
+				mu.Unlock()
+			}
+		}()
+	}
+	wait.Wait()
+	if len(seen) != generators*perGenerator {
+		t.Errorf("%d distinct IDs, want %d", len(seen), generators*perGenerator)
+	}
+}
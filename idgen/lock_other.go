@@ -0,0 +1,25 @@
+//go:build !unix
+
+package idgen
+
+import (
+	"errors"
+	"os"
+)
+
+// tryLock creates the file at path, and fails with errBusy when it exists.
+// Without flock there is no safe way to tell a lock left behind by a crash
+// from a live one, so such a lock is never taken over: it has to be removed by
+// hand, and until then reservations fail with ErrLocked.
+func tryLock(path string) (unlock func(), err error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	if errors.Is(err, os.ErrExist) {
+		return nil, errBusy
+	}
+	if err != nil {
+		return nil, err
+	}
+	file.Close()
+	// nobody else removes the file, so it is still the one created here
+	return func() { os.Remove(path) }, nil
+}
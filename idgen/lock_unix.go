@@ -0,0 +1,28 @@
+//go:build unix
+
+package idgen
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// tryLock takes an exclusive flock on the file at path, creating it if needed.
+// The kernel releases the lock when the process dies, so a crash never leaves
+// a stale lock behind, and the file itself can stay forever.
+func tryLock(path string) (unlock func(), err error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	if err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		file.Close()
+		if errors.Is(err, syscall.EWOULDBLOCK) || errors.Is(err, syscall.EINTR) {
+			return nil, errBusy
+		}
+		return nil, err
+	}
+	// closing the file releases the lock
+	return func() { file.Close() }, nil
+}
@@ -0,0 +1,167 @@
+package idgen
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+/*
+
+Unique int64 IDs. counter() in main hands out 1, 2, 3... but starts over in
+every process; the generators here keep their IDs unique across processes
+and restarts, in two different ways:
+
+	Snowflake   no shared state at all: the ID is made of the time, the node
+	            and a sequence, so nodes with different numbers never collide
+	Blocks      the next free ID lives in a file, reserved a block at a time
+
+Both hand out strictly increasing IDs within a process.
+
+A Snowflake ID packs, from the most significant bit:
+
+	1 bit    always 0, so the IDs are positive
+	41 bits  milliseconds since the epoch of the generator, about 69 years
+	10 bits  node number, 0 to 1023
+	12 bits  sequence within the millisecond, 0 to 4095
+
+so IDs sort by creation time, and one node hands out up to 4096 IDs per ms.
+
+Clocks sometimes go backwards, after an NTP correction for instance. Going
+back in time would repeat IDs, so the generator never does: it keeps using the
+last timestamp it issued and only takes the wall clock again once it has
+caught up. This works as long as the clock is behind by no more than
+MaxClockSkew; beyond that Next fails with ErrClockRegression rather than issue
+IDs far in the future. The same bound applies when a burst exhausts the
+sequence: the generator moves on to the next millisecond before the clock
+does, and waits only when it gets more than MaxClockSkew ahead.
+
+*/
+
+// Generator hands out unique IDs
+type Generator interface {
+	Next() (int64, error)
+}
+
+const (
+	timestampBits = 41
+	nodeBits      = 10
+	sequenceBits  = 12
+
+	MaxNode     = 1<<nodeBits - 1
+	maxSequence = 1<<sequenceBits - 1
+	maxOffset   = 1<<timestampBits - 1
+)
+
+var (
+	ErrNode            = fmt.Errorf("idgen: node must be between 0 and %d", MaxNode)
+	ErrClockRegression = errors.New("idgen: clock moved backwards beyond the allowed skew")
+	ErrExhausted       = errors.New("idgen: no IDs left")
+)
+
+// DefaultEpoch is the time the timestamps of the IDs are counted from,
+// unless the configuration says otherwise
+var DefaultEpoch = time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// DefaultMaxClockSkew is how far behind the clock can fall before Next gives up
+const DefaultMaxClockSkew = time.Second
+
+type SnowflakeConfig struct {
+	Node  int64     // unique among the processes generating IDs together
+	Epoch time.Time // DefaultEpoch when zero, must not be in the future
+	// MaxClockSkew is how far the issued timestamps can get ahead of the
+	// wall clock, DefaultMaxClockSkew when zero
+	MaxClockSkew time.Duration
+}
+
+type Snowflake struct {
+	node    int64
+	epoch   time.Time
+	maxSkew int64 // in milliseconds
+	now     func() time.Time
+	sleep   func(time.Duration)
+
+	mu       sync.Mutex
+	last     int64 // timestamp of the last ID, in milliseconds since the epoch
+	sequence int64
+}
+
+func NewSnowflake(config SnowflakeConfig) (*Snowflake, error) {
+	if config.Node < 0 || config.Node > MaxNode {
+		return nil, ErrNode
+	}
+	if config.Epoch.IsZero() {
+		config.Epoch = DefaultEpoch
+	}
+	if config.MaxClockSkew <= 0 {
+		config.MaxClockSkew = DefaultMaxClockSkew
+	}
+	if config.Epoch.After(time.Now()) {
+		return nil, fmt.Errorf("idgen: epoch %v is in the future", config.Epoch)
+	}
+	return &Snowflake{
+		node:    config.Node,
+		epoch:   config.Epoch,
+		maxSkew: config.MaxClockSkew.Milliseconds(),
+		now:     time.Now,
+		sleep:   time.Sleep,
+		last:    -1,
+	}, nil
+}
+
+// clock returns the current time in milliseconds since the epoch
+func (snowflake *Snowflake) clock() int64 {
+	return snowflake.now().Sub(snowflake.epoch).Milliseconds()
+}
+
+// Next returns a new ID, larger than every ID returned before by this generator
+func (snowflake *Snowflake) Next() (int64, error) {
+	snowflake.mu.Lock()
+	defer snowflake.mu.Unlock()
+
+	now := snowflake.clock()
+	if now < snowflake.last-snowflake.maxSkew {
+		return 0, fmt.Errorf("%w: %dms behind", ErrClockRegression, snowflake.last-now)
+	}
+
+	timestamp := max(now, snowflake.last)
+	sequence := int64(0)
+	if timestamp == snowflake.last {
+		sequence = snowflake.sequence + 1
+		if sequence > maxSequence {
+			// the millisecond is full, borrow the next one
+			timestamp++
+			sequence = 0
+		}
+	}
+
+	// never get further ahead of the clock than the allowed skew
+	for ahead := timestamp - now; ahead > snowflake.maxSkew; ahead = timestamp - now {
+		snowflake.sleep(time.Duration(ahead-snowflake.maxSkew) * time.Millisecond)
+		now = snowflake.clock()
+	}
+	if timestamp > maxOffset {
+		return 0, ErrExhausted
+	}
+
+	snowflake.last = timestamp
+	snowflake.sequence = sequence
+	return timestamp<<(nodeBits+sequenceBits) | snowflake.node<<sequenceBits | sequence, nil
+}
+
+// Parts are the components of a Snowflake ID
+type Parts struct {
+	Time     time.Time
+	Node     int64
+	Sequence int64
+}
+
+// Decompose splits an ID of this generator, or of any generator with the same epoch
+func (snowflake *Snowflake) Decompose(id int64) Parts {
+	return Parts{
+		Time:     snowflake.epoch.Add(time.Duration(id>>(nodeBits+sequenceBits)) * time.Millisecond),
+		Node:     id >> sequenceBits & MaxNode,
+		Sequence: id & maxSequence,
+	}
+}
@@ -0,0 +1,128 @@
+package idgen
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// fakeClock is a clock that only moves when told to, or when slept on
+type fakeClock struct {
+	now   time.Time
+	slept time.Duration
+}
+
+func (clock *fakeClock) sleep(duration time.Duration) {
+	clock.slept += duration
+	clock.now = clock.now.Add(duration)
+}
+
+func newFakeSnowflake(t *testing.T, node int64) (*Snowflake, *fakeClock) {
+	t.Helper()
+	snowflake, err := NewSnowflake(SnowflakeConfig{Node: node, MaxClockSkew: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	clock := &fakeClock{now: DefaultEpoch.Add(time.Hour)}
+	snowflake.now = func() time.Time { return clock.now }
+	snowflake.sleep = clock.sleep
+	return snowflake, clock
+}
+
+func TestSnowflakeLayout(t *testing.T) {
+	snowflake, clock := newFakeSnowflake(t, 5)
+	tests := []Parts{
+		{Time: clock.now, Node: 5, Sequence: 0},
+		{Time: clock.now, Node: 5, Sequence: 1},
+		{Time: clock.now, Node: 5, Sequence: 2},
+	}
+	for _, want := range tests {
+		id, err := snowflake.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := snowflake.Decompose(id); got != want {
+			t.Errorf("Decompose(%d) = %+v, want %+v", id, got, want)
+		}
+	}
+
+	clock.now = clock.now.Add(time.Millisecond)
+	id, _ := snowflake.Next()
+	want := int64(time.Hour/time.Millisecond+1)<<22 | 5<<12
+	if id != want {
+		t.Errorf("Next after a millisecond = %d, want %d", id, want)
+	}
+}
+
+func TestSnowflakeMonotonic(t *testing.T) {
+	tests := []struct {
+		name string
+		move func(clock *fakeClock)
+		err  error
+	}{
+		{"still clock", func(clock *fakeClock) {}, nil},
+		{"clock forward", func(clock *fakeClock) { clock.now = clock.now.Add(3 * time.Millisecond) }, nil},
+		{"small regression", func(clock *fakeClock) { clock.now = clock.now.Add(-10 * time.Millisecond) }, nil},
+		{"large regression", func(clock *fakeClock) { clock.now = clock.now.Add(-11 * time.Millisecond) }, ErrClockRegression},
+	}
+	for _, test := range tests {
+		snowflake, clock := newFakeSnowflake(t, 1)
+		previous, _ := snowflake.Next()
+		test.move(clock)
+		id, err := snowflake.Next()
+		if !errors.Is(err, test.err) {
+			t.Errorf("%s: error %v, want %v", test.name, err, test.err)
+			continue
+		}
+		if err == nil && id <= previous {
+			t.Errorf("%s: %d after %d", test.name, id, previous)
+		}
+	}
+}
+
+func TestSnowflakeBurst(t *testing.T) {
+	snowflake, clock := newFakeSnowflake(t, 0)
+	start := clock.now
+	previous := int64(-1)
+	// 20 full milliseconds of IDs on a clock that doesn't move: the current
+	// one and 10 borrowed within the skew, then each one waits for the clock
+	for range 20 * (maxSequence + 1) {
+		id, err := snowflake.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if id <= previous {
+			t.Fatalf("%d after %d", id, previous)
+		}
+		previous = id
+		if ahead := snowflake.Decompose(id).Time.Sub(clock.now); ahead > 10*time.Millisecond {
+			t.Fatalf("ID %v ahead of the clock", ahead)
+		}
+	}
+	if want := 9 * time.Millisecond; clock.slept != want || clock.now.Sub(start) != want {
+		t.Errorf("slept %v, want %v", clock.slept, want)
+	}
+}
+
+func TestNewSnowflake(t *testing.T) {
+	tests := []struct {
+		config SnowflakeConfig
+		err    bool
+	}{
+		{SnowflakeConfig{Node: 0}, false},
+		{SnowflakeConfig{Node: MaxNode}, false},
+		{SnowflakeConfig{Node: -1}, true},
+		{SnowflakeConfig{Node: MaxNode + 1}, true},
+		{SnowflakeConfig{Epoch: time.Now().Add(time.Hour)}, true},
+	}
+	for _, test := range tests {
+		if _, err := NewSnowflake(test.config); (err != nil) != test.err {
+			t.Errorf("NewSnowflake(%+v) error %v", test.config, err)
+		}
+	}
+
+	snowflake, _ := NewSnowflake(SnowflakeConfig{Epoch: time.Now().Add(-time.Duration(maxOffset+1) * time.Millisecond)})
+	if _, err := snowflake.Next(); !errors.Is(err, ErrExhausted) {
+		t.Errorf("Next 2^41 ms after the epoch error %v, want ErrExhausted", err)
+	}
+}
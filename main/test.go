@@ -2,11 +2,25 @@ package main
 
 import (
 	"errors"
+	"first/activity"
+	"first/counters"
+	"first/expr"
+	"first/functional"
+	"first/idgen"
 	mathutil "first/mathUtil"
+	"first/matrix"
+	"first/metrics"
+	"first/money"
+	"first/numfmt"
+	"first/password"
+	"first/rational"
+	"first/seq"
+	"first/store"
 	"fmt"
 	"maps"
 	"os"
 	"sync"
+	"time"
 )
 
 type person_t struct {
@@ -163,9 +177,8 @@ calls the Error() method of the error interface behind the scenes
 
 type user_t struct {
 	username   string
-	password   string
+	password   string // a hash from the password package, never the password itself
 	userActive bool
-	activeTime int
 }
 
 type error_t struct {
@@ -178,9 +191,40 @@ func (err error_t) Error() string {
 	return fmt.Sprintf("code: %d, msg: %s", err.code, err.msg)
 }
 
-func getActiveTime(user user_t) (int, error) {
+// checkPassword reports whether plain is the password of user, rehashing the
+// stored hash when it was made with older parameters than password.DefaultParams
+func (user *user_t) checkPassword(plain string) bool {
+	ok, upgraded, err := password.VerifyAndUpgrade(plain, user.password, password.DefaultParams)
+	if err != nil {
+		return ok
+	}
+	if upgraded != "" {
+		user.password = upgraded
+	}
+	return ok
+}
+
+// migratePasswords replaces the plaintext passwords left in users by their hashes,
+// and returns how many it replaced
+func migratePasswords(users []user_t) (int, error) {
+	migrated := 0
+	for index := range users {
+		encoded, changed, err := password.Migrate(users[index].password, password.DefaultParams)
+		if err != nil {
+			return migrated, err
+		}
+		if changed {
+			users[index].password = encoded
+			migrated++
+		}
+	}
+	return migrated, nil
+}
+
+// getActiveTime returns the time user spent logged in, as recorded by tracker
+func getActiveTime(tracker *activity.Tracker, user user_t) (time.Duration, error) {
 	if user.userActive {
-		return user.activeTime, nil
+		return tracker.ActiveTime(user.username), nil
 	} else {
 		return 0, error_t{code: 404, msg: fmt.Sprintf("user %s not active", user.username)}
 	}
@@ -319,14 +363,38 @@ func main() {
 	println(getSomeInfo(car))
 	println(getSomeInfo(human))
 
-	user := user_t{username: "raj", password: "rishika", userActive: false, activeTime: 0}
+	user := user_t{username: "raj", password: "rishika", userActive: false}
+	// the password was stored as is before the password package, hash it
+	users := []user_t{user}
+	if migrated, err := migratePasswords(users); err == nil {
+		user = users[0]
+		fmt.Println(migrated, user.checkPassword("rishika"), user.checkPassword("raj")) // 1 true false
+	}
 
-	activeTime, err := getActiveTime(user)
-	if err != nil {
-		fmt.Println(err)
-	} else {
-		fmt.Printf("user %s active time: %d\n", user.username, activeTime)
+	// sessions end at logout, or after 15 minutes without a heartbeat
+	if tracker, err := activity.NewTracker(15*time.Minute, nil); err == nil {
+		tracker.Login(user.username)
+		tracker.Heartbeat(user.username)
+		tracker.Logout(user.username)
+
+		activeTime, err := getActiveTime(tracker, user)
+		if err != nil {
+			fmt.Println(err)
+		} else {
+			fmt.Printf("user %s active time: %v\n", user.username, activeTime)
+		}
+	}
+
+	// users outlive local variables in a store.UserStore, store.Open keeps them in a file
+	var userStore store.UserStore = store.NewMemory()
+	userStore.Create(store.User{Username: user.username, Password: user.password, Active: user.userActive})
+	if _, err := userStore.Create(store.User{Username: "raj"}); err != nil {
+		fmt.Println(err) // code: 409, msg: user raj already exists
+	}
+	if _, err := userStore.Get("rishika"); errors.Is(err, store.ErrNotFound) {
+		fmt.Println(err) // code: 404, msg: user rishika not found
 	}
+	userStore.Close()
 
 	quotient, remainder, err := test(10, 0)
 	if err != nil {
@@ -335,16 +403,46 @@ func main() {
 		fmt.Printf("quotient: %d, remainder: %d\n", quotient, remainder)
 	}
 
+	// rational.New keeps the exact result of the division instead of a quotient and
+	// a remainder, and fails with mathutil.ErrDivideByZero for a zero denominator
+	exact, err := rational.New(10, 4)
+	if err != nil {
+		fmt.Println(err)
+	} else {
+		fmt.Println(exact, exact.DecimalString(20)) // 5/2 2.5
+	}
+
+	// test() truncates toward zero like / and %, mathutil.DivMod lets us pick the rounding
+	// so that negative values land in the right bucket
+	for _, mode := range []mathutil.DivMode{mathutil.DivTrunc, mathutil.DivFloor, mathutil.DivEuclid, mathutil.DivCeil} {
+		quotient, remainder, err := mathutil.DivMod(-7, 2, mode)
+		if err != nil {
+			fmt.Println(err)
+			continue
+		}
+		fmt.Printf("%s: -7 / 2 = %d remainder %d\n", mode, quotient, remainder)
+	}
+
 	// a for loop in GO
+	// the costs are money.Money values: 0.01*float64(index) isn't exact for most indexes,
+	// while index cents are, the scale of 3 keeps the 3 digits %0.3f used to print
 	for index := 0; index < 10; index++ {
-		fmt.Printf("cost for index %d is %0.3f\n", index, 0.01*float64(index))
+		cost, err := money.New("USD", 10*int64(index), 3)
+		if err != nil {
+			fmt.Println(err)
+			break
+		}
+		fmt.Printf("cost for index %d is %s\n", index, cost.Decimal())
 	}
 
 	// there is no explicit while loop
 	// a while loop is just a for loop with just the condition
 	index := 0
+	cost, _ := money.New("USD", 0, 3)
+	cent, _ := money.New("USD", 1, 2)
 	for index < 10 {
-		fmt.Printf("cost for index %d is %0.3f\n", index, 0.01*float64(index))
+		fmt.Printf("cost for index %d is %s\n", index, cost.Decimal())
+		cost, _ = cost.Add(cent)
 		index++
 	}
 
@@ -556,6 +654,14 @@ func main() {
 	srcSlice := []int{1, 2, 3}
 	fmt.Println(len(srcSlice), cap(srcSlice))
 
+	// the matrix package stores the same values in a single row-major slice and
+	// reports out of range indices as errors instead of panicking
+	typedMatrix, _ := matrix.FromFunc(10, 10, func(row, col int) int { return row * col })
+	if _, err := typedMatrix.At(10, 0); err != nil {
+		fmt.Println(err)
+	}
+	fmt.Print(typedMatrix.Transpose())
+
 	matrix := createMatrix(10, 10)
 	for row := 0; row < 10; row++ {
 		for col := 0; col < 10; col++ {
@@ -746,6 +852,9 @@ func main() {
 	fmt.Println(aggregate(10, 9, 8, add))  // will print 27
 	fmt.Println(aggregate(10, 9, 8, mult)) // will print 720
 
+	// seq.Reduce is aggregate for any number of values, taken lazily from a sequence
+	fmt.Println(seq.Reduce(seq.Range(8, 11), 1, mult)) // will print 720
+
 	// Go supports first-class functions, meaning functions can be:
 	// assigned to variables
 	// passed as arguments
@@ -761,6 +870,11 @@ func main() {
 	doubleFunc := selfMath(add)
 	fmt.Println(doubleFunc(12))
 
+	// the functional package generalizes selfMath and currying to any function type
+	fmt.Println(functional.Self(mult)(12))                 // 144, like squareFunc
+	addTen := functional.Curry2(add)(10)                   // func(int) int
+	fmt.Println(functional.Compose(addTen, doubleFunc)(5)) // 20
+
 	processFile("example.txt")
 
 	firstIncrement := counter() // returns a closure
@@ -773,6 +887,40 @@ func main() {
 	fmt.Println(secondIncrement())
 	fmt.Println(secondIncrement())
 
+	// the closures above aren't safe to share between goroutines, a counters.Counter is
+	var shared counters.Counter
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 100 {
+				shared.Inc()
+			}
+		}()
+	}
+	wg.Wait()
+	fmt.Println(shared.Get()) // always 1000
+
+	// both start over at 1 in every process; for IDs that stay unique across
+	// processes and restarts there's idgen
+	ids, err := idgen.NewSnowflake(idgen.SnowflakeConfig{Node: 1})
+	if err == nil {
+		first, _ := ids.Next()
+		second, _ := ids.Next()
+		fmt.Println(first < second, ids.Decompose(second).Node) // true 1
+	}
+
+	// the metrics package names and labels counters like these, and writes them in the
+	// Prometheus text format; registry.Handler() serves the same text over HTTP
+	registry := metrics.NewRegistry()
+	files, err := registry.Counter("processed_files_total", "Files processed.", "result")
+	if err == nil {
+		failed, _ := files.With("failure")
+		failed.Inc()
+		registry.WriteText(os.Stdout)
+	}
+
 	/*
 		A pointer in Go is a variable that stores the memory address of another variable.
 		Pointers allow functions and methods to modify the original value instead of working with a copy,
@@ -813,6 +961,35 @@ func main() {
 	fmt.Println(teddy.color)
 
 	fmt.Println(mathutil.GetDouble(89))
+
+	factors, err := mathutil.Factorize(600851475143)
+	if err != nil {
+		fmt.Println(err)
+	} else {
+		fmt.Println(factors) // [{71 1} {839 1} {1471 1} {6857 1}]
+	}
+
+	// "kaise ho" readers also count in lakh and crore
+	fmt.Println(numfmt.FormatInt(12345678, numfmt.Indian)) // 1,23,45,678
+	fmt.Println(numfmt.Words(12345678, numfmt.Hindi))      // एक करोड़ तेईस लाख पैंतालीस हज़ार छह सौ अठहत्तर
+
+	// the expr package parses a formula into an AST and evaluates it; plain Go functions
+	// with int parameters can be registered and then called by name from the formula
+	env := expr.NewEnv()
+	env.RegisterFunc("add", add)
+	env.RegisterFunc("mult", mult)
+	env.RegisterFunc("sums", sums)
+	env.RegisterFunc("square", mathutil.GetSquare)
+
+	result, err := expr.Evaluate("sums(1, 2, 3) * square(4) - 7", env)
+	if err != nil {
+		fmt.Println(err)
+	} else {
+		fmt.Println(result) // 89
+	}
+
+	_, err = expr.Evaluate("add(1, 2) / (3 - 3)", env)
+	fmt.Println(err) // column 11: division by zero
 }
 
 type teddy_t struct {
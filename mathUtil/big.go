@@ -0,0 +1,225 @@
+package mathutil
+
+import (
+	"errors"
+	"math/big"
+)
+
+/*
+
+Arbitrary precision versions of the mathutil operations, backed by math/big.
+
+	*big.Int   exact integers of any size
+	*big.Rat   exact fractions of any size
+	*big.Float floating point numbers with a configurable precision
+
+All the functions below follow the same rules:
+
+	- the operands are never modified, the result is always a newly allocated value
+	- a nil operand is reported with ErrNilOperand instead of panicking
+	- invalid inputs (negative exponents or factorials, zero modulus,
+	  division by zero) are reported with the errors declared below
+	- the *big.Float results use the precision of the (first) operand
+
+*/
+
+var (
+	ErrNilOperand       = errors.New("mathutil: nil operand")
+	ErrNegativeExponent = errors.New("mathutil: negative exponent")
+	ErrNegativeOperand  = errors.New("mathutil: negative operand")
+	ErrZeroModulus      = errors.New("mathutil: zero modulus")
+	ErrDivideByZero     = errors.New("mathutil: division by zero")
+)
+
+// BigSquare returns num * num
+func BigSquare(num *big.Int) (square *big.Int, err error) {
+	if traced() {
+		defer func() { emitCall("BigSquare", []any{num}, square, err) }()
+	}
+	if num == nil {
+		return nil, ErrNilOperand
+	}
+	return new(big.Int).Mul(num, num), nil
+}
+
+// BigDouble returns 2 * num
+func BigDouble(num *big.Int) (double *big.Int, err error) {
+	if traced() {
+		defer func() { emitCall("BigDouble", []any{num}, double, err) }()
+	}
+	if num == nil {
+		return nil, ErrNilOperand
+	}
+	return new(big.Int).Lsh(num, 1), nil
+}
+
+// BigPow returns base raised to exp
+func BigPow(base *big.Int, exp int64) (power *big.Int, err error) {
+	if traced() {
+		defer func() { emitCall("BigPow", []any{base, exp}, power, err) }()
+	}
+	if base == nil {
+		return nil, ErrNilOperand
+	}
+	if exp < 0 {
+		return nil, ErrNegativeExponent
+	}
+	return new(big.Int).Exp(base, big.NewInt(exp), nil), nil
+}
+
+// BigFactorial returns n! (1 * 2 * ... * n), with 0! being 1
+func BigFactorial(n int64) (factorial *big.Int, err error) {
+	if traced() {
+		defer func() { emitCall("BigFactorial", []any{n}, factorial, err) }()
+	}
+	if n < 0 {
+		return nil, ErrNegativeOperand
+	}
+	if n < 2 {
+		return big.NewInt(1), nil
+	}
+	// MulRange multiplies in a balanced tree, which is much faster than a
+	// plain loop for large n
+	return new(big.Int).MulRange(1, n), nil
+}
+
+// BigModPow returns base raised to exp modulo mod, the result is in [0, |mod|)
+// a negative exp is allowed when base has an inverse modulo mod
+func BigModPow(base, exp, mod *big.Int) (result *big.Int, err error) {
+	if traced() {
+		defer func() { emitCall("BigModPow", []any{base, exp, mod}, result, err) }()
+	}
+	if base == nil || exp == nil || mod == nil {
+		return nil, ErrNilOperand
+	}
+	if mod.Sign() == 0 {
+		return nil, ErrZeroModulus
+	}
+
+	modulus := new(big.Int).Abs(mod)
+	b := new(big.Int).Mod(base, modulus)
+	e := exp
+	if exp.Sign() < 0 {
+		// x^-e mod m is (x^-1)^e mod m
+		if b.ModInverse(b, modulus) == nil {
+			return nil, ErrDivideByZero
+		}
+		e = new(big.Int).Neg(exp)
+	}
+	return b.Exp(b, e, modulus), nil
+}
+
+// RatSquare returns num * num
+func RatSquare(num *big.Rat) (square *big.Rat, err error) {
+	if traced() {
+		defer func() { emitCall("RatSquare", []any{num}, square, err) }()
+	}
+	if num == nil {
+		return nil, ErrNilOperand
+	}
+	return new(big.Rat).Mul(num, num), nil
+}
+
+// RatDouble returns 2 * num
+func RatDouble(num *big.Rat) (double *big.Rat, err error) {
+	if traced() {
+		defer func() { emitCall("RatDouble", []any{num}, double, err) }()
+	}
+	if num == nil {
+		return nil, ErrNilOperand
+	}
+	return new(big.Rat).Add(num, num), nil
+}
+
+// RatPow returns base raised to exp
+// a negative exp inverts the base first, so it fails with ErrDivideByZero for a zero base
+func RatPow(base *big.Rat, exp int64) (power *big.Rat, err error) {
+	if traced() {
+		defer func() { emitCall("RatPow", []any{base, exp}, power, err) }()
+	}
+	if base == nil {
+		return nil, ErrNilOperand
+	}
+
+	// the magnitude of exp as a uint64, -exp would overflow for math.MinInt64
+	b := new(big.Rat).Set(base)
+	n := uint64(exp)
+	if exp < 0 {
+		if b.Sign() == 0 {
+			return nil, ErrDivideByZero
+		}
+		b.Inv(b)
+		n = -n
+	}
+
+	// numerator and denominator are coprime, so their powers are too and
+	// the result doesn't need any normalization
+	e := new(big.Int).SetUint64(n)
+	num := new(big.Int).Exp(b.Num(), e, nil)
+	denom := new(big.Int).Exp(b.Denom(), e, nil)
+	return new(big.Rat).SetFrac(num, denom), nil
+}
+
+// FloatSquare returns num * num, rounded to the precision of num
+func FloatSquare(num *big.Float) (square *big.Float, err error) {
+	if traced() {
+		defer func() { emitCall("FloatSquare", []any{num}, square, err) }()
+	}
+	if num == nil {
+		return nil, ErrNilOperand
+	}
+	return newFloatLike(num).Mul(num, num), nil
+}
+
+// FloatDouble returns 2 * num, rounded to the precision of num
+func FloatDouble(num *big.Float) (double *big.Float, err error) {
+	if traced() {
+		defer func() { emitCall("FloatDouble", []any{num}, double, err) }()
+	}
+	if num == nil {
+		return nil, ErrNilOperand
+	}
+	return newFloatLike(num).Add(num, num), nil
+}
+
+// FloatPow returns base raised to exp, rounded to the precision of base
+// a negative exp divides 1 by the power, so it fails with ErrDivideByZero for a zero base
+func FloatPow(base *big.Float, exp int64) (power *big.Float, err error) {
+	if traced() {
+		defer func() { emitCall("FloatPow", []any{base, exp}, power, err) }()
+	}
+	if base == nil {
+		return nil, ErrNilOperand
+	}
+
+	// the magnitude of exp as a uint64, -exp would overflow for math.MinInt64
+	n := uint64(exp)
+	if exp < 0 {
+		if base.Sign() == 0 {
+			return nil, ErrDivideByZero
+		}
+		n = -n
+	}
+
+	power = newFloatLike(base).SetInt64(1)
+	b := newFloatLike(base).Set(base)
+	for n > 0 {
+		if n&1 == 1 {
+			power.Mul(power, b)
+		}
+		n >>= 1
+		if n > 0 {
+			b.Mul(b, b)
+		}
+	}
+
+	if exp < 0 {
+		power.Quo(newFloatLike(base).SetInt64(1), power)
+	}
+	return power, nil
+}
+
+// newFloatLike returns a zero *big.Float with the precision and rounding mode of like
+func newFloatLike(like *big.Float) *big.Float {
+	return new(big.Float).SetPrec(like.Prec()).SetMode(like.Mode())
+}
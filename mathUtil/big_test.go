@@ -0,0 +1,75 @@
+package mathutil
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"testing"
+)
+
+func TestRatPow(t *testing.T) {
+	tests := []struct {
+		base string
+		exp  int64
+		want string
+		err  error
+	}{
+		{"2/3", 3, "8/27", nil},
+		{"2/3", -3, "27/8", nil},
+		{"-1/2", -3, "-8", nil},
+		{"5", 0, "1", nil},
+		{"0", 0, "1", nil},
+		{"0", -1, "", ErrDivideByZero},
+		// -exp overflows, the magnitude doesn't
+		{"1", math.MinInt64, "1", nil},
+		{"-1", math.MinInt64, "1", nil},
+	}
+	for _, test := range tests {
+		base, _ := new(big.Rat).SetString(test.base)
+		power, err := RatPow(base, test.exp)
+		if !errors.Is(err, test.err) || (err == nil && power.RatString() != test.want) {
+			t.Errorf("RatPow(%s, %d) = %v, %v, want %s, %v", test.base, test.exp, power, err, test.want, test.err)
+		}
+	}
+	if _, err := RatPow(nil, 1); !errors.Is(err, ErrNilOperand) {
+		t.Errorf("RatPow(nil) error %v, want ErrNilOperand", err)
+	}
+}
+
+func TestFloatPow(t *testing.T) {
+	tests := []struct {
+		base float64
+		exp  int64
+		want float64
+		err  error
+	}{
+		{2, 10, 1024, nil},
+		{2, -2, 0.25, nil},
+		{-0.5, 3, -0.125, nil},
+		{0, -1, 0, ErrDivideByZero},
+		{1, math.MinInt64, 1, nil},
+		{-1, math.MinInt64, 1, nil},
+	}
+	for _, test := range tests {
+		power, err := FloatPow(big.NewFloat(test.base), test.exp)
+		if !errors.Is(err, test.err) {
+			t.Errorf("FloatPow(%g, %d) error %v, want %v", test.base, test.exp, err, test.err)
+			continue
+		}
+		if err == nil {
+			if got, _ := power.Float64(); got != test.want {
+				t.Errorf("FloatPow(%g, %d) = %g, want %g", test.base, test.exp, got, test.want)
+			}
+		}
+	}
+}
+
+func TestBigPow(t *testing.T) {
+	power, err := BigPow(big.NewInt(3), 100)
+	if err != nil || power.String() != "515377520732011331036461129765621272702107522001" {
+		t.Errorf("BigPow(3, 100) = %v, %v", power, err)
+	}
+	if _, err := BigPow(big.NewInt(3), -1); !errors.Is(err, ErrNegativeExponent) {
+		t.Errorf("BigPow(3, -1) error %v, want ErrNegativeExponent", err)
+	}
+}
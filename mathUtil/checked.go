@@ -0,0 +1,243 @@
+package mathutil
+
+import "errors"
+
+/*
+
+Go integer arithmetic silently wraps around on overflow:
+
+	var x int8 = 127
+	x++ // x is now -128, no panic, no error
+
+The functions in this file are checked counterparts of the usual operators.
+They work for every integer width (through the Integer constraint) and
+report overflow instead of wrapping. Each operation comes in two flavours:
+
+	AddOk(a, b)      returns (result, ok); ok is false on overflow
+	AddChecked(a, b) returns (result, err); err is ErrOverflow on overflow
+
+On overflow the returned result is always the zero value of the type.
+
+*/
+
+// ErrOverflow is returned by the checked functions when the exact result
+// does not fit in the operand type
+var ErrOverflow = errors.New("mathutil: integer overflow")
+
+// isSigned reports whether T is a signed integer type
+// for a signed type, ^0 is -1, for an unsigned type it is the max value
+func isSigned[T Integer]() bool {
+	var zero T
+	return ^zero < 0
+}
+
+// isMinSigned reports whether num is the most negative value of a signed type,
+// the only non zero value that is its own negation
+func isMinSigned[T Integer](num T) bool {
+	return num != 0 && -num == num
+}
+
+// addOk returns a + b and whether the sum fits in T
+func addOk[T Integer](a, b T) (sum T, ok bool) {
+	sum = a + b
+	if isSigned[T]() {
+		if (b > 0 && sum < a) || (b < 0 && sum > a) {
+			return 0, false
+		}
+	} else if sum < a {
+		return 0, false
+	}
+	return sum, true
+}
+
+// subOk returns a - b and whether the difference fits in T
+func subOk[T Integer](a, b T) (difference T, ok bool) {
+	difference = a - b
+	if isSigned[T]() {
+		if (b > 0 && difference > a) || (b < 0 && difference < a) {
+			return 0, false
+		}
+	} else if b > a {
+		return 0, false
+	}
+	return difference, true
+}
+
+// mulOk returns a * b and whether the product fits in T
+func mulOk[T Integer](a, b T) (product T, ok bool) {
+	if a == 0 || b == 0 {
+		return 0, true
+	}
+
+	// MinInt * -1 wraps back to MinInt, and MinInt / -1 is MinInt too,
+	// so the division check below can't catch it
+	if isSigned[T]() {
+		var minusOne T = 0
+		minusOne--
+		if (a == minusOne && isMinSigned(b)) || (b == minusOne && isMinSigned(a)) {
+			return 0, false
+		}
+	}
+
+	product = a * b
+	if product/b != a {
+		return 0, false
+	}
+	return product, true
+}
+
+// negOk returns -a and whether the negation fits in T
+// for unsigned types only the negation of 0 fits
+func negOk[T Integer](a T) (negation T, ok bool) {
+	if isSigned[T]() {
+		if isMinSigned(a) {
+			return 0, false
+		}
+	} else if a != 0 {
+		return 0, false
+	}
+	return -a, true
+}
+
+// powOk returns base raised to exp and whether the power fits in T
+// it uses exponentiation by squaring, so it needs O(log exp) multiplications
+func powOk[T Integer](base T, exp uint) (power T, ok bool) {
+	power = 1
+	for exp > 0 {
+		if exp&1 == 1 {
+			if power, ok = mulOk(power, base); !ok {
+				return 0, false
+			}
+		}
+		exp >>= 1
+		if exp > 0 {
+			if base, ok = mulOk(base, base); !ok {
+				return 0, false
+			}
+		}
+	}
+	return power, true
+}
+
+// AddOk returns a + b and whether the sum fits in T
+func AddOk[T Integer](a, b T) (sum T, ok bool) {
+	sum, ok = addOk(a, b)
+	if traced() {
+		emitCall("AddOk", []any{a, b}, sum, overflowErr(ok))
+	}
+	return sum, ok
+}
+
+// AddChecked returns a + b, or ErrOverflow if the sum doesn't fit in T
+func AddChecked[T Integer](a, b T) (sum T, err error) {
+	sum, ok := addOk(a, b)
+	err = overflowErr(ok)
+	if traced() {
+		emitCall("AddChecked", []any{a, b}, sum, err)
+	}
+	return sum, err
+}
+
+// SubOk returns a - b and whether the difference fits in T
+func SubOk[T Integer](a, b T) (difference T, ok bool) {
+	difference, ok = subOk(a, b)
+	if traced() {
+		emitCall("SubOk", []any{a, b}, difference, overflowErr(ok))
+	}
+	return difference, ok
+}
+
+// SubChecked returns a - b, or ErrOverflow if the difference doesn't fit in T
+func SubChecked[T Integer](a, b T) (difference T, err error) {
+	difference, ok := subOk(a, b)
+	err = overflowErr(ok)
+	if traced() {
+		emitCall("SubChecked", []any{a, b}, difference, err)
+	}
+	return difference, err
+}
+
+// MulOk returns a * b and whether the product fits in T
+func MulOk[T Integer](a, b T) (product T, ok bool) {
+	product, ok = mulOk(a, b)
+	if traced() {
+		emitCall("MulOk", []any{a, b}, product, overflowErr(ok))
+	}
+	return product, ok
+}
+
+// MulChecked returns a * b, or ErrOverflow if the product doesn't fit in T
+func MulChecked[T Integer](a, b T) (product T, err error) {
+	product, ok := mulOk(a, b)
+	err = overflowErr(ok)
+	if traced() {
+		emitCall("MulChecked", []any{a, b}, product, err)
+	}
+	return product, err
+}
+
+// NegOk returns -a and whether the negation fits in T
+func NegOk[T Integer](a T) (negation T, ok bool) {
+	negation, ok = negOk(a)
+	if traced() {
+		emitCall("NegOk", []any{a}, negation, overflowErr(ok))
+	}
+	return negation, ok
+}
+
+// NegChecked returns -a, or ErrOverflow if the negation doesn't fit in T
+func NegChecked[T Integer](a T) (negation T, err error) {
+	negation, ok := negOk(a)
+	err = overflowErr(ok)
+	if traced() {
+		emitCall("NegChecked", []any{a}, negation, err)
+	}
+	return negation, err
+}
+
+// PowOk returns base raised to exp and whether the power fits in T
+func PowOk[T Integer](base T, exp uint) (power T, ok bool) {
+	power, ok = powOk(base, exp)
+	if traced() {
+		emitCall("PowOk", []any{base, exp}, power, overflowErr(ok))
+	}
+	return power, ok
+}
+
+// PowChecked returns base raised to exp, or ErrOverflow if the power doesn't fit in T
+func PowChecked[T Integer](base T, exp uint) (power T, err error) {
+	power, ok := powOk(base, exp)
+	err = overflowErr(ok)
+	if traced() {
+		emitCall("PowChecked", []any{base, exp}, power, err)
+	}
+	return power, err
+}
+
+// GetSquareChecked is the overflow checked counterpart of GetSquare
+func GetSquareChecked[T Integer](num T) (square T, err error) {
+	square, ok := mulOk(num, num)
+	err = overflowErr(ok)
+	if traced() {
+		emitCall("GetSquareChecked", []any{num}, square, err)
+	}
+	return square, err
+}
+
+// GetDoubleChecked is the overflow checked counterpart of GetDouble
+func GetDoubleChecked[T Integer](num T) (double T, err error) {
+	double, ok := addOk(num, num)
+	err = overflowErr(ok)
+	if traced() {
+		emitCall("GetDoubleChecked", []any{num}, double, err)
+	}
+	return double, err
+}
+
+// overflowErr maps the ok flag of the unchecked helpers to an error
+func overflowErr(ok bool) error {
+	if !ok {
+		return ErrOverflow
+	}
+	return nil
+}
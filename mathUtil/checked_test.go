@@ -0,0 +1,75 @@
+package mathutil
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// every pair of int8 and uint8 values, checked against the exact result in int
+func TestCheckedExhaustive(t *testing.T) {
+	for a := math.MinInt8; a <= math.MaxInt8; a++ {
+		for b := math.MinInt8; b <= math.MaxInt8; b++ {
+			checkInt8(t, "AddChecked", a+b, func() (int8, error) { return AddChecked(int8(a), int8(b)) })
+			checkInt8(t, "SubChecked", a-b, func() (int8, error) { return SubChecked(int8(a), int8(b)) })
+			checkInt8(t, "MulChecked", a*b, func() (int8, error) { return MulChecked(int8(a), int8(b)) })
+		}
+		checkInt8(t, "NegChecked", -a, func() (int8, error) { return NegChecked(int8(a)) })
+	}
+	for a := 0; a <= math.MaxUint8; a++ {
+		for b := 0; b <= math.MaxUint8; b++ {
+			checkUint8(t, "AddChecked", a+b, func() (uint8, error) { return AddChecked(uint8(a), uint8(b)) })
+			checkUint8(t, "SubChecked", a-b, func() (uint8, error) { return SubChecked(uint8(a), uint8(b)) })
+			checkUint8(t, "MulChecked", a*b, func() (uint8, error) { return MulChecked(uint8(a), uint8(b)) })
+		}
+	}
+}
+
+func checkInt8(t *testing.T, op string, exact int, checked func() (int8, error)) {
+	t.Helper()
+	got, err := checked()
+	if exact < math.MinInt8 || exact > math.MaxInt8 {
+		if !errors.Is(err, ErrOverflow) || got != 0 {
+			t.Fatalf("%s = %d, %v, want ErrOverflow for %d", op, got, err, exact)
+		}
+	} else if err != nil || int(got) != exact {
+		t.Fatalf("%s = %d, %v, want %d", op, got, err, exact)
+	}
+}
+
+func checkUint8(t *testing.T, op string, exact int, checked func() (uint8, error)) {
+	t.Helper()
+	got, err := checked()
+	if exact < 0 || exact > math.MaxUint8 {
+		if !errors.Is(err, ErrOverflow) || got != 0 {
+			t.Fatalf("%s = %d, %v, want ErrOverflow for %d", op, got, err, exact)
+		}
+	} else if err != nil || int(got) != exact {
+		t.Fatalf("%s = %d, %v, want %d", op, got, err, exact)
+	}
+}
+
+func TestPowChecked(t *testing.T) {
+	tests := []struct {
+		base int64
+		exp  uint
+		want int64
+		err  error
+	}{
+		{2, 0, 1, nil},
+		{0, 0, 1, nil},
+		{2, 62, 1 << 62, nil},
+		{2, 63, 0, ErrOverflow},
+		{-2, 63, math.MinInt64, nil},
+		{-2, 64, 0, ErrOverflow},
+		{3, 39, 4052555153018976267, nil},
+		{3, 40, 0, ErrOverflow},
+		{-1, math.MaxUint32, -1, nil},
+	}
+	for _, test := range tests {
+		got, err := PowChecked(test.base, test.exp)
+		if got != test.want || !errors.Is(err, test.err) {
+			t.Errorf("PowChecked(%d, %d) = %d, %v, want %d, %v", test.base, test.exp, got, err, test.want, test.err)
+		}
+	}
+}
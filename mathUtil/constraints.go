@@ -0,0 +1,41 @@
+package mathutil
+
+// type sets used as constraints by the generic functions of this package
+// the ~ prefix means that any type whose underlying type is listed also
+// satisfies the constraint, so `type userID int64` is an Integer too
+
+// Signed is satisfied by every signed integer type
+type Signed interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64
+}
+
+// Unsigned is satisfied by every unsigned integer type
+type Unsigned interface {
+	~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr
+}
+
+// Integer is satisfied by every integer type, signed or unsigned
+type Integer interface {
+	Signed | Unsigned
+}
+
+// Float is satisfied by every floating point type
+type Float interface {
+	~float32 | ~float64
+}
+
+// Complex is satisfied by every complex type
+type Complex interface {
+	~complex64 | ~complex128
+}
+
+// Real is satisfied by every numeric type that is ordered (supports < and >)
+type Real interface {
+	Integer | Float
+}
+
+// Number is satisfied by every numeric type, the complex ones included
+// it only guarantees +, -, * and /, so it can't be used for comparisons
+type Number interface {
+	Integer | Float | Complex
+}
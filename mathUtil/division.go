@@ -0,0 +1,99 @@
+package mathutil
+
+import "fmt"
+
+/*
+
+Go's / and % truncate toward zero, so test(-7, 2) in main gives -3 remainder -1.
+That's one of several reasonable conventions, and often not the one we want:
+for bucketing, -7 should land in the bucket of -8 (floor) with a remainder
+that is never negative (Euclidean).
+
+DivMod lets the caller pick the convention. For a / b, all of them satisfy
+
+	a == b*quotient + remainder   with   |remainder| < |b|
+
+and differ in how the quotient is rounded, which fixes the sign of the remainder:
+
+	mode          quotient rounded     remainder sign     -7/2      7/-2
+	DivTrunc      toward zero          same as a          -3 r -1   -3 r 1
+	DivFloor      toward -infinity     same as b          -4 r 1    -4 r -1
+	DivEuclid     so that r >= 0       never negative     -4 r 1    -3 r 1
+	DivCeil       toward +infinity     opposite of b      -3 r -1   -3 r 1
+
+The only quotient that doesn't fit its type is MinInt / -1, which is reported
+as ErrOverflow (Go itself silently returns MinInt for it).
+
+DivMod only accepts signed types: with unsigned ones every mode but DivCeil
+gives the same result as / and %, and DivCeil would need a negative remainder.
+
+*/
+
+// DivMode selects how DivMod rounds the quotient
+type DivMode int
+
+const (
+	DivTrunc DivMode = iota
+	DivFloor
+	DivEuclid
+	DivCeil
+)
+
+func (mode DivMode) String() string {
+	switch mode {
+	case DivTrunc:
+		return "truncated"
+	case DivFloor:
+		return "floored"
+	case DivEuclid:
+		return "euclidean"
+	case DivCeil:
+		return "ceiling"
+	}
+	return fmt.Sprintf("DivMode(%d)", int(mode))
+}
+
+// DivMod returns the quotient and remainder of a / b following mode
+// it fails with ErrDivideByZero if b is 0 and with ErrOverflow for MinInt / -1
+func DivMod[T Signed](a, b T, mode DivMode) (quotient, remainder T, err error) {
+	if traced() {
+		defer func() { emitCall("DivMod", []any{a, b, mode}, [2]T{quotient, remainder}, err) }()
+	}
+	if b == 0 {
+		return 0, 0, ErrDivideByZero
+	}
+	if b == -1 && isMinSigned(a) {
+		return 0, 0, ErrOverflow
+	}
+
+	// the adjustments below can't overflow: they only happen when the remainder
+	// is not zero, which means |b| >= 2 and the quotient is far from the limits
+	quotient, remainder = a/b, a%b
+	switch mode {
+	case DivTrunc:
+	case DivFloor:
+		if remainder != 0 && (remainder < 0) != (b < 0) {
+			quotient--
+			remainder += b
+		}
+	case DivEuclid:
+		if remainder < 0 {
+			if b > 0 {
+				quotient--
+				remainder += b
+			} else {
+				quotient++
+				remainder -= b
+			}
+		}
+	case DivCeil:
+		// the exact quotient is positive when a and b have the same sign
+		if remainder != 0 && (a < 0) == (b < 0) {
+			quotient++
+			remainder -= b
+		}
+	default:
+		return 0, 0, fmt.Errorf("mathutil: unknown division mode %v", mode)
+	}
+	return quotient, remainder, nil
+}
@@ -0,0 +1,86 @@
+package mathutil
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestDivMod(t *testing.T) {
+	tests := []struct {
+		a, b          int
+		mode          DivMode
+		quotient, rem int
+	}{
+		// the table of the package doc
+		{-7, 2, DivTrunc, -3, -1},
+		{-7, 2, DivFloor, -4, 1},
+		{-7, 2, DivEuclid, -4, 1},
+		{-7, 2, DivCeil, -3, -1},
+		{7, -2, DivTrunc, -3, 1},
+		{7, -2, DivFloor, -4, -1},
+		{7, -2, DivEuclid, -3, 1},
+		{7, -2, DivCeil, -3, 1},
+
+		{7, 2, DivCeil, 4, -1},
+		{-7, -2, DivEuclid, 4, 1},
+		{6, 3, DivFloor, 2, 0},
+		{-6, 3, DivCeil, -2, 0},
+		{0, -5, DivEuclid, 0, 0},
+		{math.MinInt, 1, DivFloor, math.MinInt, 0},
+		{math.MinInt, 2, DivEuclid, math.MinInt / 2, 0},
+		{math.MaxInt, -1, DivCeil, -math.MaxInt, 0},
+	}
+	for _, test := range tests {
+		quotient, rem, err := DivMod(test.a, test.b, test.mode)
+		if err != nil || quotient != test.quotient || rem != test.rem {
+			t.Errorf("DivMod(%d, %d, %v) = %d r %d, %v, want %d r %d",
+				test.a, test.b, test.mode, quotient, rem, err, test.quotient, test.rem)
+		}
+		if quotient*test.b+rem != test.a {
+			t.Errorf("DivMod(%d, %d, %v): %d*%d + %d != %d", test.a, test.b, test.mode, quotient, test.b, rem, test.a)
+		}
+	}
+}
+
+// a == q*b + r with |r| < |b| in every mode, on all the int8 pairs
+func TestDivModIdentity(t *testing.T) {
+	for _, mode := range []DivMode{DivTrunc, DivFloor, DivEuclid, DivCeil} {
+		for a := math.MinInt8; a <= math.MaxInt8; a++ {
+			for b := math.MinInt8; b <= math.MaxInt8; b++ {
+				quotient, rem, err := DivMod(int8(a), int8(b), mode)
+				if b == 0 || (a == math.MinInt8 && b == -1) {
+					if err == nil {
+						t.Fatalf("DivMod(%d, %d, %v) = %d r %d, want an error", a, b, mode, quotient, rem)
+					}
+					continue
+				}
+				if err != nil {
+					// the rounded quotient itself may not fit, 127 / -1 rounded up for instance
+					if !errors.Is(err, ErrOverflow) {
+						t.Fatalf("DivMod(%d, %d, %v): %v", a, b, mode, err)
+					}
+					continue
+				}
+				if int(quotient)*b+int(rem) != a || magnitude(rem) >= magnitude(int8(b)) {
+					t.Fatalf("DivMod(%d, %d, %v) = %d r %d", a, b, mode, quotient, rem)
+				}
+				if mode == DivEuclid && rem < 0 {
+					t.Fatalf("DivMod(%d, %d, DivEuclid) = %d r %d, negative remainder", a, b, quotient, rem)
+				}
+			}
+		}
+	}
+}
+
+func TestDivModErrors(t *testing.T) {
+	if _, _, err := DivMod(1, 0, DivFloor); !errors.Is(err, ErrDivideByZero) {
+		t.Errorf("DivMod(1, 0) error %v, want ErrDivideByZero", err)
+	}
+	if _, _, err := DivMod(int64(math.MinInt64), -1, DivTrunc); !errors.Is(err, ErrOverflow) {
+		t.Errorf("DivMod(MinInt64, -1) error %v, want ErrOverflow", err)
+	}
+	if got := DivMode(7).String(); got != "DivMode(7)" {
+		t.Errorf("DivMode(7).String() = %q", got)
+	}
+}
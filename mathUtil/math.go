@@ -1,21 +1,37 @@
 package mathutil
 
-import "fmt"
+// Square returns num * num for any numeric type
+// it wraps around on integer overflow, see GetSquareChecked for a checked version
+func Square[T Number](num T) (square T) {
+	square = num * num
+	if traced() {
+		emitCall("Square", []any{num}, square, nil)
+	}
+	return square
+}
 
-func init() {
-	fmt.Println("initialized mathUtil package")
+// Double returns 2 * num for any numeric type
+// it wraps around on integer overflow, see GetDoubleChecked for a checked version
+func Double[T Number](num T) (double T) {
+	double = num + num
+	if traced() {
+		emitCall("Double", []any{num}, double, nil)
+	}
+	return double
 }
 
 // exported function (starts with a capital letter)
+// kept for compatibility, it is Square instantiated with int
 func GetSquare(num int) (square int) {
-	return num * num
+	return Square(num)
 }
 
+// kept for compatibility, it is Double instantiated with int
 func GetDouble(num int) (double int) {
-	return 2 * num
+	return Double(num)
 }
 
 // this function is not exported (starts with a small letter)
 func getSquare(num int) (square int) {
-	return num * num
+	return Square(num)
 }
@@ -0,0 +1,58 @@
+package mathutil
+
+import (
+	"math"
+	"testing"
+)
+
+// userID checks that the ~ constraints accept defined types
+type userID int64
+
+func testSquareDouble[T Number](t *testing.T, num, square, double T) {
+	t.Helper()
+	if got := Square(num); got != square {
+		t.Errorf("Square[%T](%v) = %v, want %v", num, num, got, square)
+	}
+	if got := Double(num); got != double {
+		t.Errorf("Double[%T](%v) = %v, want %v", num, num, got, double)
+	}
+}
+
+func TestSquareDouble(t *testing.T) {
+	// signed
+	testSquareDouble(t, 0, 0, 0)
+	testSquareDouble(t, -7, 49, -14)
+	testSquareDouble[int8](t, -12, -112, -24) // 144 wraps around
+	testSquareDouble[int64](t, math.MinInt64, 0, 0)
+	testSquareDouble[userID](t, 3, 9, 6)
+	// unsigned
+	testSquareDouble[uint8](t, 16, 0, 32)
+	testSquareDouble[uint](t, 1<<32, 0, 1<<33)
+	testSquareDouble[uintptr](t, 5, 25, 10)
+	// float
+	testSquareDouble(t, 1.5, 2.25, 3)
+	testSquareDouble[float32](t, -0.5, 0.25, -1)
+	testSquareDouble(t, math.Inf(-1), math.Inf(1), math.Inf(-1))
+	// complex
+	testSquareDouble(t, 1i, -1, 2i)
+	testSquareDouble[complex64](t, 1+2i, -3+4i, 2+4i)
+}
+
+func TestGetSquareDouble(t *testing.T) {
+	tests := []struct {
+		num, square, double int
+	}{
+		{0, 0, 0},
+		{3, 9, 6},
+		{-4, 16, -8},
+		{math.MaxInt, 1, -2}, // wraps around
+	}
+	for _, test := range tests {
+		if got := GetSquare(test.num); got != test.square {
+			t.Errorf("GetSquare(%d) = %d, want %d", test.num, got, test.square)
+		}
+		if got := GetDouble(test.num); got != test.double {
+			t.Errorf("GetDouble(%d) = %d, want %d", test.num, got, test.double)
+		}
+	}
+}
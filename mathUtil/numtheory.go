@@ -0,0 +1,240 @@
+package mathutil
+
+import (
+	"math/big"
+	"math/bits"
+)
+
+/*
+
+Greatest common divisors and modular inverses.
+
+The integer versions work for every integer type and accept negative operands:
+divisibility doesn't care about signs, so the results are always non negative.
+A result that doesn't fit in the operand type (GCD(MinInt, 0) is -MinInt,
+LCM is easily larger than both operands) is reported with ErrOverflow and,
+like in checked.go, a zero result.
+
+The *big.Int versions follow the rules of big.go and never overflow.
+
+*/
+
+// magnitude returns |num| as an uint64, which holds the magnitude of any integer type,
+// including the MinInt of the signed ones
+func magnitude[T Integer](num T) uint64 {
+	if num < 0 {
+		// for MinInt64 the negation wraps to MinInt64, whose bits are exactly 2^63
+		return uint64(-int64(num))
+	}
+	return uint64(num)
+}
+
+// fromMagnitude converts a non negative value back to T, reporting whether it fits
+func fromMagnitude[T Integer](value uint64) (T, bool) {
+	converted := T(value)
+	return converted, converted >= 0 && uint64(converted) == value
+}
+
+// gcd64 is Euclid's algorithm on magnitudes
+func gcd64(a, b uint64) uint64 {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+// GCD returns the greatest common divisor of a and b, always non negative
+// GCD(0, 0) is 0
+func GCD[T Integer](a, b T) (gcd T, err error) {
+	if traced() {
+		defer func() { emitCall("GCD", []any{a, b}, gcd, err) }()
+	}
+	gcd, ok := fromMagnitude[T](gcd64(magnitude(a), magnitude(b)))
+	if !ok {
+		return 0, ErrOverflow
+	}
+	return gcd, nil
+}
+
+// LCM returns the least common multiple of a and b, always non negative
+// it is 0 when a or b is 0
+func LCM[T Integer](a, b T) (lcm T, err error) {
+	if traced() {
+		defer func() { emitCall("LCM", []any{a, b}, lcm, err) }()
+	}
+	if a == 0 || b == 0 {
+		return 0, nil
+	}
+	x, y := magnitude(a), magnitude(b)
+	hi, product := bits.Mul64(x/gcd64(x, y), y)
+	if hi != 0 {
+		return 0, ErrOverflow
+	}
+	lcm, ok := fromMagnitude[T](product)
+	if !ok {
+		return 0, ErrOverflow
+	}
+	return lcm, nil
+}
+
+// ExtGCD returns the greatest common divisor of a and b together with the
+// Bézout coefficients x and y such that a*x + b*y == gcd
+// the coefficients are the minimal ones produced by the extended Euclidean algorithm
+func ExtGCD[T Signed](a, b T) (gcd, x, y T, err error) {
+	if traced() {
+		defer func() { emitCall("ExtGCD", []any{a, b}, [3]T{gcd, x, y}, err) }()
+	}
+
+	// the coefficients are bounded by |a| and |b|, so even when q*s wraps around
+	// the difference below is exact: Go's signed arithmetic is modular
+	oldR, r := a, b
+	oldS, s := T(1), T(0)
+	oldT, t := T(0), T(1)
+	for r != 0 {
+		if r == -1 && isMinSigned(oldR) {
+			// MinInt / -1 overflows, but a remainder of -1 means the gcd is 1
+			// and the current row already is a Bézout identity for -1
+			oldR, oldS, oldT = r, s, t
+			break
+		}
+		q := oldR / r
+		oldR, r = r, oldR-q*r
+		oldS, s = s, oldS-q*s
+		oldT, t = t, oldT-q*t
+	}
+
+	if oldR < 0 {
+		var okR, okS, okT bool
+		oldR, okR = negOk(oldR)
+		oldS, okS = negOk(oldS)
+		oldT, okT = negOk(oldT)
+		if !okR || !okS || !okT {
+			return 0, 0, 0, ErrOverflow
+		}
+	}
+	return oldR, oldS, oldT, nil
+}
+
+// ModInverse returns the x in [0, |mod|) such that a*x ≡ 1 (mod |mod|)
+// it fails with ErrZeroModulus for a zero mod and with ErrDivideByZero when
+// a and mod are not coprime, since then there is no inverse
+func ModInverse[T Integer](a, mod T) (inverse T, err error) {
+	if traced() {
+		defer func() { emitCall("ModInverse", []any{a, mod}, inverse, err) }()
+	}
+	if mod == 0 {
+		return 0, ErrZeroModulus
+	}
+
+	m := magnitude(mod)
+	value := magnitude(a) % m
+	if a < 0 && value != 0 {
+		value = m - value
+	}
+
+	// extended Euclid on the unsigned values, keeping the coefficient of value
+	// reduced modulo m so that it never goes negative
+	oldR, r := m, value
+	oldS, s := uint64(0), uint64(1)
+	for r != 0 {
+		q := oldR / r
+		oldR, r = r, oldR-q*r
+		oldS, s = s, subMod(oldS, mulMod(q%m, s, m), m)
+	}
+	if oldR != 1 {
+		return 0, ErrDivideByZero
+	}
+	// m == 1 only has the residue 0, whose inverse is 0 as well
+	inverse, _ = fromMagnitude[T](oldS % m)
+	return inverse, nil
+}
+
+// mulMod returns a * b mod m without overflowing
+func mulMod(a, b, m uint64) uint64 {
+	hi, lo := bits.Mul64(a, b)
+	return bits.Rem64(hi, lo, m)
+}
+
+// addMod returns a + b mod m, for a and b already reduced modulo m
+func addMod(a, b, m uint64) uint64 {
+	sum, carry := bits.Add64(a, b, 0)
+	if carry != 0 || sum >= m {
+		sum -= m
+	}
+	return sum
+}
+
+// subMod returns a - b mod m, for a and b already reduced modulo m
+func subMod(a, b, m uint64) uint64 {
+	if a >= b {
+		return a - b
+	}
+	return m - (b - a)
+}
+
+// BigGCD returns the greatest common divisor of a and b, always non negative
+func BigGCD(a, b *big.Int) (gcd *big.Int, err error) {
+	if traced() {
+		defer func() { emitCall("BigGCD", []any{a, b}, gcd, err) }()
+	}
+	if a == nil || b == nil {
+		return nil, ErrNilOperand
+	}
+	return new(big.Int).GCD(nil, nil, a, b), nil
+}
+
+// BigLCM returns the least common multiple of a and b, always non negative
+// it is 0 when a or b is 0
+func BigLCM(a, b *big.Int) (lcm *big.Int, err error) {
+	if traced() {
+		defer func() { emitCall("BigLCM", []any{a, b}, lcm, err) }()
+	}
+	if a == nil || b == nil {
+		return nil, ErrNilOperand
+	}
+	if a.Sign() == 0 || b.Sign() == 0 {
+		return new(big.Int), nil
+	}
+	gcd := new(big.Int).GCD(nil, nil, a, b)
+	lcm = new(big.Int).Quo(a, gcd)
+	lcm.Mul(lcm, b)
+	return lcm.Abs(lcm), nil
+}
+
+// BigExtGCD returns the greatest common divisor of a and b together with the
+// Bézout coefficients x and y such that a*x + b*y == gcd
+func BigExtGCD(a, b *big.Int) (gcd, x, y *big.Int, err error) {
+	if traced() {
+		defer func() { emitCall("BigExtGCD", []any{a, b}, [3]*big.Int{gcd, x, y}, err) }()
+	}
+	if a == nil || b == nil {
+		return nil, nil, nil, ErrNilOperand
+	}
+	x, y = new(big.Int), new(big.Int)
+	gcd = new(big.Int).GCD(x, y, a, b)
+	return gcd, x, y, nil
+}
+
+// BigModInverse returns the x in [0, |mod|) such that a*x ≡ 1 (mod |mod|)
+// it fails with ErrZeroModulus for a zero mod and with ErrDivideByZero when
+// a and mod are not coprime
+func BigModInverse(a, mod *big.Int) (inverse *big.Int, err error) {
+	if traced() {
+		defer func() { emitCall("BigModInverse", []any{a, mod}, inverse, err) }()
+	}
+	if a == nil || mod == nil {
+		return nil, ErrNilOperand
+	}
+	if mod.Sign() == 0 {
+		return nil, ErrZeroModulus
+	}
+	modulus := new(big.Int).Abs(mod)
+	if modulus.Cmp(big.NewInt(1)) == 0 {
+		return new(big.Int), nil
+	}
+	inverse = new(big.Int).Mod(a, modulus)
+	if inverse.ModInverse(inverse, modulus) == nil {
+		return nil, ErrDivideByZero
+	}
+	return inverse, nil
+}
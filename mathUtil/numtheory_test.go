@@ -0,0 +1,178 @@
+package mathutil
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"slices"
+	"testing"
+)
+
+func TestGCDLCM(t *testing.T) {
+	tests := []struct {
+		a, b     int64
+		gcd, lcm int64
+		lcmErr   error
+	}{
+		{0, 0, 0, 0, nil},
+		{0, 5, 5, 0, nil},
+		{12, 18, 6, 36, nil},
+		{-12, 18, 6, 36, nil},
+		{-12, -18, 6, 36, nil},
+		{17, 5, 1, 85, nil},
+		{math.MaxInt64, math.MaxInt64 - 1, 1, 0, ErrOverflow},
+		{math.MinInt64, 2, 2, 0, ErrOverflow},
+	}
+	for _, test := range tests {
+		if gcd, err := GCD(test.a, test.b); gcd != test.gcd || err != nil {
+			t.Errorf("GCD(%d, %d) = %d, %v, want %d", test.a, test.b, gcd, err, test.gcd)
+		}
+		if lcm, err := LCM(test.a, test.b); lcm != test.lcm || !errors.Is(err, test.lcmErr) {
+			t.Errorf("LCM(%d, %d) = %d, %v, want %d, %v", test.a, test.b, lcm, err, test.lcm, test.lcmErr)
+		}
+	}
+	// 2^63 is the gcd, and doesn't fit in an int64
+	if gcd, err := GCD(int64(math.MinInt64), 0); !errors.Is(err, ErrOverflow) {
+		t.Errorf("GCD(MinInt64, 0) = %d, %v, want ErrOverflow", gcd, err)
+	}
+	if gcd, err := GCD(uint64(1<<63), 0); gcd != 1<<63 || err != nil {
+		t.Errorf("GCD(uint64(1<<63), 0) = %d, %v", gcd, err)
+	}
+}
+
+func TestExtGCD(t *testing.T) {
+	tests := []struct{ a, b int64 }{
+		{240, 46},
+		{-240, 46},
+		{0, 7},
+		{7, 0},
+		{1, 1},
+		{math.MaxInt64, math.MaxInt64 - 1},
+		{math.MinInt64, 3},
+		{math.MinInt64, -1},
+	}
+	for _, test := range tests {
+		gcd, x, y, err := ExtGCD(test.a, test.b)
+		want, _ := GCD(test.a, test.b)
+		if err != nil || gcd != want {
+			t.Errorf("ExtGCD(%d, %d) = %d, %v, want gcd %d", test.a, test.b, gcd, err, want)
+			continue
+		}
+		// checked in big.Int, the products can overflow even when the sum doesn't
+		sum := new(big.Int).Mul(big.NewInt(test.a), big.NewInt(x))
+		sum.Add(sum, new(big.Int).Mul(big.NewInt(test.b), big.NewInt(y)))
+		if sum.Cmp(big.NewInt(gcd)) != 0 {
+			t.Errorf("ExtGCD(%d, %d) = %d, %d, %d: a*x + b*y = %v", test.a, test.b, gcd, x, y, sum)
+		}
+	}
+	if _, _, _, err := ExtGCD(int64(math.MinInt64), 0); !errors.Is(err, ErrOverflow) {
+		t.Errorf("ExtGCD(MinInt64, 0) error %v, want ErrOverflow", err)
+	}
+}
+
+func TestModInverse(t *testing.T) {
+	tests := []struct {
+		a, mod  int64
+		inverse int64
+		err     error
+	}{
+		{3, 11, 4, nil},
+		{-3, 11, 7, nil},
+		{3, -11, 4, nil},
+		{10, 17, 12, nil},
+		{5, 1, 0, nil},
+		{6, 9, 0, ErrDivideByZero},
+		{0, 7, 0, ErrDivideByZero},
+		{3, 0, 0, ErrZeroModulus},
+		{2, math.MaxInt64, 1 << 62, nil},
+	}
+	for _, test := range tests {
+		inverse, err := ModInverse(test.a, test.mod)
+		if inverse != test.inverse || !errors.Is(err, test.err) {
+			t.Errorf("ModInverse(%d, %d) = %d, %v, want %d, %v", test.a, test.mod, inverse, err, test.inverse, test.err)
+		}
+	}
+}
+
+func TestIsPrime(t *testing.T) {
+	tests := []struct {
+		num  int64
+		want bool
+	}{
+		{-7, false},
+		{0, false},
+		{1, false},
+		{2, true},
+		{91, false},
+		{97, true},
+		{561, false}, // Carmichael number, fools the Fermat test
+		{1_000_000_007, true},
+		{3_215_031_751, false}, // strong pseudoprime to bases 2, 3, 5 and 7
+		{math.MaxInt64, false},
+		{9_223_372_036_854_775_783, true}, // the largest prime below 2^63
+	}
+	for _, test := range tests {
+		if got := IsPrime(test.num); got != test.want {
+			t.Errorf("IsPrime(%d) = %v, want %v", test.num, got, test.want)
+		}
+	}
+	if !IsPrime(uint64(18_446_744_073_709_551_557)) {
+		t.Error("IsPrime(2^64 - 59) = false")
+	}
+}
+
+func TestPrimes(t *testing.T) {
+	tests := []struct {
+		lo, hi int
+		want   []int
+	}{
+		{0, 30, []int{2, 3, 5, 7, 11, 13, 17, 19, 23, 29}},
+		{-10, 2, []int{2}},
+		{90, 96, nil},
+		{30, 0, nil},
+		{1_000_000, 1_000_100, []int{1_000_003, 1_000_033, 1_000_037, 1_000_039, 1_000_081, 1_000_099}},
+	}
+	for _, test := range tests {
+		if got := slices.Collect(Primes(test.lo, test.hi)); !slices.Equal(got, test.want) {
+			t.Errorf("Primes(%d, %d) = %v, want %v", test.lo, test.hi, got, test.want)
+		}
+	}
+
+	// across several segments of the sieve, against IsPrime
+	var want []int
+	for num := range 3 * sieveSegment {
+		if IsPrime(num) {
+			want = append(want, num)
+		}
+	}
+	if got := slices.Collect(Primes(0, 3*sieveSegment)); !slices.Equal(got, want) {
+		t.Errorf("Primes(0, %d) yielded %d primes, IsPrime finds %d", 3*sieveSegment, len(got), len(want))
+	}
+}
+
+func TestFactorize(t *testing.T) {
+	tests := []struct {
+		num  int64
+		want []Factor[int64]
+	}{
+		{1, nil},
+		{2, []Factor[int64]{{2, 1}}},
+		{360, []Factor[int64]{{2, 3}, {3, 2}, {5, 1}}},
+		{600_851_475_143, []Factor[int64]{{71, 1}, {839, 1}, {1471, 1}, {6857, 1}}},
+		{1 << 62, []Factor[int64]{{2, 62}}},
+		// two large primes, out of reach of trial division
+		{1_000_000_007 * 1_000_000_009, []Factor[int64]{{1_000_000_007, 1}, {1_000_000_009, 1}}},
+	}
+	for _, test := range tests {
+		got, err := Factorize(test.num)
+		if err != nil || !slices.Equal(got, test.want) {
+			t.Errorf("Factorize(%d) = %v, %v, want %v", test.num, got, err, test.want)
+		}
+	}
+	if _, err := Factorize(0); !errors.Is(err, ErrZeroOperand) {
+		t.Errorf("Factorize(0) error %v, want ErrZeroOperand", err)
+	}
+	if _, err := Factorize(-4); !errors.Is(err, ErrNegativeOperand) {
+		t.Errorf("Factorize(-4) error %v, want ErrNegativeOperand", err)
+	}
+}
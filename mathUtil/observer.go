@@ -0,0 +1,151 @@
+package mathutil
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+)
+
+/*
+
+The package is silent by default, it never writes to stdout or stderr by itself.
+
+Callers that want to know what the package is doing install an Observer:
+
+	mathutil.SetLogger(slog.Default()) // log through slog
+	mathutil.SetObserver(myObserver)   // or use a custom observer
+	mathutil.SetTracing(true)          // also report every operation call
+
+Lifecycle events (an observer being attached or detached, tracing being turned
+on or off) are always reported to the installed observer. Operation calls are
+reported only while tracing is on, since that happens on every single call.
+
+When tracing is off the cost of the hook is a single atomic load per call.
+
+*/
+
+// lifecycle events reported through Observer.OnLifecycle
+const (
+	EventAttached        = "observer attached"
+	EventDetached        = "observer detached"
+	EventTracingEnabled  = "tracing enabled"
+	EventTracingDisabled = "tracing disabled"
+)
+
+// Call describes a single traced operation
+type Call struct {
+	Op     string // name of the exported function, e.g. "MulChecked"
+	Args   []any  // arguments in the order they were passed
+	Result any    // the main return value
+	Err    error  // non nil if the operation failed (ErrOverflow for the Ok functions)
+}
+
+// Observer receives the events of the package
+// implementations must be safe for concurrent use, since the package functions can be
+// called from many goroutines
+type Observer interface {
+	OnLifecycle(event string)
+	OnCall(call Call)
+}
+
+// observerBox lets us store an interface value in an atomic.Pointer
+type observerBox struct {
+	observer Observer
+}
+
+var (
+	currentObserver atomic.Pointer[observerBox]
+	tracing         atomic.Bool
+)
+
+// SetObserver installs observer as the receiver of the package events and returns the
+// previously installed one (nil if there was none)
+// passing nil makes the package silent again
+func SetObserver(observer Observer) (previous Observer) {
+	var box *observerBox
+	if observer != nil {
+		box = &observerBox{observer: observer}
+	}
+
+	if old := currentObserver.Swap(box); old != nil {
+		previous = old.observer
+		previous.OnLifecycle(EventDetached)
+	}
+	if observer != nil {
+		observer.OnLifecycle(EventAttached)
+	}
+	return previous
+}
+
+// SetLogger installs an observer that writes the package events to logger
+// passing nil makes the package silent again
+func SetLogger(logger *slog.Logger) {
+	if logger == nil {
+		SetObserver(nil)
+		return
+	}
+	SetObserver(NewSlogObserver(logger))
+}
+
+// SetTracing turns the reporting of every operation call on or off
+func SetTracing(enabled bool) {
+	if tracing.Swap(enabled) == enabled {
+		return
+	}
+	if enabled {
+		emitLifecycle(EventTracingEnabled)
+	} else {
+		emitLifecycle(EventTracingDisabled)
+	}
+}
+
+// traced reports whether the operation calls have to be reported
+// callers check it before building the Call, so that nothing is allocated
+// when tracing is off
+func traced() bool {
+	return tracing.Load() && currentObserver.Load() != nil
+}
+
+func emitLifecycle(event string) {
+	if box := currentObserver.Load(); box != nil {
+		box.observer.OnLifecycle(event)
+	}
+}
+
+func emitCall(op string, args []any, result any, err error) {
+	if box := currentObserver.Load(); box != nil {
+		box.observer.OnCall(Call{Op: op, Args: args, Result: result, Err: err})
+	}
+}
+
+// SlogObserver is an Observer writing the package events to a slog.Logger
+// lifecycle events are logged at info level, operation calls at debug level
+type SlogObserver struct {
+	logger *slog.Logger
+}
+
+// NewSlogObserver returns an observer logging to logger
+func NewSlogObserver(logger *slog.Logger) *SlogObserver {
+	return &SlogObserver{logger: logger.With(slog.String("package", "mathutil"))}
+}
+
+func (observer *SlogObserver) OnLifecycle(event string) {
+	observer.logger.Info(event)
+}
+
+func (observer *SlogObserver) OnCall(call Call) {
+	// skip building the attributes if debug records are dropped anyway
+	if !observer.logger.Enabled(context.Background(), slog.LevelDebug) {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.String("op", call.Op),
+		slog.Any("args", call.Args),
+		slog.Any("result", call.Result),
+	}
+	if call.Err != nil {
+		attrs = append(attrs, slog.String("error", call.Err.Error()))
+	}
+	observer.logger.LogAttrs(context.Background(), slog.LevelDebug, "call", attrs...)
+}
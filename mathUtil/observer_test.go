@@ -0,0 +1,162 @@
+package mathutil
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log/slog"
+	"math/big"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// recorder is an Observer keeping every event it receives
+type recorder struct {
+	mu     sync.Mutex
+	events []string
+	calls  []Call
+}
+
+func (r *recorder) OnLifecycle(event string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+func (r *recorder) OnCall(call Call) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, call)
+}
+
+// observe installs a recorder for the duration of the test
+func observe(t *testing.T) *recorder {
+	t.Helper()
+	r := &recorder{}
+	SetObserver(r)
+	t.Cleanup(func() {
+		SetTracing(false)
+		SetObserver(nil)
+	})
+	return r
+}
+
+// someCalls runs a few traced operations, failing ones included
+func someCalls() {
+	Square(3)
+	AddChecked(1, 2)
+	MulChecked(int8(100), 2)
+	BigPow(big.NewInt(2), 10)
+	BigPow(big.NewInt(2), -1)
+	DivMod(7, -2, DivFloor)
+	DivMod(7, 0, DivFloor)
+}
+
+func TestSilentByDefault(t *testing.T) {
+	stdout, stderr := os.Stdout, os.Stderr
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stdout, os.Stderr = writer, writer
+	defer func() { os.Stdout, os.Stderr = stdout, stderr }()
+
+	// no observer, with and without tracing
+	someCalls()
+	SetTracing(true)
+	someCalls()
+	SetTracing(false)
+
+	writer.Close()
+	if output, _ := io.ReadAll(reader); len(output) != 0 {
+		t.Errorf("the package wrote %q", output)
+	}
+}
+
+func TestTracingDisabled(t *testing.T) {
+	r := observe(t)
+	someCalls()
+	if len(r.calls) != 0 {
+		t.Errorf("%d calls reported with tracing off: %v", len(r.calls), r.calls)
+	}
+}
+
+func TestLifecycle(t *testing.T) {
+	r := observe(t)
+	SetTracing(true)
+	SetTracing(true) // no change, no event
+	SetTracing(false)
+	second := &recorder{}
+	if previous := SetObserver(second); previous != r {
+		t.Errorf("SetObserver returned %v, want the first observer", previous)
+	}
+
+	want := []string{EventAttached, EventTracingEnabled, EventTracingDisabled, EventDetached}
+	if !reflect.DeepEqual(r.events, want) {
+		t.Errorf("events %q, want %q", r.events, want)
+	}
+	if !reflect.DeepEqual(second.events, []string{EventAttached}) {
+		t.Errorf("events of the second observer %q, want %q", second.events, EventAttached)
+	}
+}
+
+func TestTracedCalls(t *testing.T) {
+	r := observe(t)
+	SetTracing(true)
+	someCalls()
+
+	// the results and errors of the functions reporting through a defer are
+	// the values they return, not the ones at the time of the defer
+	want := []Call{
+		{Op: "Square", Args: []any{3}, Result: 9},
+		{Op: "AddChecked", Args: []any{1, 2}, Result: 3},
+		{Op: "MulChecked", Args: []any{int8(100), int8(2)}, Result: int8(0), Err: ErrOverflow},
+		{Op: "BigPow", Args: []any{big.NewInt(2), int64(10)}, Result: big.NewInt(1024)},
+		{Op: "BigPow", Args: []any{big.NewInt(2), int64(-1)}, Result: (*big.Int)(nil), Err: ErrNegativeExponent},
+		{Op: "DivMod", Args: []any{7, -2, DivFloor}, Result: [2]int{-4, -1}},
+		{Op: "DivMod", Args: []any{7, 0, DivFloor}, Result: [2]int{0, 0}, Err: ErrDivideByZero},
+	}
+	if len(r.calls) != len(want) {
+		t.Fatalf("%d calls reported, want %d: %v", len(r.calls), len(want), r.calls)
+	}
+	for index, call := range r.calls {
+		if call.Op != want[index].Op || !reflect.DeepEqual(call.Args, want[index].Args) ||
+			!reflect.DeepEqual(call.Result, want[index].Result) || !errors.Is(call.Err, want[index].Err) ||
+			(call.Err == nil) != (want[index].Err == nil) {
+			t.Errorf("call %d = %+v, want %+v", index, call, want[index])
+		}
+	}
+}
+
+func TestSlogObserver(t *testing.T) {
+	tests := []struct {
+		level      slog.Level
+		want, skip []string
+	}{
+		{slog.LevelDebug, []string{"observer attached", "tracing enabled", "op=MulChecked", "error=", "package=mathutil"}, nil},
+		{slog.LevelInfo, []string{"observer attached", "tracing enabled"}, []string{"op="}},
+	}
+	for _, test := range tests {
+		var buffer bytes.Buffer
+		SetLogger(slog.New(slog.NewTextHandler(&buffer, &slog.HandlerOptions{Level: test.level})))
+		SetTracing(true)
+		MulChecked(int8(100), 2)
+		SetTracing(false)
+		SetLogger(nil)
+
+		output := buffer.String()
+		for _, text := range test.want {
+			if !strings.Contains(output, text) {
+				t.Errorf("level %v: %q doesn't contain %q", test.level, output, text)
+			}
+		}
+		for _, text := range test.skip {
+			if strings.Contains(output, text) {
+				t.Errorf("level %v: %q contains %q", test.level, output, text)
+			}
+		}
+	}
+}
@@ -0,0 +1,390 @@
+package mathutil
+
+import (
+	"errors"
+	"iter"
+	"math"
+	"math/big"
+	"math/bits"
+	"slices"
+)
+
+/*
+
+Primes: testing, enumerating and factorizing.
+
+	IsPrime    deterministic Miller-Rabin, exact for every 64-bit value
+	Primes     segmented sieve of Eratosthenes over a range
+	Factorize  trial division for the small factors, then Pollard's rho
+
+IsPrime uses the first 12 primes as witnesses, which is known to be exact
+for every n < 3.3 * 10^24, so there are no false positives for 64-bit values.
+
+Primes only keeps the primes up to sqrt(hi) and one segment of the range in
+memory, so enumerating a short range around 10^12 costs about as much as
+sieving up to 10^6. Close to MaxInt64 the sieving primes alone take more
+than a gigabyte; use IsPrime on each value there instead. It has no *big.Int
+variant: a sieve only makes sense on ranges that can be enumerated.
+
+*/
+
+// ErrZeroOperand is returned by the factorizations, since every number divides zero
+var ErrZeroOperand = errors.New("mathutil: zero operand")
+
+// Factor is a prime factor and its multiplicity
+type Factor[T Integer] struct {
+	Prime T
+	Exp   int
+}
+
+// BigFactor is a prime factor of a *big.Int and its multiplicity
+type BigFactor struct {
+	Prime *big.Int
+	Exp   int
+}
+
+// millerRabinWitnesses make Miller-Rabin deterministic for every 64-bit value
+var millerRabinWitnesses = [...]uint64{2, 3, 5, 7, 11, 13, 17, 19, 23, 29, 31, 37}
+
+// sieveSegment is the number of values Primes sieves at a time
+const sieveSegment = 1 << 15
+
+// IsPrime reports whether num is a prime number, negative numbers never are
+func IsPrime[T Integer](num T) (prime bool) {
+	if traced() {
+		defer func() { emitCall("IsPrime", []any{num}, prime, nil) }()
+	}
+	return num >= 0 && isPrime64(uint64(num))
+}
+
+func isPrime64(n uint64) bool {
+	if n < 2 {
+		return false
+	}
+	for _, p := range millerRabinWitnesses {
+		if n%p == 0 {
+			return n == p
+		}
+	}
+
+	// n - 1 == d * 2^s with d odd
+	s := bits.TrailingZeros64(n - 1)
+	d := (n - 1) >> s
+	for _, witness := range millerRabinWitnesses {
+		x := powMod(witness, d, n)
+		if x == 1 || x == n-1 {
+			continue
+		}
+		for round := 1; round < s && x != n-1; round++ {
+			x = mulMod(x, x, n)
+		}
+		if x != n-1 {
+			return false
+		}
+	}
+	return true
+}
+
+// powMod returns base^exp mod m
+func powMod(base, exp, m uint64) uint64 {
+	result := uint64(1) % m
+	base %= m
+	for exp > 0 {
+		if exp&1 == 1 {
+			result = mulMod(result, base, m)
+		}
+		base = mulMod(base, base, m)
+		exp >>= 1
+	}
+	return result
+}
+
+// isqrt returns the largest r such that r*r <= n, for n >= 0
+func isqrt(n int) int {
+	r := int(math.Sqrt(float64(n)))
+	// the float square root can be off by one for large n
+	for r > 0 && r > n/r {
+		r--
+	}
+	for r+1 <= n/(r+1) {
+		r++
+	}
+	return r
+}
+
+// Primes yields the primes in [lo, hi] in increasing order
+func Primes(lo, hi int) iter.Seq[int] {
+	if traced() {
+		emitCall("Primes", []any{lo, hi}, nil, nil)
+	}
+	return primes(lo, hi)
+}
+
+func primes(lo, hi int) iter.Seq[int] {
+	return func(yield func(int) bool) {
+		if hi < max(lo, 2) {
+			return
+		}
+
+		// the sieving primes come from the same sieve, on a much smaller range:
+		// the recursion ends when sqrt(hi) drops below 2
+		base := slices.Collect(primes(2, isqrt(hi)))
+		composite := make([]bool, sieveSegment)
+		for start := max(lo, 2); ; {
+			end := start + sieveSegment - 1
+			if end > hi || end < start {
+				end = hi
+			}
+
+			clear(composite)
+			for _, p := range base {
+				if p > end/p {
+					break
+				}
+				// the first multiple of p in the segment, smaller multiples
+				// than p*p have already been crossed by smaller primes
+				first := max(p*p, start)
+				if rem := first % p; rem != 0 {
+					first += p - rem
+				}
+				// first can wrap around near MaxInt, end-p guards the increment too
+				for multiple := first; multiple >= start && multiple <= end; multiple += p {
+					composite[multiple-start] = true
+					if multiple > end-p {
+						break
+					}
+				}
+			}
+
+			for offset := range end - start + 1 {
+				if !composite[offset] && !yield(start+offset) {
+					return
+				}
+			}
+			if end == hi {
+				return
+			}
+			start = end + 1
+		}
+	}
+}
+
+// Factorize returns the prime factorization of num in increasing order of the primes
+// 1 has no prime factors, zero and negative numbers are rejected
+func Factorize[T Integer](num T) (factors []Factor[T], err error) {
+	if traced() {
+		defer func() { emitCall("Factorize", []any{num}, factors, err) }()
+	}
+	if num < 0 {
+		return nil, ErrNegativeOperand
+	}
+	if num == 0 {
+		return nil, ErrZeroOperand
+	}
+	for _, factor := range factorize64(uint64(num)) {
+		factors = append(factors, Factor[T]{Prime: T(factor.Prime), Exp: factor.Exp})
+	}
+	return factors, nil
+}
+
+// factorize64 factorizes n >= 1
+func factorize64(n uint64) []Factor[uint64] {
+	var primes []uint64
+	for _, p := range millerRabinWitnesses {
+		for n%p == 0 {
+			primes = append(primes, p)
+			n /= p
+		}
+	}
+	primes = appendPrimeFactors(primes, n)
+	slices.Sort(primes)
+
+	var factors []Factor[uint64]
+	for _, p := range primes {
+		if last := len(factors) - 1; last >= 0 && factors[last].Prime == p {
+			factors[last].Exp++
+		} else {
+			factors = append(factors, Factor[uint64]{Prime: p, Exp: 1})
+		}
+	}
+	return factors
+}
+
+// appendPrimeFactors appends the prime factors of n, which has no factor below 41
+func appendPrimeFactors(primes []uint64, n uint64) []uint64 {
+	if n == 1 {
+		return primes
+	}
+	if isPrime64(n) {
+		return append(primes, n)
+	}
+	d := pollardRho(n)
+	primes = appendPrimeFactors(primes, d)
+	return appendPrimeFactors(primes, n/d)
+}
+
+// pollardRho returns a non trivial divisor of the odd composite n,
+// using Brent's cycle detection and batching the gcds
+func pollardRho(n uint64) uint64 {
+	const batch = 128
+	for c := uint64(1); ; c++ {
+		next := func(x uint64) uint64 { return addMod(mulMod(x, x, n), c, n) }
+
+		y, ys, x := uint64(2), uint64(0), uint64(0)
+		g, q := uint64(1), uint64(1)
+		for r := 1; g == 1; r *= 2 {
+			x = y
+			for range r {
+				y = next(y)
+			}
+			for k := 0; k < r && g == 1; k += batch {
+				ys = y
+				for range min(batch, r-k) {
+					y = next(y)
+					q = mulMod(q, absDiff(x, y), n)
+				}
+				g = gcd64(q, n)
+			}
+		}
+
+		if g == n {
+			// the batch overshot, walk it again one step at a time
+			for g = 1; g == 1; {
+				ys = next(ys)
+				g = gcd64(absDiff(x, ys), n)
+			}
+		}
+		if g != n {
+			return g
+		}
+		// the cycle closed without finding a factor, try another polynomial
+	}
+}
+
+func absDiff(a, b uint64) uint64 {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
+
+// BigIsPrime reports whether num is a prime number, negative numbers never are
+// it is exact below 2^64 and has an error probability below 4^-20 above
+func BigIsPrime(num *big.Int) (prime bool, err error) {
+	if traced() {
+		defer func() { emitCall("BigIsPrime", []any{num}, prime, err) }()
+	}
+	if num == nil {
+		return false, ErrNilOperand
+	}
+	return num.Sign() > 0 && num.ProbablyPrime(20), nil
+}
+
+// BigFactorize returns the prime factorization of num in increasing order of the primes
+// values that fit in 64 bits are factorized exactly, larger ones rely on BigIsPrime
+// and can take a very long time when num has two or more large prime factors
+func BigFactorize(num *big.Int) (factors []BigFactor, err error) {
+	if traced() {
+		defer func() { emitCall("BigFactorize", []any{num}, factors, err) }()
+	}
+	if num == nil {
+		return nil, ErrNilOperand
+	}
+	if num.Sign() < 0 {
+		return nil, ErrNegativeOperand
+	}
+	if num.Sign() == 0 {
+		return nil, ErrZeroOperand
+	}
+
+	var primes []*big.Int
+	n := new(big.Int).Set(num)
+	quotient, remainder := new(big.Int), new(big.Int)
+	for _, p := range millerRabinWitnesses {
+		prime := new(big.Int).SetUint64(p)
+		for {
+			quotient.QuoRem(n, prime, remainder)
+			if remainder.Sign() != 0 {
+				break
+			}
+			primes = append(primes, prime)
+			n.Set(quotient)
+		}
+	}
+	primes = appendBigPrimeFactors(primes, n)
+	slices.SortFunc(primes, (*big.Int).Cmp)
+
+	for _, p := range primes {
+		if last := len(factors) - 1; last >= 0 && factors[last].Prime.Cmp(p) == 0 {
+			factors[last].Exp++
+		} else {
+			factors = append(factors, BigFactor{Prime: p, Exp: 1})
+		}
+	}
+	return factors, nil
+}
+
+// appendBigPrimeFactors appends the prime factors of n, which has no factor below 41
+func appendBigPrimeFactors(primes []*big.Int, n *big.Int) []*big.Int {
+	if n.IsUint64() {
+		if n.Uint64() == 1 {
+			return primes
+		}
+		for _, factor := range factorize64(n.Uint64()) {
+			for range factor.Exp {
+				primes = append(primes, new(big.Int).SetUint64(factor.Prime))
+			}
+		}
+		return primes
+	}
+	if n.ProbablyPrime(20) {
+		return append(primes, n)
+	}
+	d := bigPollardRho(n)
+	primes = appendBigPrimeFactors(primes, d)
+	return appendBigPrimeFactors(primes, new(big.Int).Quo(n, d))
+}
+
+// bigPollardRho is pollardRho for an odd composite n larger than 64 bits
+func bigPollardRho(n *big.Int) *big.Int {
+	const batch = 128
+	one := big.NewInt(1)
+	diff, g := new(big.Int), new(big.Int)
+	for c := int64(1); ; c++ {
+		constant := big.NewInt(c)
+		next := func(x *big.Int) {
+			x.Mul(x, x)
+			x.Add(x, constant)
+			x.Mod(x, n)
+		}
+
+		y, ys, x := big.NewInt(2), new(big.Int), new(big.Int)
+		q := big.NewInt(1)
+		g.SetInt64(1)
+		for r := 1; g.Cmp(one) == 0; r *= 2 {
+			x.Set(y)
+			for range r {
+				next(y)
+			}
+			for k := 0; k < r && g.Cmp(one) == 0; k += batch {
+				ys.Set(y)
+				for range min(batch, r-k) {
+					next(y)
+					q.Mul(q, diff.Sub(x, y).Abs(diff))
+					q.Mod(q, n)
+				}
+				g.GCD(nil, nil, q, n)
+			}
+		}
+
+		if g.Cmp(n) == 0 {
+			for g.SetInt64(1); g.Cmp(one) == 0; {
+				next(ys)
+				g.GCD(nil, nil, diff.Sub(x, ys).Abs(diff), n)
+			}
+		}
+		if g.Cmp(n) != 0 {
+			return new(big.Int).Set(g)
+		}
+	}
+}
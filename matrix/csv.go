@@ -0,0 +1,222 @@
+package matrix
+
+import (
+	"encoding/csv"
+	"errors"
+	mathutil "first/mathUtil"
+	"fmt"
+	"io"
+	"iter"
+	"strconv"
+	"strings"
+)
+
+/*
+
+Two CSV layouts are supported:
+
+	dense: one matrix row per record, every record with the same number of fields
+
+		1,0,0
+		0,2,0
+
+	coordinate: a mandatory "row,col,value" header followed by one record per
+	non-zero element, with 0-based indices. The shape isn't part of the file,
+	the caller has to provide it
+
+		row,col,value
+		0,0,1
+		1,1,2
+
+Lines starting with # are comments and are skipped. The readers are strict:
+ragged rows, malformed numbers, out of range indices and wrong headers are all
+errors wrapping ErrFormat, with the line they were found on.
+
+*/
+
+var ErrFormat = errors.New("matrix: invalid file format")
+
+// csvCoordinateHeader is the header line of the coordinate CSV layout
+var csvCoordinateHeader = []string{"row", "col", "value"}
+
+func newCSVReader(r io.Reader) *csv.Reader {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.ReuseRecord = true
+	reader.Comment = '#'
+	return reader
+}
+
+// csvError wraps an error of encoding/csv or of a field of the current record with ErrFormat
+func csvError(reader *csv.Reader, field int, err error) error {
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return fmt.Errorf("%w: line %d: %v", ErrFormat, parseErr.Line, parseErr.Err)
+	}
+	line, column := reader.FieldPos(field)
+	return fmt.Errorf("%w: line %d, column %d: %v", ErrFormat, line, column, err)
+}
+
+// CSVRows streams the rows of a dense CSV matrix one at a time, without holding
+// the whole matrix in memory
+// the yielded slice is only valid until the next iteration, copy it to keep it
+// iteration stops after the first error
+func CSVRows[T mathutil.Number](r io.Reader) iter.Seq2[[]T, error] {
+	return func(yield func([]T, error) bool) {
+		reader := newCSVReader(r)
+		var row []T
+		for {
+			record, err := reader.Read()
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				yield(nil, csvError(reader, 0, err))
+				return
+			}
+
+			row = row[:0]
+			for field, text := range record {
+				value, err := parseValue[T](text)
+				if err != nil {
+					yield(nil, csvError(reader, field, err))
+					return
+				}
+				row = append(row, value)
+			}
+			if !yield(row, nil) {
+				return
+			}
+		}
+	}
+}
+
+// ReadCSV reads a dense CSV matrix
+func ReadCSV[T mathutil.Number](r io.Reader) (*Matrix[T], error) {
+	m := &Matrix[T]{}
+	for row, err := range CSVRows[T](r) {
+		if err != nil {
+			return nil, err
+		}
+		// encoding/csv already rejects records with a different number of fields
+		m.cols = len(row)
+		m.data = append(m.data, row...)
+		m.rows++
+	}
+	return m, nil
+}
+
+// WriteCSV writes m as a dense CSV matrix
+func WriteCSV[T mathutil.Number](w io.Writer, m *Matrix[T]) error {
+	writer := csv.NewWriter(w)
+	record := make([]string, m.cols)
+	for row := 0; row < m.rows; row++ {
+		for col := range record {
+			record[col] = formatValue(m.data[row*m.cols+col])
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// CSVEntries streams the entries of a coordinate CSV matrix of the given shape
+// iteration stops after the first error
+func CSVEntries[T mathutil.Number](r io.Reader, rows, cols int) iter.Seq2[Entry[T], error] {
+	return func(yield func(Entry[T], error) bool) {
+		reader := newCSVReader(r)
+		reader.FieldsPerRecord = len(csvCoordinateHeader)
+
+		header, err := reader.Read()
+		if err == io.EOF {
+			yield(Entry[T]{}, fmt.Errorf("%w: missing header %q", ErrFormat, strings.Join(csvCoordinateHeader, ",")))
+			return
+		}
+		if err != nil {
+			yield(Entry[T]{}, csvError(reader, 0, err))
+			return
+		}
+		for field, name := range header {
+			if strings.TrimSpace(name) != csvCoordinateHeader[field] {
+				yield(Entry[T]{}, csvError(reader, field, fmt.Errorf("header must be %q", strings.Join(csvCoordinateHeader, ","))))
+				return
+			}
+		}
+
+		for {
+			record, err := reader.Read()
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				yield(Entry[T]{}, csvError(reader, 0, err))
+				return
+			}
+
+			var entry Entry[T]
+			if entry.Row, err = parseIndex(record[0], rows); err != nil {
+				yield(entry, csvError(reader, 0, err))
+				return
+			}
+			if entry.Col, err = parseIndex(record[1], cols); err != nil {
+				yield(entry, csvError(reader, 1, err))
+				return
+			}
+			if entry.Value, err = parseValue[T](record[2]); err != nil {
+				yield(entry, csvError(reader, 2, err))
+				return
+			}
+			if !yield(entry, nil) {
+				return
+			}
+		}
+	}
+}
+
+// parseIndex parses a 0-based index that must be less than size
+func parseIndex(text string, size int) (int, error) {
+	index, err := strconv.Atoi(strings.TrimSpace(text))
+	if err != nil {
+		return 0, fmt.Errorf("invalid index %q", text)
+	}
+	if index < 0 || index >= size {
+		return 0, fmt.Errorf("index %d out of range [0, %d)", index, size)
+	}
+	return index, nil
+}
+
+// ReadCSVCOO reads a coordinate CSV matrix of the given shape
+func ReadCSVCOO[T mathutil.Number](r io.Reader, rows, cols int) (*COO[T], error) {
+	coo, err := NewCOO[T](rows, cols)
+	if err != nil {
+		return nil, err
+	}
+	for entry, err := range CSVEntries[T](r, rows, cols) {
+		if err != nil {
+			return nil, err
+		}
+		coo.entries = append(coo.entries, entry)
+	}
+	return coo, nil
+}
+
+// WriteCSVCOO writes the entries of coo as a coordinate CSV matrix
+func WriteCSVCOO[T mathutil.Number](w io.Writer, coo *COO[T]) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvCoordinateHeader); err != nil {
+		return err
+	}
+	record := make([]string, 3)
+	for entry := range coo.NonZeros() {
+		record[0] = strconv.Itoa(entry.Row)
+		record[1] = strconv.Itoa(entry.Col)
+		record[2] = formatValue(entry.Value)
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,67 @@
+package matrix
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCSV(t *testing.T) {
+	m := mustMatrix(t, [][]float64{{1, 0.5, -2}, {0, 1e-10, 3}})
+	var buffer bytes.Buffer
+	if err := WriteCSV(&buffer, m); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := ReadCSV[float64](&buffer); err != nil || !got.Equal(m) {
+		t.Errorf("ReadCSV(WriteCSV(m)) = %v, %v, want %v", got, err, m)
+	}
+
+	commented := "# a comment\n1, 2\n3, 4\n"
+	if got, err := ReadCSV[int](strings.NewReader(commented)); err != nil || !got.Equal(mustMatrix(t, [][]int{{1, 2}, {3, 4}})) {
+		t.Errorf("ReadCSV(%q) = %v, %v", commented, got, err)
+	}
+
+	coo, _ := NewCOO[int](2, 3)
+	coo.Append(0, 2, 7)
+	coo.Append(1, 0, -1)
+	buffer.Reset()
+	if err := WriteCSVCOO(&buffer, coo); err != nil {
+		t.Fatal(err)
+	}
+	if want := "row,col,value\n0,2,7\n1,0,-1\n"; buffer.String() != want {
+		t.Errorf("WriteCSVCOO = %q, want %q", buffer.String(), want)
+	}
+	if got, err := ReadCSVCOO[int](&buffer, 2, 3); err != nil || !got.ToDense().Equal(coo.ToDense()) {
+		t.Errorf("ReadCSVCOO(WriteCSVCOO(coo)) = %v, %v", got, err)
+	}
+}
+
+func TestCSVErrors(t *testing.T) {
+	dense := []struct {
+		text, line string
+	}{
+		{"1,2\n3\n", "line 2"},
+		{"1,2\n3,x\n", "line 2, column 3"},
+		{"1.5\n", "line 1"}, // not an int
+	}
+	for _, test := range dense {
+		_, err := ReadCSV[int](strings.NewReader(test.text))
+		if !errors.Is(err, ErrFormat) || !strings.Contains(err.Error(), test.line) {
+			t.Errorf("ReadCSV(%q) error %v, want ErrFormat at %s", test.text, err, test.line)
+		}
+	}
+
+	coordinate := []string{
+		"",
+		"r,c,v\n",
+		"row,col,value\n2,0,1\n",
+		"row,col,value\n0,-1,1\n",
+		"row,col,value\n0,0\n",
+	}
+	for _, text := range coordinate {
+		if _, err := ReadCSVCOO[int](strings.NewReader(text), 2, 2); !errors.Is(err, ErrFormat) {
+			t.Errorf("ReadCSVCOO(%q) error %v, want ErrFormat", text, err)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package matrix
+
+import (
+	mathutil "first/mathUtil"
+	"fmt"
+)
+
+// the functions in this file need division and comparisons, so unlike the
+// methods of Matrix they are only available for float matrices
+
+func abs[T mathutil.Float](value T) T {
+	if value < 0 {
+		return -value
+	}
+	return value
+}
+
+// Det returns the determinant of the square matrix m
+// it reduces a copy of m to upper triangular form with Gaussian elimination
+// and partial pivoting, the determinant is then the product of the diagonal
+func Det[T mathutil.Float](m *Matrix[T]) (T, error) {
+	if !m.IsSquare() {
+		return 0, fmt.Errorf("%w: determinant of a %dx%d matrix", ErrNotSquare, m.rows, m.cols)
+	}
+
+	n := m.rows
+	a := m.Clone().data
+	det := T(1)
+	for col := 0; col < n; col++ {
+		// pick the largest pivot in the column to keep rounding errors small
+		pivot := col
+		for row := col + 1; row < n; row++ {
+			if abs(a[row*n+col]) > abs(a[pivot*n+col]) {
+				pivot = row
+			}
+		}
+		if a[pivot*n+col] == 0 {
+			return 0, nil
+		}
+		if pivot != col {
+			swapRows(a, n, pivot, col)
+			det = -det
+		}
+
+		det *= a[col*n+col]
+		for row := col + 1; row < n; row++ {
+			factor := a[row*n+col] / a[col*n+col]
+			for k := col; k < n; k++ {
+				a[row*n+k] -= factor * a[col*n+k]
+			}
+		}
+	}
+	return det, nil
+}
+
+// Inverse returns the inverse of the square matrix m, or ErrSingular if it has none
+// it runs Gauss-Jordan elimination with partial pivoting on m and the identity side by side
+func Inverse[T mathutil.Float](m *Matrix[T]) (*Matrix[T], error) {
+	if !m.IsSquare() {
+		return nil, fmt.Errorf("%w: inverse of a %dx%d matrix", ErrNotSquare, m.rows, m.cols)
+	}
+
+	n := m.rows
+	a := m.Clone().data
+	inverse, _ := Identity[T](n)
+	inv := inverse.data
+
+	for col := 0; col < n; col++ {
+		pivot := col
+		for row := col + 1; row < n; row++ {
+			if abs(a[row*n+col]) > abs(a[pivot*n+col]) {
+				pivot = row
+			}
+		}
+		if a[pivot*n+col] == 0 {
+			return nil, ErrSingular
+		}
+		swapRows(a, n, pivot, col)
+		swapRows(inv, n, pivot, col)
+
+		// scale the pivot row so that the pivot becomes 1
+		scale := 1 / a[col*n+col]
+		for k := 0; k < n; k++ {
+			a[col*n+k] *= scale
+			inv[col*n+k] *= scale
+		}
+
+		// and clear the column in every other row
+		for row := 0; row < n; row++ {
+			if row == col || a[row*n+col] == 0 {
+				continue
+			}
+			factor := a[row*n+col]
+			for k := 0; k < n; k++ {
+				a[row*n+k] -= factor * a[col*n+k]
+				inv[row*n+k] -= factor * inv[col*n+k]
+			}
+		}
+	}
+	return inverse, nil
+}
+
+// swapRows swaps rows i and j of the row-major n-column data
+func swapRows[T mathutil.Number](data []T, n, i, j int) {
+	if i == j {
+		return
+	}
+	for k := 0; k < n; k++ {
+		data[i*n+k], data[j*n+k] = data[j*n+k], data[i*n+k]
+	}
+}
@@ -0,0 +1,184 @@
+package matrix
+
+import (
+	"errors"
+	mathutil "first/mathUtil"
+	"fmt"
+	"strings"
+)
+
+/*
+
+Matrix is a dense rows x cols matrix, generic over any numeric element type.
+
+The elements are stored in a single slice in row-major order, so the element
+at (row, col) lives at data[row*cols+col]. Compared to the [][]int returned
+by createMatrix this needs a single allocation and keeps the rows next to each
+other in memory, which matters a lot for the speed of multiplication.
+
+The operations never modify their operands, they always return a new matrix.
+Only Set modifies the matrix it's called on.
+
+*/
+
+var (
+	ErrShape       = errors.New("matrix: mismatched shapes")
+	ErrOutOfBounds = errors.New("matrix: index out of bounds")
+	ErrNotSquare   = errors.New("matrix: not a square matrix")
+	ErrSingular    = errors.New("matrix: singular matrix")
+)
+
+// maxElements bounds the number of elements of a dense matrix: 2^40 complex128
+// are 16 TiB, more than can be allocated, so a larger size is a mistake or a
+// corrupted input, and reporting it beats the panic of make
+const maxElements int64 = 1 << 40
+
+type Matrix[T mathutil.Number] struct {
+	rows, cols int
+	data       []T
+}
+
+// Zeros returns a rows x cols matrix filled with zeros
+func Zeros[T mathutil.Number](rows, cols int) (*Matrix[T], error) {
+	if rows < 0 || cols < 0 {
+		return nil, fmt.Errorf("%w: negative size %dx%d", ErrShape, rows, cols)
+	}
+	size, err := mathutil.MulChecked(rows, cols)
+	if err != nil || int64(size) > maxElements {
+		return nil, fmt.Errorf("%w: size %dx%d is too large", ErrShape, rows, cols)
+	}
+	return &Matrix[T]{rows: rows, cols: cols, data: make([]T, size)}, nil
+}
+
+// Identity returns the n x n identity matrix
+func Identity[T mathutil.Number](n int) (*Matrix[T], error) {
+	m, err := Zeros[T](n, n)
+	if err != nil {
+		return nil, err
+	}
+	for index := 0; index < n; index++ {
+		m.data[index*n+index] = 1
+	}
+	return m, nil
+}
+
+// FromFunc returns a rows x cols matrix whose element at (row, col) is fill(row, col)
+// createMatrix from main is FromFunc(rows, cols, func(row, col int) int { return row * col })
+func FromFunc[T mathutil.Number](rows, cols int, fill func(row, col int) T) (*Matrix[T], error) {
+	m, err := Zeros[T](rows, cols)
+	if err != nil {
+		return nil, err
+	}
+	for row := 0; row < rows; row++ {
+		for col := 0; col < cols; col++ {
+			m.data[row*cols+col] = fill(row, col)
+		}
+	}
+	return m, nil
+}
+
+// FromSlices returns a matrix holding a copy of values, which must not be ragged
+func FromSlices[T mathutil.Number](values [][]T) (*Matrix[T], error) {
+	rows := len(values)
+	cols := 0
+	if rows > 0 {
+		cols = len(values[0])
+	}
+
+	m, err := Zeros[T](rows, cols)
+	if err != nil {
+		return nil, err
+	}
+	for row, rowValues := range values {
+		if len(rowValues) != cols {
+			return nil, fmt.Errorf("%w: row %d has %d elements, row 0 has %d", ErrShape, row, len(rowValues), cols)
+		}
+		copy(m.data[row*cols:], rowValues)
+	}
+	return m, nil
+}
+
+// Rows returns the number of rows of m
+func (m *Matrix[T]) Rows() int {
+	return m.rows
+}
+
+// Cols returns the number of columns of m
+func (m *Matrix[T]) Cols() int {
+	return m.cols
+}
+
+// IsSquare reports whether m has as many rows as columns
+func (m *Matrix[T]) IsSquare() bool {
+	return m.rows == m.cols
+}
+
+func (m *Matrix[T]) checkBounds(row, col int) error {
+	if row < 0 || row >= m.rows || col < 0 || col >= m.cols {
+		return fmt.Errorf("%w: (%d, %d) in a %dx%d matrix", ErrOutOfBounds, row, col, m.rows, m.cols)
+	}
+	return nil
+}
+
+// At returns the element at (row, col)
+func (m *Matrix[T]) At(row, col int) (T, error) {
+	if err := m.checkBounds(row, col); err != nil {
+		var zero T
+		return zero, err
+	}
+	return m.data[row*m.cols+col], nil
+}
+
+// Set sets the element at (row, col) to value
+func (m *Matrix[T]) Set(row, col int, value T) error {
+	if err := m.checkBounds(row, col); err != nil {
+		return err
+	}
+	m.data[row*m.cols+col] = value
+	return nil
+}
+
+// Clone returns a copy of m
+func (m *Matrix[T]) Clone() *Matrix[T] {
+	data := make([]T, len(m.data))
+	copy(data, m.data)
+	return &Matrix[T]{rows: m.rows, cols: m.cols, data: data}
+}
+
+// ToSlices returns the elements of m as a [][]T, independent from m
+func (m *Matrix[T]) ToSlices() [][]T {
+	values := make([][]T, m.rows)
+	for row := range values {
+		values[row] = make([]T, m.cols)
+		copy(values[row], m.data[row*m.cols:(row+1)*m.cols])
+	}
+	return values
+}
+
+// Equal reports whether m and other have the same shape and elements
+func (m *Matrix[T]) Equal(other *Matrix[T]) bool {
+	if m.rows != other.rows || m.cols != other.cols {
+		return false
+	}
+	for index, value := range m.data {
+		if other.data[index] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// String returns m one row per line, with the columns separated by spaces
+func (m *Matrix[T]) String() string {
+	var builder strings.Builder
+	for row := 0; row < m.rows; row++ {
+		for col := 0; col < m.cols; col++ {
+			if col > 0 {
+				builder.WriteByte(' ')
+			}
+			fmt.Fprint(&builder, m.data[row*m.cols+col])
+		}
+		builder.WriteByte('\n')
+	}
+	return builder.String()
+}
@@ -0,0 +1,158 @@
+package matrix
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func mustMatrix[T int | float64](t *testing.T, values [][]T) *Matrix[T] {
+	t.Helper()
+	m, err := FromSlices(values)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestConstructors(t *testing.T) {
+	identity, _ := Identity[int](3)
+	if !identity.Equal(mustMatrix(t, [][]int{{1, 0, 0}, {0, 1, 0}, {0, 0, 1}})) {
+		t.Errorf("Identity(3) = %v", identity)
+	}
+	table, _ := FromFunc(2, 3, func(row, col int) int { return row * col })
+	if !table.Equal(mustMatrix(t, [][]int{{0, 0, 0}, {0, 1, 2}})) {
+		t.Errorf("FromFunc = %v", table)
+	}
+
+	sizes := []struct {
+		rows, cols int
+	}{
+		{-1, 2},
+		{2, -1},
+		{3037000500, 3037000500}, // the product overflows
+		{1 << 21, 1 << 20},       // too large to allocate
+		{math.MaxInt, 2},
+	}
+	for _, size := range sizes {
+		if _, err := Zeros[int](size.rows, size.cols); !errors.Is(err, ErrShape) {
+			t.Errorf("Zeros(%d, %d) error %v, want ErrShape", size.rows, size.cols, err)
+		}
+	}
+	if _, err := FromSlices([][]int{{1, 2}, {3}}); !errors.Is(err, ErrShape) {
+		t.Errorf("FromSlices(ragged) error %v, want ErrShape", err)
+	}
+
+	// FromSlices and ToSlices copy, the matrix owns its data
+	values := [][]int{{1, 2}}
+	m := mustMatrix(t, values)
+	values[0][0] = 9
+	m.ToSlices()[0][1] = 9
+	if got, _ := m.At(0, 0); got != 1 {
+		t.Error("FromSlices kept a reference to its input")
+	}
+	if got, _ := m.At(0, 1); got != 2 {
+		t.Error("ToSlices returned a reference to the data")
+	}
+}
+
+func TestAccess(t *testing.T) {
+	m := mustMatrix(t, [][]int{{1, 2}, {3, 4}})
+	for _, index := range [][2]int{{-1, 0}, {0, -1}, {2, 0}, {0, 2}} {
+		if _, err := m.At(index[0], index[1]); !errors.Is(err, ErrOutOfBounds) {
+			t.Errorf("At%v error %v, want ErrOutOfBounds", index, err)
+		}
+		if err := m.Set(index[0], index[1], 0); !errors.Is(err, ErrOutOfBounds) {
+			t.Errorf("Set%v error %v, want ErrOutOfBounds", index, err)
+		}
+	}
+	clone := m.Clone()
+	clone.Set(1, 1, 40)
+	if got, _ := m.At(1, 1); got != 4 {
+		t.Error("Set on a clone changed the original")
+	}
+}
+
+func TestOps(t *testing.T) {
+	a := mustMatrix(t, [][]int{{1, 2, 3}, {4, 5, 6}})
+	b := mustMatrix(t, [][]int{{7, 8}, {9, 10}, {11, 12}})
+	tests := []struct {
+		name string
+		got  func() (*Matrix[int], error)
+		want *Matrix[int]
+	}{
+		{"Add", func() (*Matrix[int], error) { return a.Add(a) }, mustMatrix(t, [][]int{{2, 4, 6}, {8, 10, 12}})},
+		{"Sub", func() (*Matrix[int], error) { return a.Sub(a.Scale(2)) }, mustMatrix(t, [][]int{{-1, -2, -3}, {-4, -5, -6}})},
+		{"Mul", func() (*Matrix[int], error) { return a.Mul(b) }, mustMatrix(t, [][]int{{58, 64}, {139, 154}})},
+		{"Transpose", func() (*Matrix[int], error) { return a.Transpose(), nil }, mustMatrix(t, [][]int{{1, 4}, {2, 5}, {3, 6}})},
+	}
+	for _, test := range tests {
+		if got, err := test.got(); err != nil || !got.Equal(test.want) {
+			t.Errorf("%s = %v, %v, want %v", test.name, got, err, test.want)
+		}
+	}
+
+	if _, err := a.Add(b); !errors.Is(err, ErrShape) {
+		t.Errorf("Add of 2x3 and 3x2 error %v, want ErrShape", err)
+	}
+	if _, err := a.Mul(a); !errors.Is(err, ErrShape) {
+		t.Errorf("Mul of 2x3 by 2x3 error %v, want ErrShape", err)
+	}
+	column, _ := Zeros[int8](1<<21, 1)
+	row, _ := Zeros[int8](1, 1<<20)
+	if _, err := column.Mul(row); !errors.Is(err, ErrShape) {
+		t.Errorf("Mul with a 2^41 elements product error %v, want ErrShape", err)
+	}
+	if _, err := a.Trace(); !errors.Is(err, ErrNotSquare) {
+		t.Errorf("Trace of 2x3 error %v, want ErrNotSquare", err)
+	}
+	if trace, _ := mustMatrix(t, [][]int{{1, 2}, {3, 4}}).Trace(); trace != 5 {
+		t.Errorf("Trace = %d, want 5", trace)
+	}
+}
+
+func TestDetInverse(t *testing.T) {
+	tests := []struct {
+		values [][]float64
+		det    float64
+	}{
+		{[][]float64{{4, 7}, {2, 6}}, 10},
+		{[][]float64{{0, 1}, {1, 0}}, -1}, // needs a row swap
+		{[][]float64{{2, 0, 0}, {0, 3, 0}, {0, 0, 4}}, 24},
+		{[][]float64{{1, 2}, {2, 4}}, 0},
+	}
+	for _, test := range tests {
+		m := mustMatrix(t, test.values)
+		if det, err := Det(m); err != nil || math.Abs(det-test.det) > 1e-12 {
+			t.Errorf("Det(%v) = %v, %v, want %v", test.values, det, err, test.det)
+		}
+
+		inverse, err := Inverse(m)
+		if test.det == 0 {
+			if !errors.Is(err, ErrSingular) {
+				t.Errorf("Inverse(%v) error %v, want ErrSingular", test.values, err)
+			}
+			continue
+		}
+		product, _ := m.Mul(inverse)
+		identity, _ := Identity[float64](m.Rows())
+		if err != nil || !closeMatrices(product, identity, 1e-12) {
+			t.Errorf("%v * Inverse = %v, %v", test.values, product, err)
+		}
+	}
+	if _, err := Det(mustMatrix(t, [][]float64{{1, 2}})); !errors.Is(err, ErrNotSquare) {
+		t.Errorf("Det(1x2) error %v, want ErrNotSquare", err)
+	}
+}
+
+func closeMatrices(a, b *Matrix[float64], tolerance float64) bool {
+	if a.rows != b.rows || a.cols != b.cols {
+		return false
+	}
+	for index := range a.data {
+		if math.Abs(a.data[index]-b.data[index]) > tolerance {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,470 @@
+package matrix
+
+import (
+	"bufio"
+	mathutil "first/mathUtil"
+	"fmt"
+	"io"
+	"iter"
+	"math/cmplx"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+/*
+
+Matrix Market (.mtx) is the text format used by most sparse matrix collections.
+A file starts with a banner, optional % comments and a size line:
+
+	%%MatrixMarket matrix coordinate real general
+	% any number of comments
+	3 3 2
+	1 1 5.0
+	3 2 2.0
+
+coordinate files list rows cols nnz on the size line, then nnz "row col value"
+lines with 1-based indices. array files list rows cols, then every value in
+column-major order, one per line.
+
+The field (integer, real, complex or pattern) must be representable by the
+element type: a real file can't be read into an int matrix, a complex file
+only into a complex one. pattern files have no values, every listed element is 1.
+
+The symmetry can be general, symmetric, skew-symmetric or hermitian. For the
+last three only the lower triangle is stored, and the readers mirror it.
+
+The writers always produce general files, with the field matching the element type.
+
+*/
+
+// MMHeader describes a Matrix Market file
+type MMHeader struct {
+	Format   string // "coordinate" or "array"
+	Field    string // "integer", "real", "complex" or "pattern"
+	Symmetry string // "general", "symmetric", "skew-symmetric" or "hermitian"
+	Rows     int
+	Cols     int
+	NNZ      int // number of stored entries for coordinate files, rows*cols for array files
+}
+
+const mmBanner = "%%MatrixMarket"
+
+// MMReader reads a Matrix Market file incrementally
+type MMReader[T mathutil.Number] struct {
+	scanner *bufio.Scanner
+	header  MMHeader
+	line    int
+}
+
+func (mr *MMReader[T]) errorf(format string, args ...any) error {
+	return fmt.Errorf("%w: line %d: %s", ErrFormat, mr.line, fmt.Sprintf(format, args...))
+}
+
+// nextLine returns the next line that isn't a comment or blank
+func (mr *MMReader[T]) nextLine() (string, bool) {
+	for mr.scanner.Scan() {
+		mr.line++
+		text := strings.TrimSpace(mr.scanner.Text())
+		if text != "" && !strings.HasPrefix(text, "%") {
+			return text, true
+		}
+	}
+	return "", false
+}
+
+// NewMMReader reads and validates the banner and the size line of a Matrix Market file
+func NewMMReader[T mathutil.Number](r io.Reader) (*MMReader[T], error) {
+	mr := &MMReader[T]{scanner: bufio.NewScanner(r)}
+
+	if !mr.scanner.Scan() {
+		if err := mr.scanner.Err(); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: empty file", ErrFormat)
+	}
+	mr.line++
+
+	// the banner is case insensitive, except for the %%MatrixMarket token itself
+	banner := strings.Fields(mr.scanner.Text())
+	if len(banner) != 5 || banner[0] != mmBanner {
+		return nil, mr.errorf("expected %q banner", mmBanner+" matrix <format> <field> <symmetry>")
+	}
+	if strings.ToLower(banner[1]) != "matrix" {
+		return nil, mr.errorf("unsupported object %q, only matrix is supported", banner[1])
+	}
+
+	header := MMHeader{
+		Format:   strings.ToLower(banner[2]),
+		Field:    strings.ToLower(banner[3]),
+		Symmetry: strings.ToLower(banner[4]),
+	}
+	if header.Format != "coordinate" && header.Format != "array" {
+		return nil, mr.errorf("unknown format %q", banner[2])
+	}
+	if err := mr.checkField(header); err != nil {
+		return nil, err
+	}
+	switch header.Symmetry {
+	case "general", "symmetric", "skew-symmetric":
+	case "hermitian":
+		if header.Field != "complex" {
+			return nil, mr.errorf("hermitian symmetry requires the complex field")
+		}
+	default:
+		return nil, mr.errorf("unknown symmetry %q", banner[4])
+	}
+	if header.Format == "array" && header.Field == "pattern" {
+		return nil, mr.errorf("array files can't use the pattern field")
+	}
+
+	sizeLine, ok := mr.nextLine()
+	if !ok {
+		if err := mr.scanner.Err(); err != nil {
+			return nil, err
+		}
+		return nil, mr.errorf("missing size line")
+	}
+	sizes := strings.Fields(sizeLine)
+	wantSizes := 3
+	if header.Format == "array" {
+		wantSizes = 2
+	}
+	if len(sizes) != wantSizes {
+		return nil, mr.errorf("size line must have %d numbers, found %d", wantSizes, len(sizes))
+	}
+	values := make([]int, len(sizes))
+	for index, text := range sizes {
+		value, err := strconv.Atoi(text)
+		if err != nil || value < 0 {
+			return nil, mr.errorf("invalid size %q", text)
+		}
+		values[index] = value
+	}
+
+	header.Rows, header.Cols = values[0], values[1]
+	if header.Format == "coordinate" {
+		header.NNZ = values[2]
+	} else {
+		// every element is in the file, an overflowing or huge count is a corrupted size line
+		size, err := mathutil.MulChecked(header.Rows, header.Cols)
+		if err != nil || int64(size) > maxElements {
+			return nil, mr.errorf("size %dx%d is too large", header.Rows, header.Cols)
+		}
+		header.NNZ = size
+	}
+	if header.Symmetry != "general" && header.Rows != header.Cols {
+		return nil, mr.errorf("%s matrix must be square, found %dx%d", header.Symmetry, header.Rows, header.Cols)
+	}
+
+	mr.header = header
+	return mr, nil
+}
+
+// checkField reports whether the values of the field can be stored in a T
+func (mr *MMReader[T]) checkField(header MMHeader) error {
+	kind := kindOf[T]()
+	switch header.Field {
+	case "pattern", "integer":
+		return nil
+	case "real":
+		if kind == kindReal || kind == kindComplex {
+			return nil
+		}
+	case "complex":
+		if kind == kindComplex {
+			return nil
+		}
+	default:
+		return mr.errorf("unknown field %q", header.Field)
+	}
+	var zero T
+	return mr.errorf("can't read a %s matrix into %T elements", header.Field, zero)
+}
+
+// Header returns the header of the file
+func (mr *MMReader[T]) Header() MMHeader {
+	return mr.header
+}
+
+// parseMMValue parses the value fields of an entry according to the file's field
+func (mr *MMReader[T]) parseMMValue(fields []string) (T, error) {
+	var value T
+	switch mr.header.Field {
+	case "pattern":
+		if len(fields) != 0 {
+			return value, mr.errorf("pattern entries have no value")
+		}
+		return 1, nil
+	case "complex":
+		if len(fields) != 2 {
+			return value, mr.errorf("complex entries need a real and an imaginary part")
+		}
+		re, errRe := strconv.ParseFloat(fields[0], 64)
+		im, errIm := strconv.ParseFloat(fields[1], 64)
+		if errRe != nil || errIm != nil {
+			return value, mr.errorf("invalid complex value %q %q", fields[0], fields[1])
+		}
+		reflect.ValueOf(&value).Elem().SetComplex(complex(re, im))
+		return value, nil
+	}
+
+	if len(fields) != 1 {
+		return value, mr.errorf("expected a single value, found %d fields", len(fields))
+	}
+	value, err := parseValue[T](fields[0])
+	if err != nil {
+		return value, mr.errorf("%v", err)
+	}
+	return value, nil
+}
+
+// mirror returns the entry matching entry in the upper triangle of a symmetric matrix
+func (mr *MMReader[T]) mirror(entry Entry[T]) Entry[T] {
+	mirrored := Entry[T]{Row: entry.Col, Col: entry.Row, Value: entry.Value}
+	switch mr.header.Symmetry {
+	case "skew-symmetric":
+		mirrored.Value = -entry.Value
+	case "hermitian":
+		target := reflect.ValueOf(&mirrored.Value).Elem()
+		target.SetComplex(cmplx.Conj(target.Complex()))
+	}
+	return mirrored
+}
+
+// Entries streams the elements stored in the file, with 0-based indices
+// for symmetric files the mirrored elements of the upper triangle are yielded too
+// for array files every element is yielded, zeros included, in column-major order
+// iteration stops after the first error, and an error is also yielded if the
+// file doesn't have exactly as many entries as its size line announced
+func (mr *MMReader[T]) Entries() iter.Seq2[Entry[T], error] {
+	return func(yield func(Entry[T], error) bool) {
+		header := mr.header
+		symmetric := header.Symmetry != "general"
+
+		// for symmetric array files only the lower triangle is stored, column by column
+		arrayRow, arrayCol := 0, 0
+		expected := header.NNZ
+		if header.Format == "array" && symmetric {
+			expected = header.Rows * (header.Rows + 1) / 2
+			if header.Symmetry == "skew-symmetric" {
+				// the diagonal of a skew-symmetric matrix is zero and isn't stored
+				expected = header.Rows * (header.Rows - 1) / 2
+				arrayRow = 1
+			}
+		}
+
+		for count := 0; count < expected; count++ {
+			text, ok := mr.nextLine()
+			if !ok {
+				if err := mr.scanner.Err(); err != nil {
+					yield(Entry[T]{}, err)
+				} else {
+					yield(Entry[T]{}, mr.errorf("expected %d entries, found %d", expected, count))
+				}
+				return
+			}
+			fields := strings.Fields(text)
+
+			var entry Entry[T]
+			var err error
+			if header.Format == "coordinate" {
+				if len(fields) < 2 {
+					yield(entry, mr.errorf("expected row and column indices"))
+					return
+				}
+				if entry.Row, err = parseIndex1(fields[0], header.Rows); err != nil {
+					yield(entry, mr.errorf("%v", err))
+					return
+				}
+				if entry.Col, err = parseIndex1(fields[1], header.Cols); err != nil {
+					yield(entry, mr.errorf("%v", err))
+					return
+				}
+				if symmetric && entry.Col > entry.Row {
+					yield(entry, mr.errorf("%s files only store the lower triangle, found (%d, %d)", header.Symmetry, entry.Row+1, entry.Col+1))
+					return
+				}
+				fields = fields[2:]
+			} else {
+				entry.Row, entry.Col = arrayRow, arrayCol
+				arrayRow++
+				if arrayRow == header.Rows {
+					arrayCol++
+					arrayRow = 0
+					if symmetric {
+						arrayRow = arrayCol
+						if header.Symmetry == "skew-symmetric" {
+							arrayRow++
+						}
+					}
+				}
+			}
+
+			if entry.Value, err = mr.parseMMValue(fields); err != nil {
+				yield(entry, err)
+				return
+			}
+			if !yield(entry, nil) {
+				return
+			}
+			if symmetric && entry.Row != entry.Col {
+				if !yield(mr.mirror(entry), nil) {
+					return
+				}
+			}
+		}
+
+		if text, ok := mr.nextLine(); ok {
+			yield(Entry[T]{}, mr.errorf("unexpected data after the last entry: %q", text))
+		} else if err := mr.scanner.Err(); err != nil {
+			yield(Entry[T]{}, err)
+		}
+	}
+}
+
+// parseIndex1 parses a 1-based index that must be at most size and returns it 0-based
+func parseIndex1(text string, size int) (int, error) {
+	index, err := strconv.Atoi(text)
+	if err != nil {
+		return 0, fmt.Errorf("invalid index %q", text)
+	}
+	if index < 1 || index > size {
+		return 0, fmt.Errorf("index %d out of range [1, %d]", index, size)
+	}
+	return index - 1, nil
+}
+
+// ReadMMCOO reads a Matrix Market file of any format as a COO matrix
+func ReadMMCOO[T mathutil.Number](r io.Reader) (*COO[T], error) {
+	mr, err := NewMMReader[T](r)
+	if err != nil {
+		return nil, err
+	}
+	coo := &COO[T]{rows: mr.header.Rows, cols: mr.header.Cols}
+	for entry, err := range mr.Entries() {
+		if err != nil {
+			return nil, err
+		}
+		if entry.Value != 0 {
+			coo.entries = append(coo.entries, entry)
+		}
+	}
+	return coo, nil
+}
+
+// ReadMM reads a Matrix Market file of any format as a dense matrix
+// duplicated coordinate entries are summed, like the COO conversions do
+func ReadMM[T mathutil.Number](r io.Reader) (*Matrix[T], error) {
+	mr, err := NewMMReader[T](r)
+	if err != nil {
+		return nil, err
+	}
+	m, err := Zeros[T](mr.header.Rows, mr.header.Cols)
+	if err != nil {
+		// a coordinate file can describe a matrix too large to be dense
+		return nil, mr.errorf("%v", err)
+	}
+	for entry, err := range mr.Entries() {
+		if err != nil {
+			return nil, err
+		}
+		m.data[entry.Row*m.cols+entry.Col] += entry.Value
+	}
+	return m, nil
+}
+
+// mmField returns the Matrix Market field matching the element type T
+func mmField[T mathutil.Number]() string {
+	switch kindOf[T]() {
+	case kindReal:
+		return "real"
+	case kindComplex:
+		return "complex"
+	}
+	return "integer"
+}
+
+// formatMMValue formats value the way the field of T expects it
+// complex values are written as two numbers separated by a space
+func formatMMValue[T mathutil.Number](value T) string {
+	if kindOf[T]() != kindComplex {
+		return formatValue(value)
+	}
+	source := reflect.ValueOf(value)
+	bits := source.Type().Bits() / 2
+	c := source.Complex()
+	return strconv.FormatFloat(real(c), 'g', -1, bits) + " " + strconv.FormatFloat(imag(c), 'g', -1, bits)
+}
+
+// MMWriter writes a coordinate Matrix Market file incrementally
+// the number of entries is part of the header, so it has to be known upfront
+type MMWriter[T mathutil.Number] struct {
+	writer  *bufio.Writer
+	rows    int
+	cols    int
+	nnz     int
+	written int
+}
+
+// NewMMWriter writes the header of a coordinate file with nnz entries
+func NewMMWriter[T mathutil.Number](w io.Writer, rows, cols, nnz int) (*MMWriter[T], error) {
+	if rows < 0 || cols < 0 || nnz < 0 {
+		return nil, fmt.Errorf("%w: negative size %dx%d with %d entries", ErrShape, rows, cols, nnz)
+	}
+	mw := &MMWriter[T]{writer: bufio.NewWriter(w), rows: rows, cols: cols, nnz: nnz}
+	_, err := fmt.Fprintf(mw.writer, "%s matrix coordinate %s general\n%d %d %d\n", mmBanner, mmField[T](), rows, cols, nnz)
+	return mw, err
+}
+
+// Write writes a single entry, with 0-based indices
+func (mw *MMWriter[T]) Write(entry Entry[T]) error {
+	if entry.Row < 0 || entry.Row >= mw.rows || entry.Col < 0 || entry.Col >= mw.cols {
+		return fmt.Errorf("%w: (%d, %d) in a %dx%d matrix", ErrOutOfBounds, entry.Row, entry.Col, mw.rows, mw.cols)
+	}
+	if mw.written == mw.nnz {
+		return fmt.Errorf("matrix: more than the %d announced entries", mw.nnz)
+	}
+	mw.written++
+	_, err := fmt.Fprintf(mw.writer, "%d %d %s\n", entry.Row+1, entry.Col+1, formatMMValue(entry.Value))
+	return err
+}
+
+// Close flushes the output and checks that all the announced entries were written
+// it doesn't close the underlying writer
+func (mw *MMWriter[T]) Close() error {
+	if err := mw.writer.Flush(); err != nil {
+		return err
+	}
+	if mw.written != mw.nnz {
+		return fmt.Errorf("matrix: %d entries announced, %d written", mw.nnz, mw.written)
+	}
+	return nil
+}
+
+// WriteMMCOO writes coo as a coordinate Matrix Market file
+func WriteMMCOO[T mathutil.Number](w io.Writer, coo *COO[T]) error {
+	mw, err := NewMMWriter[T](w, coo.rows, coo.cols, coo.NNZ())
+	if err != nil {
+		return err
+	}
+	for entry := range coo.NonZeros() {
+		if err := mw.Write(entry); err != nil {
+			return err
+		}
+	}
+	return mw.Close()
+}
+
+// WriteMM writes m as an array Matrix Market file
+func WriteMM[T mathutil.Number](w io.Writer, m *Matrix[T]) error {
+	writer := bufio.NewWriter(w)
+	fmt.Fprintf(writer, "%s matrix array %s general\n%d %d\n", mmBanner, mmField[T](), m.rows, m.cols)
+	for col := 0; col < m.cols; col++ {
+		for row := 0; row < m.rows; row++ {
+			writer.WriteString(formatMMValue(m.data[row*m.cols+col]))
+			writer.WriteByte('\n')
+		}
+	}
+	return writer.Flush()
+}
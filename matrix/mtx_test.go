@@ -0,0 +1,108 @@
+package matrix
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestMatrixMarket(t *testing.T) {
+	tests := []struct {
+		name, text string
+		want       [][]float64
+	}{
+		{"coordinate", "%%MatrixMarket matrix coordinate real general\n% comment\n3 3 2\n1 1 5.0\n3 2 2.0\n",
+			[][]float64{{5, 0, 0}, {0, 0, 0}, {0, 2, 0}}},
+		{"duplicates", "%%MatrixMarket matrix coordinate real general\n1 1 2\n1 1 1\n1 1 2\n",
+			[][]float64{{3}}},
+		{"array", "%%MatrixMarket matrix array real general\n2 2\n1\n2\n3\n4\n",
+			[][]float64{{1, 3}, {2, 4}}},
+		{"symmetric", "%%MatrixMarket matrix coordinate integer symmetric\n2 2 2\n1 1 1\n2 1 7\n",
+			[][]float64{{1, 7}, {7, 0}}},
+		{"skew-symmetric array", "%%MatrixMarket matrix array real skew-symmetric\n3 3\n1\n2\n3\n",
+			[][]float64{{0, -1, -2}, {1, 0, -3}, {2, 3, 0}}},
+		{"pattern", "%%MatrixMarket MATRIX Coordinate Pattern General\n2 2 1\n2 2\n",
+			[][]float64{{0, 0}, {0, 1}}},
+	}
+	for _, test := range tests {
+		want := mustMatrix(t, test.want)
+		if got, err := ReadMM[float64](strings.NewReader(test.text)); err != nil || !got.Equal(want) {
+			t.Errorf("%s: ReadMM = %v, %v, want %v", test.name, got, err, want)
+		}
+		if coo, err := ReadMMCOO[float64](strings.NewReader(test.text)); err != nil || !coo.ToDense().Equal(want) {
+			t.Errorf("%s: ReadMMCOO = %v, %v, want %v", test.name, coo, err, want)
+		}
+	}
+}
+
+func TestMatrixMarketHermitian(t *testing.T) {
+	text := "%%MatrixMarket matrix coordinate complex hermitian\n2 2 2\n1 1 1 0\n2 1 2 3\n"
+	got, err := ReadMM[complex128](strings.NewReader(text))
+	want, _ := FromSlices([][]complex128{{1, 2 - 3i}, {2 + 3i, 0}})
+	if err != nil || !got.Equal(want) {
+		t.Errorf("ReadMM = %v, %v, want %v", got, err, want)
+	}
+}
+
+func TestMatrixMarketRoundTrip(t *testing.T) {
+	m := mustMatrix(t, [][]float64{{1.5, 0}, {0, -2}, {3, 0}})
+	var buffer bytes.Buffer
+	if err := WriteMM(&buffer, m); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := ReadMM[float64](&buffer); err != nil || !got.Equal(m) {
+		t.Errorf("ReadMM(WriteMM(m)) = %v, %v, want %v", got, err, m)
+	}
+
+	buffer.Reset()
+	if err := WriteMMCOO(&buffer, COOFromDense(m)); err != nil {
+		t.Fatal(err)
+	}
+	if want := "%%MatrixMarket matrix coordinate real general\n3 2 3\n1 1 1.5\n2 2 -2\n3 1 3\n"; buffer.String() != want {
+		t.Errorf("WriteMMCOO = %q, want %q", buffer.String(), want)
+	}
+	if got, err := ReadMMCOO[float64](&buffer); err != nil || !got.ToDense().Equal(m) {
+		t.Errorf("ReadMMCOO(WriteMMCOO(m)) = %v, %v, want %v", got, err, m)
+	}
+
+	// the writer holds to the announced number of entries
+	mw, _ := NewMMWriter[int](&buffer, 2, 2, 1)
+	if err := mw.Write(Entry[int]{Row: 2, Col: 0, Value: 1}); !errors.Is(err, ErrOutOfBounds) {
+		t.Errorf("Write out of bounds error %v, want ErrOutOfBounds", err)
+	}
+	if err := mw.Close(); err == nil {
+		t.Error("Close with a missing entry succeeded")
+	}
+}
+
+func TestMatrixMarketErrors(t *testing.T) {
+	tests := []string{
+		"",
+		"%%MatrixMarket vector coordinate real general\n1 1 0\n",
+		"%%MatrixMarket matrix coordinate real hermitian\n1 1 0\n",
+		"%%MatrixMarket matrix array pattern general\n1 1\n",
+		"%%MatrixMarket matrix coordinate real symmetric\n1 2 0\n",
+		"%%MatrixMarket matrix coordinate real general\n2 2\n",
+		"%%MatrixMarket matrix coordinate real general\n2 2 2\n1 1 1\n",
+		"%%MatrixMarket matrix coordinate real general\n2 2 1\n1 1 1\n2 2 2\n",
+		"%%MatrixMarket matrix coordinate real general\n2 2 1\n3 1 1\n",
+		"%%MatrixMarket matrix coordinate real symmetric\n2 2 1\n1 2 1\n",
+		"%%MatrixMarket matrix coordinate real general\n2 2 1\n1 1 1.5\n", // read as int
+		"%%MatrixMarket matrix coordinate complex general\n1 1 1\n1 1 1 0\n",
+		"%%MatrixMarket matrix array real general\n3037000500 3037000500\n", // the size overflows
+		"%%MatrixMarket matrix array real general\n2097152 1048576\n",
+		"%%MatrixMarket matrix coordinate real general\n2097152 1048576 0\n", // too large for ReadMM only
+	}
+	for _, text := range tests {
+		if _, err := ReadMM[int](strings.NewReader(text)); !errors.Is(err, ErrFormat) {
+			t.Errorf("ReadMM(%q) error %v, want ErrFormat", text, err)
+		}
+	}
+
+	// sparse, the same size is fine
+	text := "%%MatrixMarket matrix coordinate integer general\n2097152 1048576 1\n2097152 1 1\n"
+	if coo, err := ReadMMCOO[int](strings.NewReader(text)); err != nil || coo.NNZ() != 1 {
+		t.Errorf("ReadMMCOO(%q) = %v, %v", text, coo, err)
+	}
+}
@@ -0,0 +1,84 @@
+package matrix
+
+import "fmt"
+
+// Add returns m + other
+func (m *Matrix[T]) Add(other *Matrix[T]) (*Matrix[T], error) {
+	if m.rows != other.rows || m.cols != other.cols {
+		return nil, fmt.Errorf("%w: can't add %dx%d and %dx%d", ErrShape, m.rows, m.cols, other.rows, other.cols)
+	}
+	sum := m.Clone()
+	for index, value := range other.data {
+		sum.data[index] += value
+	}
+	return sum, nil
+}
+
+// Sub returns m - other
+func (m *Matrix[T]) Sub(other *Matrix[T]) (*Matrix[T], error) {
+	if m.rows != other.rows || m.cols != other.cols {
+		return nil, fmt.Errorf("%w: can't subtract %dx%d and %dx%d", ErrShape, m.rows, m.cols, other.rows, other.cols)
+	}
+	difference := m.Clone()
+	for index, value := range other.data {
+		difference.data[index] -= value
+	}
+	return difference, nil
+}
+
+// Scale returns every element of m multiplied by factor
+func (m *Matrix[T]) Scale(factor T) *Matrix[T] {
+	scaled := m.Clone()
+	for index := range scaled.data {
+		scaled.data[index] *= factor
+	}
+	return scaled
+}
+
+// Mul returns the matrix product m * other
+// m must have as many columns as other has rows
+func (m *Matrix[T]) Mul(other *Matrix[T]) (*Matrix[T], error) {
+	if m.cols != other.rows {
+		return nil, fmt.Errorf("%w: can't multiply %dx%d by %dx%d", ErrShape, m.rows, m.cols, other.rows, other.cols)
+	}
+
+	product, err := Zeros[T](m.rows, other.cols)
+	if err != nil {
+		return nil, err
+	}
+	// the i-k-j loop order walks both other and product row by row, which is
+	// much friendlier to the cache than the textbook i-j-k order
+	for i := 0; i < m.rows; i++ {
+		productRow := product.data[i*other.cols : (i+1)*other.cols]
+		for k := 0; k < m.cols; k++ {
+			a := m.data[i*m.cols+k]
+			otherRow := other.data[k*other.cols : (k+1)*other.cols]
+			for j, b := range otherRow {
+				productRow[j] += a * b
+			}
+		}
+	}
+	return product, nil
+}
+
+// Transpose returns the transpose of m, whose element at (row, col) is m's element at (col, row)
+func (m *Matrix[T]) Transpose() *Matrix[T] {
+	transposed := &Matrix[T]{rows: m.cols, cols: m.rows, data: make([]T, len(m.data))}
+	for row := 0; row < m.rows; row++ {
+		for col := 0; col < m.cols; col++ {
+			transposed.data[col*m.rows+row] = m.data[row*m.cols+col]
+		}
+	}
+	return transposed
+}
+
+// Trace returns the sum of the diagonal elements of the square matrix m
+func (m *Matrix[T]) Trace() (trace T, err error) {
+	if !m.IsSquare() {
+		return trace, fmt.Errorf("%w: trace of a %dx%d matrix", ErrNotSquare, m.rows, m.cols)
+	}
+	for index := 0; index < m.rows; index++ {
+		trace += m.data[index*m.cols+index]
+	}
+	return trace, nil
+}
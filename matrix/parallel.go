@@ -0,0 +1,109 @@
+package matrix
+
+import (
+	mathutil "first/mathUtil"
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+/*
+
+MulParallel multiplies large matrices with two classic optimizations:
+
+	cache blocking: the matrices are processed in tiles of TileSize x TileSize
+	elements, small enough that the tiles being combined stay in the CPU cache
+	instead of being fetched from memory again for every row
+
+	parallelism: the rows of the product are split in bands of TileSize rows,
+	and Workers goroutines take bands one at a time until none is left. Every
+	band is written by exactly one goroutine, so no locking is needed
+
+Every element of the product still accumulates its terms in the same order as
+Mul (k = 0, 1, 2, ...), so the result is identical to Mul bit for bit, for
+integer matrices (where the order doesn't matter anyway) and float ones alike.
+
+*/
+
+// DefaultTileSize is the tile size used when MulOptions.TileSize is 0
+// 64x64 float64 elements are 32KiB, which fits most L1 data caches
+const DefaultTileSize = 64
+
+// MulOptions configures MulParallel, the zero value picks sensible defaults
+type MulOptions struct {
+	Workers  int // number of goroutines, runtime.GOMAXPROCS(0) if 0
+	TileSize int // side of the square tiles, DefaultTileSize if 0
+}
+
+// MulParallel returns the matrix product m * other, computed by a cache
+// blocked algorithm running on several goroutines
+func (m *Matrix[T]) MulParallel(other *Matrix[T], options MulOptions) (*Matrix[T], error) {
+	if m.cols != other.rows {
+		return nil, fmt.Errorf("%w: can't multiply %dx%d by %dx%d", ErrShape, m.rows, m.cols, other.rows, other.cols)
+	}
+	if options.Workers < 0 || options.TileSize < 0 {
+		return nil, fmt.Errorf("matrix: invalid options %+v", options)
+	}
+
+	workers := options.Workers
+	if workers == 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	tile := options.TileSize
+	if tile == 0 {
+		tile = DefaultTileSize
+	}
+
+	product, err := Zeros[T](m.rows, other.cols)
+	if err != nil {
+		return nil, err
+	}
+	bands := (m.rows + tile - 1) / tile
+	if workers > bands {
+		workers = bands
+	}
+
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	for worker := 0; worker < workers; worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				band := int(next.Add(1) - 1)
+				if band >= bands {
+					return
+				}
+				mulBand(m, other, product, band*tile, min((band+1)*tile, m.rows), tile)
+			}
+		}()
+	}
+	wg.Wait()
+	return product, nil
+}
+
+// mulBand computes the rows [rowStart, rowEnd) of product = a * b tile by tile
+func mulBand[T mathutil.Number](a, b, product *Matrix[T], rowStart, rowEnd, tile int) {
+	n, p := a.cols, b.cols
+	// the k tiles must be visited in increasing order to add the terms of
+	// each element in the same order as Mul, the j tiles can go in any order
+	for kStart := 0; kStart < n; kStart += tile {
+		kEnd := min(kStart+tile, n)
+		for jStart := 0; jStart < p; jStart += tile {
+			jEnd := min(jStart+tile, p)
+			for i := rowStart; i < rowEnd; i++ {
+				productRow := product.data[i*p+jStart : i*p+jEnd]
+				for k := kStart; k < kEnd; k++ {
+					value := a.data[i*n+k]
+					// reslicing to the same length lets the compiler drop the bounds checks
+					bRow := b.data[k*p+jStart : k*p+jEnd]
+					bRow = bRow[:len(productRow)]
+					for j := range productRow {
+						productRow[j] += value * bRow[j]
+					}
+				}
+			}
+		}
+	}
+}
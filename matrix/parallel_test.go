@@ -0,0 +1,29 @@
+package matrix
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMulParallel(t *testing.T) {
+	// sizes that aren't multiples of the tile, so the last tiles are partial
+	a, _ := FromFunc(37, 53, func(row, col int) int { return (row*7 + col*3) % 11 })
+	b, _ := FromFunc(53, 29, func(row, col int) int { return (row*5 - col) % 13 })
+	want, _ := a.Mul(b)
+	for _, options := range []MulOptions{{}, {Workers: 1, TileSize: 1}, {Workers: 3, TileSize: 8}, {Workers: 64, TileSize: 100}} {
+		if got, err := a.MulParallel(b, options); err != nil || !got.Equal(want) {
+			t.Errorf("MulParallel(%+v) differs from Mul: %v", options, err)
+		}
+	}
+	if _, err := a.MulParallel(b, MulOptions{Workers: -1}); err == nil {
+		t.Error("MulParallel with negative workers succeeded")
+	}
+	if _, err := a.MulParallel(a, MulOptions{}); !errors.Is(err, ErrShape) {
+		t.Errorf("MulParallel of 37x53 by 37x53 error %v, want ErrShape", err)
+	}
+	column, _ := Zeros[int8](1<<21, 1)
+	row, _ := Zeros[int8](1, 1<<20)
+	if _, err := column.MulParallel(row, MulOptions{}); !errors.Is(err, ErrShape) {
+		t.Errorf("MulParallel with a 2^41 elements product error %v, want ErrShape", err)
+	}
+}
@@ -0,0 +1,481 @@
+package matrix
+
+import (
+	"errors"
+	mathutil "first/mathUtil"
+	"fmt"
+	"math"
+)
+
+/*
+
+Solving linear systems Ax = b for float matrices.
+
+	Solve(a, b)         square systems, Gaussian elimination with partial pivoting
+	FactorLU(a)         the same elimination, kept around to solve many right hand sides
+	FactorQR(a)         Householder QR, for square and tall (overdetermined) matrices
+	LeastSquares(a, b)  the x minimizing ||Ax - b|| when there are more equations than unknowns
+
+A system whose matrix is exactly singular fails with ErrSingular. A system that
+can be solved but whose solution is dominated by rounding errors fails with a
+*ConditionError, which carries an estimate of the condition number of a and
+matches ErrIllConditioned with errors.Is. In that case the computed solution is
+still returned, for callers that want to use it anyway.
+
+The condition number is ||A|| * ||A⁻¹|| in the 1-norm. Roughly, log10 of it is
+the number of decimal digits lost when solving the system, so a system is
+reported as ill conditioned when it exceeds 1 / machine epsilon, which means
+that no correct digit is left.
+
+*/
+
+var ErrIllConditioned = errors.New("matrix: ill-conditioned matrix")
+
+// ConditionError is returned when the matrix of a system is too ill conditioned
+// for the solution to be trusted
+type ConditionError struct {
+	Cond float64 // estimate of the 1-norm condition number
+}
+
+func (err *ConditionError) Error() string {
+	return fmt.Sprintf("%v: condition number ~%.3g", ErrIllConditioned, err.Cond)
+}
+
+func (err *ConditionError) Is(target error) bool {
+	return target == ErrIllConditioned
+}
+
+// epsilon returns the machine epsilon of T, the gap between 1 and the next float
+func epsilon[T mathutil.Float]() float64 {
+	one := T(1)
+	// float32 has a 24 bit mantissa, float64 a 53 bit one
+	if T(one+T(math.Pow(2, -24))) == one {
+		return math.Pow(2, -23)
+	}
+	return math.Pow(2, -52)
+}
+
+// LU is the factorization PA = LU of a square matrix A, where P is a permutation,
+// L is lower triangular with a unit diagonal and U is upper triangular
+type LU[T mathutil.Float] struct {
+	n     int
+	lu    []T     // L below the diagonal (without its unit diagonal), U on and above it
+	pivot []int   // row i of PA is row pivot[i] of A
+	sign  T       // determinant of P, 1 or -1
+	cond  float64 // estimated once, for the factorization and not for every Solve
+}
+
+// FactorLU computes the LU factorization of the square matrix a with Gaussian
+// elimination and partial pivoting
+// it fails with ErrSingular if a is exactly singular
+func FactorLU[T mathutil.Float](a *Matrix[T]) (*LU[T], error) {
+	if !a.IsSquare() {
+		return nil, fmt.Errorf("%w: LU factorization of a %dx%d matrix", ErrNotSquare, a.rows, a.cols)
+	}
+
+	n := a.rows
+	f := &LU[T]{n: n, lu: a.Clone().data, pivot: make([]int, n), sign: 1}
+	for index := range f.pivot {
+		f.pivot[index] = index
+	}
+
+	lu := f.lu
+	for col := 0; col < n; col++ {
+		pivot := col
+		for row := col + 1; row < n; row++ {
+			if abs(lu[row*n+col]) > abs(lu[pivot*n+col]) {
+				pivot = row
+			}
+		}
+		if lu[pivot*n+col] == 0 {
+			return nil, fmt.Errorf("%w: no pivot in column %d", ErrSingular, col)
+		}
+		if pivot != col {
+			swapRows(lu, n, pivot, col)
+			f.pivot[pivot], f.pivot[col] = f.pivot[col], f.pivot[pivot]
+			f.sign = -f.sign
+		}
+
+		for row := col + 1; row < n; row++ {
+			factor := lu[row*n+col] / lu[col*n+col]
+			lu[row*n+col] = factor
+			for k := col + 1; k < n; k++ {
+				lu[row*n+k] -= factor * lu[col*n+k]
+			}
+		}
+	}
+	if n > 0 {
+		f.cond = inverseNorm1(n, f.solve, f.solveTransposed) * norm1(a)
+	}
+	return f, nil
+}
+
+// L returns the unit lower triangular factor
+func (f *LU[T]) L() *Matrix[T] {
+	l, _ := Identity[T](f.n)
+	for row := 0; row < f.n; row++ {
+		copy(l.data[row*f.n:row*f.n+row], f.lu[row*f.n:row*f.n+row])
+	}
+	return l
+}
+
+// U returns the upper triangular factor
+func (f *LU[T]) U() *Matrix[T] {
+	u, _ := Zeros[T](f.n, f.n)
+	for row := 0; row < f.n; row++ {
+		copy(u.data[row*f.n+row:(row+1)*f.n], f.lu[row*f.n+row:(row+1)*f.n])
+	}
+	return u
+}
+
+// P returns the permutation matrix, such that P * A = L * U
+func (f *LU[T]) P() *Matrix[T] {
+	p, _ := Zeros[T](f.n, f.n)
+	for row, source := range f.pivot {
+		p.data[row*f.n+source] = 1
+	}
+	return p
+}
+
+// Det returns the determinant of A, the product of the diagonal of U times the sign of P
+func (f *LU[T]) Det() T {
+	det := f.sign
+	for index := 0; index < f.n; index++ {
+		det *= f.lu[index*f.n+index]
+	}
+	return det
+}
+
+// solve solves Ax = b by forward substitution with L and back substitution with U
+func (f *LU[T]) solve(b []T) []T {
+	n, lu := f.n, f.lu
+	x := make([]T, n)
+	for row, source := range f.pivot {
+		x[row] = b[source]
+	}
+	for row := 0; row < n; row++ {
+		for k := 0; k < row; k++ {
+			x[row] -= lu[row*n+k] * x[k]
+		}
+	}
+	for row := n - 1; row >= 0; row-- {
+		for k := row + 1; k < n; k++ {
+			x[row] -= lu[row*n+k] * x[k]
+		}
+		x[row] /= lu[row*n+row]
+	}
+	return x
+}
+
+// solveTransposed solves Aᵀx = b, with Aᵀ = Uᵀ Lᵀ P
+func (f *LU[T]) solveTransposed(b []T) []T {
+	n, lu := f.n, f.lu
+	y := make([]T, n)
+	copy(y, b)
+	for row := 0; row < n; row++ {
+		for k := 0; k < row; k++ {
+			y[row] -= lu[k*n+row] * y[k]
+		}
+		y[row] /= lu[row*n+row]
+	}
+	for row := n - 1; row >= 0; row-- {
+		for k := row + 1; k < n; k++ {
+			y[row] -= lu[k*n+row] * y[k]
+		}
+	}
+	x := make([]T, n)
+	for row, source := range f.pivot {
+		x[source] = y[row]
+	}
+	return x
+}
+
+// Cond returns an estimate of the 1-norm condition number of A
+func (f *LU[T]) Cond() float64 {
+	return f.cond
+}
+
+// inverseNorm1 estimates ||M⁻¹|| in the 1-norm for an n x n matrix M with
+// Hager's algorithm, which only needs a few solves with a factorization of M
+// (solve for Mx = b, solveTransposed for Mᵀx = b) instead of computing the inverse
+func inverseNorm1[T mathutil.Float](n int, solve, solveTransposed func([]T) []T) float64 {
+	x := make([]T, n)
+	for index := range x {
+		x[index] = T(1) / T(n)
+	}
+
+	estimate := 0.0
+	for iteration := 0; iteration < 5; iteration++ {
+		y := solve(x)
+		estimate = 0
+		signs := make([]T, n)
+		for index, value := range y {
+			estimate += math.Abs(float64(value))
+			signs[index] = 1
+			if value < 0 {
+				signs[index] = -1
+			}
+		}
+
+		z := solveTransposed(signs)
+		best, zx := 0, 0.0
+		for index, value := range z {
+			zx += float64(value * x[index])
+			if abs(value) > abs(z[best]) {
+				best = index
+			}
+		}
+		if math.Abs(float64(z[best])) <= zx {
+			break
+		}
+		for index := range x {
+			x[index] = 0
+		}
+		x[best] = 1
+	}
+	return estimate
+}
+
+// illConditioned reports whether a system with condition number cond has no correct digit left
+func illConditioned[T mathutil.Float](cond float64) bool {
+	return cond*epsilon[T]() >= 1 || math.IsInf(cond, 0) || math.IsNaN(cond)
+}
+
+// Solve solves Ax = b
+// it fails with a *ConditionError if A is too ill conditioned, see the top of the file
+func (f *LU[T]) Solve(b []T) ([]T, error) {
+	if len(b) != f.n {
+		return nil, fmt.Errorf("%w: %dx%d system with %d right hand side values", ErrShape, f.n, f.n, len(b))
+	}
+	x := f.solve(b)
+	if cond := f.Cond(); illConditioned[T](cond) {
+		return x, &ConditionError{Cond: cond}
+	}
+	return x, nil
+}
+
+// Solve solves the square system Ax = b with Gaussian elimination and partial pivoting
+func Solve[T mathutil.Float](a *Matrix[T], b []T) ([]T, error) {
+	f, err := FactorLU(a)
+	if err != nil {
+		return nil, err
+	}
+	return f.Solve(b)
+}
+
+// norm1 returns the 1-norm of m, its largest absolute column sum
+func norm1[T mathutil.Float](m *Matrix[T]) float64 {
+	norm := 0.0
+	for col := 0; col < m.cols; col++ {
+		sum := 0.0
+		for row := 0; row < m.rows; row++ {
+			sum += math.Abs(float64(m.data[row*m.cols+col]))
+		}
+		norm = math.Max(norm, sum)
+	}
+	return norm
+}
+
+// QR is the factorization A = QR of a rows x cols matrix A with rows >= cols,
+// where Q has orthonormal columns and R is upper triangular
+// Q isn't stored explicitly but as the cols Householder reflections that produce it
+type QR[T mathutil.Float] struct {
+	rows, cols int
+	qr         []T // the reflection vectors on and below the diagonal, R above it
+	rDiag      []T
+	cond       float64 // estimated once, like for LU
+}
+
+// FactorQR computes the QR factorization of a with Householder reflections
+// a must have at least as many rows as columns
+func FactorQR[T mathutil.Float](a *Matrix[T]) (*QR[T], error) {
+	if a.rows < a.cols {
+		return nil, fmt.Errorf("%w: QR factorization of a %dx%d matrix needs rows >= cols", ErrShape, a.rows, a.cols)
+	}
+
+	m, n := a.rows, a.cols
+	f := &QR[T]{rows: m, cols: n, qr: a.Clone().data, rDiag: make([]T, n)}
+	qr := f.qr
+	for k := 0; k < n; k++ {
+		// the reflection maps the column below the diagonal onto a multiple of e1
+		norm := 0.0
+		for row := k; row < m; row++ {
+			norm = math.Hypot(norm, float64(qr[row*n+k]))
+		}
+		if norm == 0 {
+			f.rDiag[k] = 0
+			continue
+		}
+		if qr[k*n+k] < 0 {
+			norm = -norm
+		}
+		for row := k; row < m; row++ {
+			qr[row*n+k] /= T(norm)
+		}
+		qr[k*n+k] += 1
+
+		// apply the reflection to the remaining columns
+		for col := k + 1; col < n; col++ {
+			var s T
+			for row := k; row < m; row++ {
+				s += qr[row*n+k] * qr[row*n+col]
+			}
+			s = -s / qr[k*n+k]
+			for row := k; row < m; row++ {
+				qr[row*n+col] += s * qr[row*n+k]
+			}
+		}
+		f.rDiag[k] = T(-norm)
+	}
+	f.cond = f.estimateCond()
+	return f, nil
+}
+
+// R returns the cols x cols upper triangular factor
+func (f *QR[T]) R() *Matrix[T] {
+	n := f.cols
+	r, _ := Zeros[T](n, n)
+	for row := 0; row < n; row++ {
+		r.data[row*n+row] = f.rDiag[row]
+		copy(r.data[row*n+row+1:(row+1)*n], f.qr[row*n+row+1:(row+1)*n])
+	}
+	return r
+}
+
+// Q returns the rows x cols factor with orthonormal columns
+func (f *QR[T]) Q() *Matrix[T] {
+	m, n := f.rows, f.cols
+	q, _ := Zeros[T](m, n)
+	for k := n - 1; k >= 0; k-- {
+		q.data[k*n+k] = 1
+		for col := k; col < n; col++ {
+			if f.qr[k*n+k] == 0 {
+				continue
+			}
+			var s T
+			for row := k; row < m; row++ {
+				s += f.qr[row*n+k] * q.data[row*n+col]
+			}
+			s = -s / f.qr[k*n+k]
+			for row := k; row < m; row++ {
+				q.data[row*n+col] += s * f.qr[row*n+k]
+			}
+		}
+	}
+	return q
+}
+
+// FullRank reports whether R has no (numerically) zero element on its diagonal
+func (f *QR[T]) FullRank() bool {
+	largest := 0.0
+	for _, value := range f.rDiag {
+		largest = math.Max(largest, math.Abs(float64(value)))
+	}
+	tolerance := largest * float64(max(f.rows, f.cols)) * epsilon[T]()
+	for _, value := range f.rDiag {
+		if math.Abs(float64(value)) <= tolerance {
+			return false
+		}
+	}
+	return true
+}
+
+// Cond returns an estimate of the 1-norm condition number of R, which is
+// within a factor cols of the 2-norm condition number of A: Q doesn't change it
+func (f *QR[T]) Cond() float64 {
+	return f.cond
+}
+
+// estimateCond computes the estimate returned by Cond
+func (f *QR[T]) estimateCond() float64 {
+	n := f.cols
+	if n == 0 {
+		return 0
+	}
+	norm := 0.0
+	for col := 0; col < n; col++ {
+		sum := math.Abs(float64(f.rDiag[col]))
+		for row := 0; row < col; row++ {
+			sum += math.Abs(float64(f.qr[row*n+col]))
+		}
+		norm = math.Max(norm, sum)
+	}
+	return inverseNorm1(n, f.solveR, f.solveRTransposed) * norm
+}
+
+// solveR solves Rx = b by back substitution
+func (f *QR[T]) solveR(b []T) []T {
+	n := f.cols
+	x := make([]T, n)
+	copy(x, b)
+	for row := n - 1; row >= 0; row-- {
+		for k := row + 1; k < n; k++ {
+			x[row] -= f.qr[row*n+k] * x[k]
+		}
+		x[row] /= f.rDiag[row]
+	}
+	return x
+}
+
+// solveRTransposed solves Rᵀx = b by forward substitution
+func (f *QR[T]) solveRTransposed(b []T) []T {
+	n := f.cols
+	x := make([]T, n)
+	copy(x, b)
+	for row := 0; row < n; row++ {
+		for k := 0; k < row; k++ {
+			x[row] -= f.qr[k*n+row] * x[k]
+		}
+		x[row] /= f.rDiag[row]
+	}
+	return x
+}
+
+// Solve returns the x minimizing ||Ax - b||, the exact solution when A is square
+// it fails with ErrSingular if A doesn't have full column rank, and with a
+// *ConditionError if it nearly doesn't, see the top of the file
+func (f *QR[T]) Solve(b []T) ([]T, error) {
+	m, n := f.rows, f.cols
+	if len(b) != m {
+		return nil, fmt.Errorf("%w: %dx%d system with %d right hand side values", ErrShape, m, n, len(b))
+	}
+	// only an exact zero stops the solve, R is invertible otherwise and a
+	// numerically rank deficient A shows up in the condition number
+	for col, value := range f.rDiag {
+		if value == 0 {
+			return nil, fmt.Errorf("%w: matrix is rank deficient, column %d", ErrSingular, col)
+		}
+	}
+
+	// y = Qᵀb, applying the reflections in order
+	y := make([]T, m)
+	copy(y, b)
+	for k := 0; k < n; k++ {
+		var s T
+		for row := k; row < m; row++ {
+			s += f.qr[row*n+k] * y[row]
+		}
+		s = -s / f.qr[k*n+k]
+		for row := k; row < m; row++ {
+			y[row] += s * f.qr[row*n+k]
+		}
+	}
+
+	// then solve Rx = y[:n]
+	x := f.solveR(y[:n])
+	if cond := f.Cond(); illConditioned[T](cond) {
+		return x, &ConditionError{Cond: cond}
+	}
+	return x, nil
+}
+
+// LeastSquares returns the x minimizing ||Ax - b|| for a matrix a with at least
+// as many rows as columns, the exact solution when a is square
+// it fails like (*QR).Solve when a is (nearly) rank deficient
+func LeastSquares[T mathutil.Float](a *Matrix[T], b []T) ([]T, error) {
+	f, err := FactorQR(a)
+	if err != nil {
+		return nil, err
+	}
+	return f.Solve(b)
+}
@@ -0,0 +1,165 @@
+package matrix
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestSolve(t *testing.T) {
+	tests := []struct {
+		a    [][]float64
+		b, x []float64
+	}{
+		{[][]float64{{2, 1}, {1, 3}}, []float64{3, 5}, []float64{0.8, 1.4}},
+		{[][]float64{{0, 2, 0}, {1, 0, 0}, {0, 0, 4}}, []float64{4, 3, 8}, []float64{3, 2, 2}},
+		{[][]float64{{1e-20, 1}, {1, 1}}, []float64{1, 2}, []float64{1, 1}}, // wrong without pivoting
+	}
+	for _, test := range tests {
+		a := mustMatrix(t, test.a)
+		if x, err := Solve(a, test.b); err != nil || !closeVectors(x, test.x, 1e-12) {
+			t.Errorf("Solve(%v, %v) = %v, %v, want %v", test.a, test.b, x, err, test.x)
+		}
+		if x, err := LeastSquares(a, test.b); err != nil || !closeVectors(x, test.x, 1e-12) {
+			t.Errorf("LeastSquares(%v, %v) = %v, %v, want %v", test.a, test.b, x, err, test.x)
+		}
+	}
+}
+
+func TestLU(t *testing.T) {
+	a := mustMatrix(t, [][]float64{{2, 1, 1}, {4, -6, 0}, {-2, 7, 2}})
+	f, err := FactorLU(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pa, _ := f.P().Mul(a)
+	lu, _ := f.L().Mul(f.U())
+	if !closeMatrices(pa, lu, 1e-12) {
+		t.Errorf("PA = %v, LU = %v", pa, lu)
+	}
+	if det, _ := Det(a); math.Abs(f.Det()-det) > 1e-12 {
+		t.Errorf("LU Det = %v, Det = %v", f.Det(), det)
+	}
+	// several right hand sides with one factorization
+	for _, x := range [][]float64{{1, 2, 3}, {-1, 0, 4}} {
+		b := make([]float64, 3)
+		for row := range 3 {
+			for col := range 3 {
+				b[row] += a.data[row*3+col] * x[col]
+			}
+		}
+		if got, err := f.Solve(b); err != nil || !closeVectors(got, x, 1e-12) {
+			t.Errorf("Solve(%v) = %v, %v, want %v", b, got, err, x)
+		}
+	}
+	if _, err := f.Solve([]float64{1, 2}); !errors.Is(err, ErrShape) {
+		t.Errorf("Solve with 2 values error %v, want ErrShape", err)
+	}
+}
+
+func TestSolveFailures(t *testing.T) {
+	hilbert, _ := FromFunc(14, 14, func(row, col int) float64 { return 1 / float64(row+col+1) })
+	ones := make([]float64, 14)
+	for index := range ones {
+		ones[index] = 1
+	}
+
+	tests := []struct {
+		name string
+		a    *Matrix[float64]
+		b    []float64
+		want error
+	}{
+		{"singular", mustMatrix(t, [][]float64{{1, 2}, {2, 4}}), []float64{1, 2}, ErrSingular},
+		{"not square", mustMatrix(t, [][]float64{{1, 2}}), []float64{1}, ErrNotSquare},
+		{"Hilbert 14", hilbert, ones, ErrIllConditioned},
+	}
+	for _, test := range tests {
+		x, err := Solve(test.a, test.b)
+		if !errors.Is(err, test.want) {
+			t.Errorf("%s: Solve error %v, want %v", test.name, err, test.want)
+		}
+		var condErr *ConditionError
+		if errors.As(err, &condErr) && (condErr.Cond < 1e16 || len(x) != len(test.b)) {
+			t.Errorf("%s: Solve = %v, condition %g: the solution must still be returned", test.name, x, condErr.Cond)
+		}
+	}
+}
+
+func TestLeastSquares(t *testing.T) {
+	// the line through (0, 1), (1, 3), (2, 5), (3, 7) is exactly y = 1 + 2x,
+	// with noise it is still the closest one
+	a := mustMatrix(t, [][]float64{{1, 0}, {1, 1}, {1, 2}, {1, 3}})
+	tests := []struct {
+		b, x []float64
+	}{
+		{[]float64{1, 3, 5, 7}, []float64{1, 2}},
+		{[]float64{1.5, 2.5, 5.5, 6.5}, []float64{1.3, 1.8}},
+	}
+	for _, test := range tests {
+		if x, err := LeastSquares(a, test.b); err != nil || !closeVectors(x, test.x, 1e-12) {
+			t.Errorf("LeastSquares(%v) = %v, %v, want %v", test.b, x, err, test.x)
+		}
+	}
+
+	f, _ := FactorQR(a)
+	qr, _ := f.Q().Mul(f.R())
+	if !closeMatrices(qr, a, 1e-12) || !f.FullRank() {
+		t.Errorf("QR = %v, want %v", qr, a)
+	}
+
+	failures := []struct {
+		name string
+		a    [][]float64
+		b    []float64
+		want error
+	}{
+		{"wide", [][]float64{{1, 2, 3}}, []float64{1}, ErrShape},
+		{"rank deficient", [][]float64{{1, 0}, {2, 0}, {3, 0}}, []float64{1, 2, 3}, ErrSingular},
+		// the second column is 3 times the first up to rounding, R ends up
+		// with a tiny diagonal entry rather than an exact zero
+		{"nearly rank deficient", [][]float64{{0.1, 0.3}, {0.2, 0.6}, {0.3, 0.9}, {0.7, 2.1}}, []float64{1, 2, 3, 4}, ErrIllConditioned},
+	}
+	for _, test := range failures {
+		_, err := LeastSquares(mustMatrix(t, test.a), test.b)
+		if !errors.Is(err, test.want) {
+			t.Errorf("%s: LeastSquares error %v, want %v", test.name, err, test.want)
+		}
+	}
+}
+
+func TestCond(t *testing.T) {
+	tests := []struct {
+		a    [][]float64
+		cond float64
+	}{
+		{[][]float64{{1, 0}, {0, 1}}, 1},
+		{[][]float64{{2, 0}, {0, 0.5}}, 4},
+		{[][]float64{{1, 2}, {3, 4}}, 21}, // ||A||₁ = 6, ||A⁻¹||₁ = 3.5
+	}
+	for _, test := range tests {
+		a := mustMatrix(t, test.a)
+		// Hager's estimate is exact on 2x2 matrices
+		lu, _ := FactorLU(a)
+		if math.Abs(lu.Cond()-test.cond) > 1e-9 {
+			t.Errorf("LU Cond(%v) = %v, want %v", test.a, lu.Cond(), test.cond)
+		}
+		// the condition number of R is within a factor n of the one of A
+		qr, _ := FactorQR(a)
+		if cond := qr.Cond(); cond < test.cond/2-1e-9 || cond > test.cond*2+1e-9 {
+			t.Errorf("QR Cond(%v) = %v, want within a factor 2 of %v", test.a, cond, test.cond)
+		}
+	}
+}
+
+func closeVectors(a, b []float64, tolerance float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for index := range a {
+		if math.Abs(a[index]-b[index]) > tolerance {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,413 @@
+package matrix
+
+import (
+	"cmp"
+	mathutil "first/mathUtil"
+	"fmt"
+	"iter"
+	"slices"
+	"sort"
+)
+
+/*
+
+Sparse matrices only store their non-zero elements. There are three formats,
+each good at something different:
+
+	COO (coordinate): a plain list of (row, col, value) entries in any order.
+	Cheap to build incrementally, slow to do maths with.
+
+	CSR (compressed sparse row): the entries sorted by row then column, where
+	rowPtr[i] and rowPtr[i+1] delimit the entries of row i. Fast row access,
+	the format to use for multiplication.
+
+	CSC (compressed sparse column): the same as CSR with rows and columns
+	swapped. Fast column access.
+
+For the matrix
+
+	[ 5 0 0 ]
+	[ 0 0 7 ]
+	[ 0 2 0 ]
+
+the CSR form is
+
+	rowPtr = [0 1 2 3]
+	colIdx = [0 2 1]
+	values = [5 7 2]
+
+The usual flow is to build a COO matrix with Append, convert it once with ToCSR
+or ToCSC, and then only work with the compressed form.
+
+CSR and CSC never store zeros: duplicated COO entries are summed during the
+conversion, and entries that end up being zero are dropped.
+
+*/
+
+// Entry is a single non-zero element of a sparse matrix
+type Entry[T mathutil.Number] struct {
+	Row, Col int
+	Value    T
+}
+
+// COO is a sparse matrix in coordinate format
+type COO[T mathutil.Number] struct {
+	rows, cols int
+	entries    []Entry[T]
+}
+
+// CSR is a sparse matrix in compressed sparse row format
+type CSR[T mathutil.Number] struct {
+	rows, cols int
+	rowPtr     []int // len rows+1
+	colIdx     []int
+	values     []T
+}
+
+// CSC is a sparse matrix in compressed sparse column format
+type CSC[T mathutil.Number] struct {
+	rows, cols int
+	colPtr     []int // len cols+1
+	rowIdx     []int
+	values     []T
+}
+
+// NewCOO returns an empty rows x cols COO matrix
+func NewCOO[T mathutil.Number](rows, cols int) (*COO[T], error) {
+	if rows < 0 || cols < 0 {
+		return nil, fmt.Errorf("%w: negative size %dx%d", ErrShape, rows, cols)
+	}
+	return &COO[T]{rows: rows, cols: cols}, nil
+}
+
+// COOFromDense returns the non-zero elements of m as a COO matrix
+func COOFromDense[T mathutil.Number](m *Matrix[T]) *COO[T] {
+	coo := &COO[T]{rows: m.rows, cols: m.cols}
+	for index, value := range m.data {
+		if value != 0 {
+			coo.entries = append(coo.entries, Entry[T]{Row: index / m.cols, Col: index % m.cols, Value: value})
+		}
+	}
+	return coo
+}
+
+// CSRFromDense returns m in CSR format
+func CSRFromDense[T mathutil.Number](m *Matrix[T]) *CSR[T] {
+	return COOFromDense(m).ToCSR()
+}
+
+// CSCFromDense returns m in CSC format
+func CSCFromDense[T mathutil.Number](m *Matrix[T]) *CSC[T] {
+	return COOFromDense(m).ToCSC()
+}
+
+func (coo *COO[T]) Rows() int { return coo.rows }
+func (coo *COO[T]) Cols() int { return coo.cols }
+
+// NNZ returns the number of stored entries, duplicates and zeros included
+func (coo *COO[T]) NNZ() int { return len(coo.entries) }
+
+// Append adds value at (row, col)
+// appending to the same position twice is allowed, the values are summed by the conversions
+func (coo *COO[T]) Append(row, col int, value T) error {
+	if row < 0 || row >= coo.rows || col < 0 || col >= coo.cols {
+		return fmt.Errorf("%w: (%d, %d) in a %dx%d matrix", ErrOutOfBounds, row, col, coo.rows, coo.cols)
+	}
+	coo.entries = append(coo.entries, Entry[T]{Row: row, Col: col, Value: value})
+	return nil
+}
+
+// NonZeros iterates over the stored entries in the order they were appended
+func (coo *COO[T]) NonZeros() iter.Seq[Entry[T]] {
+	return func(yield func(Entry[T]) bool) {
+		for _, entry := range coo.entries {
+			if !yield(entry) {
+				return
+			}
+		}
+	}
+}
+
+// compress sorts entries by (major, minor), sums the duplicates and drops the zeros
+// it returns the pointer array over the major dimension and the minor indices and values
+func compress[T mathutil.Number](entries []Entry[T], majorSize int, major, minor func(Entry[T]) int) (ptr, idx []int, values []T) {
+	sorted := slices.Clone(entries)
+	slices.SortStableFunc(sorted, func(a, b Entry[T]) int {
+		if c := cmp.Compare(major(a), major(b)); c != 0 {
+			return c
+		}
+		return cmp.Compare(minor(a), minor(b))
+	})
+
+	ptr = make([]int, majorSize+1)
+	for index := 0; index < len(sorted); {
+		entry := sorted[index]
+		sum := entry.Value
+		for index++; index < len(sorted) && major(sorted[index]) == major(entry) && minor(sorted[index]) == minor(entry); index++ {
+			sum += sorted[index].Value
+		}
+		if sum != 0 {
+			ptr[major(entry)+1]++
+			idx = append(idx, minor(entry))
+			values = append(values, sum)
+		}
+	}
+	for index := 0; index < majorSize; index++ {
+		ptr[index+1] += ptr[index]
+	}
+	return ptr, idx, values
+}
+
+func entryRow[T mathutil.Number](entry Entry[T]) int { return entry.Row }
+func entryCol[T mathutil.Number](entry Entry[T]) int { return entry.Col }
+
+// ToCSR returns coo in CSR format
+func (coo *COO[T]) ToCSR() *CSR[T] {
+	ptr, idx, values := compress(coo.entries, coo.rows, entryRow[T], entryCol[T])
+	return &CSR[T]{rows: coo.rows, cols: coo.cols, rowPtr: ptr, colIdx: idx, values: values}
+}
+
+// ToCSC returns coo in CSC format
+func (coo *COO[T]) ToCSC() *CSC[T] {
+	ptr, idx, values := compress(coo.entries, coo.cols, entryCol[T], entryRow[T])
+	return &CSC[T]{rows: coo.rows, cols: coo.cols, colPtr: ptr, rowIdx: idx, values: values}
+}
+
+// ToDense returns coo as a dense matrix
+func (coo *COO[T]) ToDense() *Matrix[T] {
+	m := &Matrix[T]{rows: coo.rows, cols: coo.cols, data: make([]T, coo.rows*coo.cols)}
+	for _, entry := range coo.entries {
+		m.data[entry.Row*coo.cols+entry.Col] += entry.Value
+	}
+	return m
+}
+
+func (csr *CSR[T]) Rows() int { return csr.rows }
+func (csr *CSR[T]) Cols() int { return csr.cols }
+
+// NNZ returns the number of non-zero elements
+func (csr *CSR[T]) NNZ() int { return len(csr.values) }
+
+// At returns the element at (row, col), it needs a binary search in the row
+func (csr *CSR[T]) At(row, col int) (T, error) {
+	var zero T
+	if row < 0 || row >= csr.rows || col < 0 || col >= csr.cols {
+		return zero, fmt.Errorf("%w: (%d, %d) in a %dx%d matrix", ErrOutOfBounds, row, col, csr.rows, csr.cols)
+	}
+	start, end := csr.rowPtr[row], csr.rowPtr[row+1]
+	if index, found := slices.BinarySearch(csr.colIdx[start:end], col); found {
+		return csr.values[start+index], nil
+	}
+	return zero, nil
+}
+
+// NonZeros iterates over the non-zero elements row by row
+func (csr *CSR[T]) NonZeros() iter.Seq[Entry[T]] {
+	return func(yield func(Entry[T]) bool) {
+		for row := 0; row < csr.rows; row++ {
+			for index := csr.rowPtr[row]; index < csr.rowPtr[row+1]; index++ {
+				if !yield(Entry[T]{Row: row, Col: csr.colIdx[index], Value: csr.values[index]}) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// ToCOO returns csr in COO format
+func (csr *CSR[T]) ToCOO() *COO[T] {
+	coo := &COO[T]{rows: csr.rows, cols: csr.cols, entries: make([]Entry[T], 0, csr.NNZ())}
+	for entry := range csr.NonZeros() {
+		coo.entries = append(coo.entries, entry)
+	}
+	return coo
+}
+
+// ToCSC returns csr in CSC format
+func (csr *CSR[T]) ToCSC() *CSC[T] {
+	return csr.ToCOO().ToCSC()
+}
+
+// ToDense returns csr as a dense matrix
+func (csr *CSR[T]) ToDense() *Matrix[T] {
+	m := &Matrix[T]{rows: csr.rows, cols: csr.cols, data: make([]T, csr.rows*csr.cols)}
+	for entry := range csr.NonZeros() {
+		m.data[entry.Row*csr.cols+entry.Col] = entry.Value
+	}
+	return m
+}
+
+// MulVec returns the matrix-vector product csr * vector
+func (csr *CSR[T]) MulVec(vector []T) ([]T, error) {
+	if len(vector) != csr.cols {
+		return nil, fmt.Errorf("%w: can't multiply %dx%d by a vector of %d", ErrShape, csr.rows, csr.cols, len(vector))
+	}
+	product := make([]T, csr.rows)
+	for row := 0; row < csr.rows; row++ {
+		for index := csr.rowPtr[row]; index < csr.rowPtr[row+1]; index++ {
+			product[row] += csr.values[index] * vector[csr.colIdx[index]]
+		}
+	}
+	return product, nil
+}
+
+// MulDense returns the product csr * other, which is dense
+// only the non-zero elements of csr are visited, so the cost is NNZ * other.Cols()
+func (csr *CSR[T]) MulDense(other *Matrix[T]) (*Matrix[T], error) {
+	if csr.cols != other.rows {
+		return nil, fmt.Errorf("%w: can't multiply %dx%d by %dx%d", ErrShape, csr.rows, csr.cols, other.rows, other.cols)
+	}
+	product, err := Zeros[T](csr.rows, other.cols)
+	if err != nil {
+		return nil, err
+	}
+	for row := 0; row < csr.rows; row++ {
+		productRow := product.data[row*other.cols : (row+1)*other.cols]
+		for index := csr.rowPtr[row]; index < csr.rowPtr[row+1]; index++ {
+			value := csr.values[index]
+			k := csr.colIdx[index]
+			otherRow := other.data[k*other.cols : (k+1)*other.cols]
+			for j, otherValue := range otherRow {
+				productRow[j] += value * otherValue
+			}
+		}
+	}
+	return product, nil
+}
+
+// Mul returns the sparse product csr * other
+// it uses Gustavson's algorithm: row i of the product is the sum of the rows k
+// of other scaled by csr's (i, k) elements, accumulated in a dense scratch row
+func (csr *CSR[T]) Mul(other *CSR[T]) (*CSR[T], error) {
+	if csr.cols != other.rows {
+		return nil, fmt.Errorf("%w: can't multiply %dx%d by %dx%d", ErrShape, csr.rows, csr.cols, other.rows, other.cols)
+	}
+
+	product := &CSR[T]{rows: csr.rows, cols: other.cols, rowPtr: make([]int, csr.rows+1)}
+	accumulator := make([]T, other.cols)
+	// touched[j] is row+1 if column j has been written while computing row,
+	// so the scratch arrays never need to be cleared between rows
+	touched := make([]int, other.cols)
+	var cols []int
+
+	for row := 0; row < csr.rows; row++ {
+		cols = cols[:0]
+		for index := csr.rowPtr[row]; index < csr.rowPtr[row+1]; index++ {
+			value := csr.values[index]
+			k := csr.colIdx[index]
+			for otherIndex := other.rowPtr[k]; otherIndex < other.rowPtr[k+1]; otherIndex++ {
+				col := other.colIdx[otherIndex]
+				if touched[col] != row+1 {
+					touched[col] = row + 1
+					accumulator[col] = 0
+					cols = append(cols, col)
+				}
+				accumulator[col] += value * other.values[otherIndex]
+			}
+		}
+
+		sort.Ints(cols)
+		for _, col := range cols {
+			if accumulator[col] != 0 {
+				product.colIdx = append(product.colIdx, col)
+				product.values = append(product.values, accumulator[col])
+			}
+		}
+		product.rowPtr[row+1] = len(product.values)
+	}
+	return product, nil
+}
+
+func (csc *CSC[T]) Rows() int { return csc.rows }
+func (csc *CSC[T]) Cols() int { return csc.cols }
+
+// NNZ returns the number of non-zero elements
+func (csc *CSC[T]) NNZ() int { return len(csc.values) }
+
+// At returns the element at (row, col), it needs a binary search in the column
+func (csc *CSC[T]) At(row, col int) (T, error) {
+	var zero T
+	if row < 0 || row >= csc.rows || col < 0 || col >= csc.cols {
+		return zero, fmt.Errorf("%w: (%d, %d) in a %dx%d matrix", ErrOutOfBounds, row, col, csc.rows, csc.cols)
+	}
+	start, end := csc.colPtr[col], csc.colPtr[col+1]
+	if index, found := slices.BinarySearch(csc.rowIdx[start:end], row); found {
+		return csc.values[start+index], nil
+	}
+	return zero, nil
+}
+
+// NonZeros iterates over the non-zero elements column by column
+func (csc *CSC[T]) NonZeros() iter.Seq[Entry[T]] {
+	return func(yield func(Entry[T]) bool) {
+		for col := 0; col < csc.cols; col++ {
+			for index := csc.colPtr[col]; index < csc.colPtr[col+1]; index++ {
+				if !yield(Entry[T]{Row: csc.rowIdx[index], Col: col, Value: csc.values[index]}) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// ToCOO returns csc in COO format
+func (csc *CSC[T]) ToCOO() *COO[T] {
+	coo := &COO[T]{rows: csc.rows, cols: csc.cols, entries: make([]Entry[T], 0, csc.NNZ())}
+	for entry := range csc.NonZeros() {
+		coo.entries = append(coo.entries, entry)
+	}
+	return coo
+}
+
+// ToCSR returns csc in CSR format
+func (csc *CSC[T]) ToCSR() *CSR[T] {
+	return csc.ToCOO().ToCSR()
+}
+
+// ToDense returns csc as a dense matrix
+func (csc *CSC[T]) ToDense() *Matrix[T] {
+	m := &Matrix[T]{rows: csc.rows, cols: csc.cols, data: make([]T, csc.rows*csc.cols)}
+	for entry := range csc.NonZeros() {
+		m.data[entry.Row*csc.cols+entry.Col] = entry.Value
+	}
+	return m
+}
+
+// DenseMul returns the product m * csc, which is dense
+// it's the mirror of CSR.MulDense: column j of the product only depends on
+// the non-zero elements of column j of csc
+func DenseMul[T mathutil.Number](m *Matrix[T], csc *CSC[T]) (*Matrix[T], error) {
+	if m.cols != csc.rows {
+		return nil, fmt.Errorf("%w: can't multiply %dx%d by %dx%d", ErrShape, m.rows, m.cols, csc.rows, csc.cols)
+	}
+	product, err := Zeros[T](m.rows, csc.cols)
+	if err != nil {
+		return nil, err
+	}
+	for col := 0; col < csc.cols; col++ {
+		for index := csc.colPtr[col]; index < csc.colPtr[col+1]; index++ {
+			k := csc.rowIdx[index]
+			value := csc.values[index]
+			for row := 0; row < m.rows; row++ {
+				product.data[row*csc.cols+col] += m.data[row*m.cols+k] * value
+			}
+		}
+	}
+	return product, nil
+}
+
+// Mul returns the sparse product csc * other
+// (A * B) in CSC is (Bᵀ * Aᵀ)ᵀ in CSR, and a CSC matrix read as CSR is its
+// transpose, so the CSR algorithm can be reused without moving any data
+func (csc *CSC[T]) Mul(other *CSC[T]) (*CSC[T], error) {
+	if csc.cols != other.rows {
+		return nil, fmt.Errorf("%w: can't multiply %dx%d by %dx%d", ErrShape, csc.rows, csc.cols, other.rows, other.cols)
+	}
+	aT := &CSR[T]{rows: csc.cols, cols: csc.rows, rowPtr: csc.colPtr, colIdx: csc.rowIdx, values: csc.values}
+	bT := &CSR[T]{rows: other.cols, cols: other.rows, rowPtr: other.colPtr, colIdx: other.rowIdx, values: other.values}
+	productT, err := bT.Mul(aT)
+	if err != nil {
+		return nil, err
+	}
+	return &CSC[T]{rows: productT.cols, cols: productT.rows, colPtr: productT.rowPtr, rowIdx: productT.colIdx, values: productT.values}, nil
+}
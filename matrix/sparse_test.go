@@ -0,0 +1,115 @@
+package matrix
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestSparseConversions(t *testing.T) {
+	coo, _ := NewCOO[int](3, 3)
+	coo.Append(0, 0, 5)
+	coo.Append(2, 1, 2)
+	coo.Append(1, 2, 3)
+	coo.Append(1, 2, 4) // duplicates are summed
+	coo.Append(0, 1, 1)
+	coo.Append(0, 1, -1) // and zero sums dropped
+	want := mustMatrix(t, [][]int{{5, 0, 0}, {0, 0, 7}, {0, 2, 0}})
+
+	csr := coo.ToCSR()
+	// the example of the package doc
+	if !slices.Equal(csr.rowPtr, []int{0, 1, 2, 3}) || !slices.Equal(csr.colIdx, []int{0, 2, 1}) || !slices.Equal(csr.values, []int{5, 7, 2}) {
+		t.Errorf("CSR = %v %v %v", csr.rowPtr, csr.colIdx, csr.values)
+	}
+	csc := coo.ToCSC()
+	if csr.NNZ() != 3 || csc.NNZ() != 3 || coo.NNZ() != 6 {
+		t.Errorf("NNZ = %d (CSR), %d (CSC), %d (COO), want 3, 3, 6", csr.NNZ(), csc.NNZ(), coo.NNZ())
+	}
+
+	dense := map[string]*Matrix[int]{
+		"COO":        coo.ToDense(),
+		"CSR":        csr.ToDense(),
+		"CSC":        csc.ToDense(),
+		"CSR to CSC": csr.ToCSC().ToDense(),
+		"CSC to CSR": csc.ToCSR().ToDense(),
+		"CSR to COO": csr.ToCOO().ToDense(),
+		"from dense": CSRFromDense(want).ToDense(),
+		"COO dense":  COOFromDense(want).ToDense(),
+		"CSC dense":  CSCFromDense(want).ToDense(),
+	}
+	for name, got := range dense {
+		if !got.Equal(want) {
+			t.Errorf("%s: %v, want %v", name, got, want)
+		}
+	}
+
+	for row := range 3 {
+		for col := range 3 {
+			wantValue, _ := want.At(row, col)
+			if got, err := csr.At(row, col); err != nil || got != wantValue {
+				t.Errorf("CSR At(%d, %d) = %v, %v, want %v", row, col, got, err, wantValue)
+			}
+			if got, err := csc.At(row, col); err != nil || got != wantValue {
+				t.Errorf("CSC At(%d, %d) = %v, %v, want %v", row, col, got, err, wantValue)
+			}
+		}
+	}
+
+	if err := coo.Append(3, 0, 1); !errors.Is(err, ErrOutOfBounds) {
+		t.Errorf("Append(3, 0) error %v, want ErrOutOfBounds", err)
+	}
+	if _, err := csr.At(0, 3); !errors.Is(err, ErrOutOfBounds) {
+		t.Errorf("At(0, 3) error %v, want ErrOutOfBounds", err)
+	}
+	if _, err := NewCOO[int](-1, 1); !errors.Is(err, ErrShape) {
+		t.Errorf("NewCOO(-1, 1) error %v, want ErrShape", err)
+	}
+}
+
+func TestSparseMul(t *testing.T) {
+	a := mustMatrix(t, [][]int{{1, 0, 2}, {0, 0, 3}, {4, 5, 0}})
+	b := mustMatrix(t, [][]int{{0, 1}, {2, 0}, {0, -1}})
+	want, _ := a.Mul(b)
+
+	if got, err := CSRFromDense(a).MulDense(b); err != nil || !got.Equal(want) {
+		t.Errorf("CSR MulDense = %v, %v, want %v", got, err, want)
+	}
+	if got, err := DenseMul(a, CSCFromDense(b)); err != nil || !got.Equal(want) {
+		t.Errorf("DenseMul = %v, %v, want %v", got, err, want)
+	}
+	if got, err := CSRFromDense(a).Mul(CSRFromDense(b)); err != nil || !got.ToDense().Equal(want) {
+		t.Errorf("CSR Mul = %v, %v, want %v", got, err, want)
+	}
+	if got, err := CSCFromDense(a).Mul(CSCFromDense(b)); err != nil || !got.ToDense().Equal(want) {
+		t.Errorf("CSC Mul = %v, %v, want %v", got, err, want)
+	}
+	if got, err := CSRFromDense(a).MulVec([]int{1, 1, 1}); err != nil || !slices.Equal(got, []int{3, 3, 9}) {
+		t.Errorf("MulVec = %v, %v, want [3 3 9]", got, err)
+	}
+
+	// products that cancel out are not stored
+	c := mustMatrix(t, [][]int{{1, 1}})
+	d := mustMatrix(t, [][]int{{1}, {-1}})
+	if got, _ := CSRFromDense(c).Mul(CSRFromDense(d)); got.NNZ() != 0 {
+		t.Errorf("Mul stored %d entries, want 0", got.NNZ())
+	}
+
+	if _, err := CSRFromDense(a).MulDense(c); !errors.Is(err, ErrShape) {
+		t.Errorf("MulDense error %v, want ErrShape", err)
+	}
+	if _, err := CSRFromDense(a).MulVec([]int{1}); !errors.Is(err, ErrShape) {
+		t.Errorf("MulVec error %v, want ErrShape", err)
+	}
+
+	// a dense product too large to allocate
+	column, _ := NewCOO[int](1<<21, 1)
+	row, _ := NewCOO[int](1, 1<<20)
+	rowDense, _ := Zeros[int](1, 1<<20)
+	columnDense, _ := Zeros[int](1<<21, 1)
+	if _, err := column.ToCSR().MulDense(rowDense); !errors.Is(err, ErrShape) {
+		t.Errorf("MulDense with a 2^41 elements product error %v, want ErrShape", err)
+	}
+	if _, err := DenseMul(columnDense, row.ToCSC()); !errors.Is(err, ErrShape) {
+		t.Errorf("DenseMul with a 2^41 elements product error %v, want ErrShape", err)
+	}
+}
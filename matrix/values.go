@@ -0,0 +1,86 @@
+package matrix
+
+import (
+	mathutil "first/mathUtil"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// helpers shared by the readers and writers to convert a single element
+// to and from text, whatever the element type of the matrix is
+// they go through reflect so that types like `type celsius float64` work too
+
+// valueKind groups the element types the way text formats care about them
+type valueKind int
+
+const (
+	kindInteger valueKind = iota
+	kindUnsigned
+	kindReal
+	kindComplex
+)
+
+func kindOf[T mathutil.Number]() valueKind {
+	var zero T
+	switch reflect.TypeOf(zero).Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return kindInteger
+	case reflect.Float32, reflect.Float64:
+		return kindReal
+	case reflect.Complex64, reflect.Complex128:
+		return kindComplex
+	}
+	return kindUnsigned
+}
+
+// parseValue parses text as a T, rejecting values that don't fit in T
+func parseValue[T mathutil.Number](text string) (T, error) {
+	var value T
+	target := reflect.ValueOf(&value).Elem()
+	text = strings.TrimSpace(text)
+	bits := target.Type().Bits()
+
+	switch kindOf[T]() {
+	case kindInteger:
+		parsed, err := strconv.ParseInt(text, 10, bits)
+		if err != nil {
+			return value, fmt.Errorf("invalid %s %q", target.Type(), text)
+		}
+		target.SetInt(parsed)
+	case kindUnsigned:
+		parsed, err := strconv.ParseUint(text, 10, bits)
+		if err != nil {
+			return value, fmt.Errorf("invalid %s %q", target.Type(), text)
+		}
+		target.SetUint(parsed)
+	case kindReal:
+		parsed, err := strconv.ParseFloat(text, bits)
+		if err != nil {
+			return value, fmt.Errorf("invalid %s %q", target.Type(), text)
+		}
+		target.SetFloat(parsed)
+	case kindComplex:
+		parsed, err := strconv.ParseComplex(text, bits)
+		if err != nil {
+			return value, fmt.Errorf("invalid %s %q", target.Type(), text)
+		}
+		target.SetComplex(parsed)
+	}
+	return value, nil
+}
+
+// formatValue returns the shortest text that parseValue turns back into value
+func formatValue[T mathutil.Number](value T) string {
+	source := reflect.ValueOf(value)
+	switch kindOf[T]() {
+	case kindInteger:
+		return strconv.FormatInt(source.Int(), 10)
+	case kindUnsigned:
+		return strconv.FormatUint(source.Uint(), 10)
+	case kindReal:
+		return strconv.FormatFloat(source.Float(), 'g', -1, source.Type().Bits())
+	}
+	return strconv.FormatComplex(source.Complex(), 'g', -1, source.Type().Bits())
+}
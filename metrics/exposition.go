@@ -0,0 +1,166 @@
+package metrics
+
+import (
+	"io"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+/*
+
+The Prometheus text exposition format, version 0.0.4:
+
+	# HELP http_requests_total Requests served.
+	# TYPE http_requests_total counter
+	http_requests_total{method="GET",code="200"} 1027
+	http_requests_total{method="GET",code="500"} 3
+	# HELP request_duration_seconds Time spent serving requests.
+	# TYPE request_duration_seconds histogram
+	request_duration_seconds_bucket{le="0.1"} 8
+	request_duration_seconds_bucket{le="+Inf"} 9
+	request_duration_seconds_sum 1.37
+	request_duration_seconds_count 9
+
+The families are written sorted by name and their metrics sorted by label
+values, with the labels in the order the family declared them, so two scrapes
+of the same state give the same text. Backslashes and newlines are escaped in
+help texts, and double quotes too in label values.
+
+*/
+
+// ContentType is the media type of the text exposition format
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// WriteText writes every registered family in the text exposition format
+func (registry *Registry) WriteText(w io.Writer) error {
+	registry.mu.RLock()
+	families := make([]family, 0, len(registry.families))
+	for _, family := range registry.families {
+		families = append(families, family)
+	}
+	registry.mu.RUnlock()
+	slices.SortFunc(families, func(a, b family) int {
+		return strings.Compare(a.definition().name, b.definition().name)
+	})
+
+	var builder strings.Builder
+	for _, family := range families {
+		family.write(&builder)
+	}
+	_, err := io.WriteString(w, builder.String())
+	return err
+}
+
+// Handler returns an http.Handler serving the metrics for a Prometheus scrape
+func (registry *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", ContentType)
+		if r.Method == http.MethodHead {
+			return
+		}
+		// the text is built in memory first, so an error here can only come
+		// from the connection and there's no one left to report it to
+		_ = registry.WriteText(w)
+	})
+}
+
+func (family *Family[M]) write(builder *strings.Builder) {
+	family.mu.RLock()
+	entries := make([]labeled[M], 0, len(family.metrics))
+	for _, entry := range family.metrics {
+		entries = append(entries, entry)
+	}
+	family.mu.RUnlock()
+	if len(entries) == 0 {
+		return
+	}
+	slices.SortFunc(entries, func(a, b labeled[M]) int {
+		return slices.Compare(a.values, b.values)
+	})
+
+	def := &family.def
+	if def.help != "" {
+		builder.WriteString("# HELP " + def.name + " " + escapeHelp(def.help) + "\n")
+	}
+	builder.WriteString("# TYPE " + def.name + " " + string(def.kind) + "\n")
+	for _, entry := range entries {
+		labels := formatLabels(def.labels, entry.values)
+		switch metric := any(entry.metric).(type) {
+		case *Counter:
+			writeSample(builder, def.name, labels, metric.Value())
+		case *Gauge:
+			writeSample(builder, def.name, labels, metric.Value())
+		case *Histogram:
+			upperBounds, counts := metric.Buckets()
+			for index, bound := range upperBounds {
+				bucketLabels := formatLabels(append(slices.Clone(def.labels), "le"),
+					append(slices.Clone(entry.values), formatFloat(bound)))
+				writeSample(builder, def.name+"_bucket", bucketLabels, float64(counts[index]))
+			}
+			writeSample(builder, def.name+"_sum", labels, metric.Sum())
+			// the +Inf bucket already holds the count, and it is consistent
+			// with the buckets even when an observation lands while writing
+			writeSample(builder, def.name+"_count", labels, float64(counts[len(counts)-1]))
+		}
+	}
+}
+
+func writeSample(builder *strings.Builder, name, labels string, value float64) {
+	builder.WriteString(name)
+	builder.WriteString(labels)
+	builder.WriteByte(' ')
+	builder.WriteString(formatFloat(value))
+	builder.WriteByte('\n')
+}
+
+// formatLabels returns {name="value",...}, or "" without labels
+func formatLabels(names, values []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	var builder strings.Builder
+	builder.WriteByte('{')
+	for index, name := range names {
+		if index > 0 {
+			builder.WriteByte(',')
+		}
+		builder.WriteString(name + `="` + escapeLabelValue(values[index]) + `"`)
+	}
+	builder.WriteByte('}')
+	return builder.String()
+}
+
+var (
+	helpEscaper       = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelValueEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(help string) string {
+	return helpEscaper.Replace(help)
+}
+
+func escapeLabelValue(value string) string {
+	return labelValueEscaper.Replace(value)
+}
+
+// formatFloat writes the shortest text for value, with the spelling of
+// the special values Prometheus expects
+func formatFloat(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
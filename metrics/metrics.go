@@ -0,0 +1,168 @@
+package metrics
+
+import (
+	"math"
+	"slices"
+	"sync/atomic"
+	"time"
+)
+
+/*
+
+The three kinds of metrics of the Prometheus data model:
+
+	Counter    a total that only goes up: requests served, bytes sent
+	Gauge      a value that goes up and down: requests in flight, temperature
+	Histogram  observations counted in buckets: request durations, sizes
+
+Like counters.Counter, they are safe for concurrent use and never take a lock:
+the float64 values are updated with compare-and-swap on their bits.
+
+Metrics are not created directly but through a Family of a Registry, one
+metric per combination of label values:
+
+	requests, _ := registry.Counter("http_requests_total", "Requests served.", "method", "code")
+	ok, _ := requests.With("GET", "200")
+	ok.Inc()
+
+*/
+
+// atomicFloat is a float64 updated atomically through its bits
+type atomicFloat struct {
+	bits atomic.Uint64
+}
+
+func (value *atomicFloat) Load() float64 {
+	return math.Float64frombits(value.bits.Load())
+}
+
+func (value *atomicFloat) Store(f float64) {
+	value.bits.Store(math.Float64bits(f))
+}
+
+func (value *atomicFloat) Add(delta float64) {
+	for {
+		old := value.bits.Load()
+		next := math.Float64bits(math.Float64frombits(old) + delta)
+		if value.bits.CompareAndSwap(old, next) {
+			return
+		}
+	}
+}
+
+type Counter struct {
+	value atomicFloat
+}
+
+// Inc adds 1 to the counter
+func (counter *Counter) Inc() {
+	counter.value.Add(1)
+}
+
+// Add adds delta to the counter, counters only go up so a negative delta
+// (or NaN) fails with ErrNegative
+func (counter *Counter) Add(delta float64) error {
+	if !(delta >= 0) {
+		return ErrNegative
+	}
+	counter.value.Add(delta)
+	return nil
+}
+
+func (counter *Counter) Value() float64 {
+	return counter.value.Load()
+}
+
+type Gauge struct {
+	value atomicFloat
+}
+
+func (gauge *Gauge) Set(value float64) {
+	gauge.value.Store(value)
+}
+
+func (gauge *Gauge) Inc() {
+	gauge.value.Add(1)
+}
+
+func (gauge *Gauge) Dec() {
+	gauge.value.Add(-1)
+}
+
+func (gauge *Gauge) Add(delta float64) {
+	gauge.value.Add(delta)
+}
+
+func (gauge *Gauge) Sub(delta float64) {
+	gauge.value.Add(-delta)
+}
+
+// SetToCurrentTime sets the gauge to the current Unix time in seconds
+func (gauge *Gauge) SetToCurrentTime() {
+	gauge.value.Store(float64(time.Now().UnixNano()) / 1e9)
+}
+
+func (gauge *Gauge) Value() float64 {
+	return gauge.value.Load()
+}
+
+// DefaultBuckets suit request durations in seconds, from 5ms to 10s
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+type Histogram struct {
+	// upperBounds are the sorted bucket bounds, without the implicit +Inf
+	upperBounds []float64
+	// counts[i] counts the observations in (upperBounds[i-1], upperBounds[i]],
+	// the last one those above every bound; they are summed up when written
+	counts []atomic.Uint64
+	sum    atomicFloat
+	count  atomic.Uint64
+}
+
+func newHistogram(upperBounds []float64) *Histogram {
+	return &Histogram{
+		upperBounds: upperBounds,
+		counts:      make([]atomic.Uint64, len(upperBounds)+1),
+	}
+}
+
+// Observe adds value to the histogram
+func (histogram *Histogram) Observe(value float64) {
+	// the first bound >= value, len(upperBounds) for the +Inf bucket; NaN
+	// compares below every bound for BinarySearch but belongs in +Inf only
+	bucket := len(histogram.upperBounds)
+	if !math.IsNaN(value) {
+		bucket, _ = slices.BinarySearch(histogram.upperBounds, value)
+	}
+	histogram.counts[bucket].Add(1)
+	histogram.sum.Add(value)
+	histogram.count.Add(1)
+}
+
+// ObserveDuration adds the time elapsed since start, in seconds
+func (histogram *Histogram) ObserveDuration(start time.Time) {
+	histogram.Observe(time.Since(start).Seconds())
+}
+
+// Count returns the number of observations
+func (histogram *Histogram) Count() uint64 {
+	return histogram.count.Load()
+}
+
+// Sum returns the sum of the observations
+func (histogram *Histogram) Sum() float64 {
+	return histogram.sum.Load()
+}
+
+// Buckets returns the upper bounds of the buckets with the cumulative count of
+// observations less than or equal to them, the last bound is +Inf
+func (histogram *Histogram) Buckets() (upperBounds []float64, counts []uint64) {
+	upperBounds = append(slices.Clone(histogram.upperBounds), math.Inf(1))
+	counts = make([]uint64, len(histogram.counts))
+	var total uint64
+	for index := range histogram.counts {
+		total += histogram.counts[index].Load()
+		counts[index] = total
+	}
+	return upperBounds, counts
+}
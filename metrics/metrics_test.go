@@ -0,0 +1,224 @@
+package metrics
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestWriteText(t *testing.T) {
+	registry := NewRegistry()
+	requests, _ := registry.Counter("http_requests_total", "Requests served.", "method", "code")
+	durations, _ := registry.Histogram("request_duration_seconds", "Time spent\nserving requests.", []float64{0.1, 1})
+	inFlight, _ := registry.Gauge("in_flight", "")
+	registry.Counter("errors_total", `Errors, by \ kind.`)
+
+	for range 1027 {
+		counter, _ := requests.With("GET", "200")
+		counter.Inc()
+	}
+	counter, _ := requests.With("GET", "500")
+	counter.Add(3)
+	counter, _ = requests.With(`say "hi"`, "200")
+	counter.Inc()
+
+	histogram, _ := durations.With()
+	for _, value := range []float64{0.05, 0.1, 0.5, 2, math.NaN()} {
+		histogram.Observe(value)
+	}
+	gauge, _ := inFlight.With()
+	gauge.Set(-2.5)
+
+	want := `# HELP errors_total Errors, by \\ kind.
+# TYPE errors_total counter
+errors_total 0
+# HELP http_requests_total Requests served.
+# TYPE http_requests_total counter
+http_requests_total{method="GET",code="200"} 1027
+http_requests_total{method="GET",code="500"} 3
+http_requests_total{method="say \"hi\"",code="200"} 1
+# TYPE in_flight gauge
+in_flight -2.5
+# HELP request_duration_seconds Time spent\nserving requests.
+# TYPE request_duration_seconds histogram
+request_duration_seconds_bucket{le="0.1"} 2
+request_duration_seconds_bucket{le="1"} 3
+request_duration_seconds_bucket{le="+Inf"} 5
+request_duration_seconds_sum NaN
+request_duration_seconds_count 5
+`
+	var builder strings.Builder
+	if err := registry.WriteText(&builder); err != nil {
+		t.Fatal(err)
+	}
+	if builder.String() != want {
+		t.Errorf("WriteText:\n%s\nwant:\n%s", builder.String(), want)
+	}
+}
+
+func TestHistogramBuckets(t *testing.T) {
+	registry := NewRegistry()
+	family, _ := registry.Histogram("h", "", []float64{1, 2, math.Inf(1)})
+	histogram, _ := family.With()
+	tests := []struct {
+		value  float64
+		counts []uint64
+	}{
+		{1, []uint64{1, 1, 1}}, // a bound is inclusive
+		{1.5, []uint64{1, 2, 2}},
+		{math.Inf(1), []uint64{1, 2, 3}},
+		{math.Inf(-1), []uint64{2, 3, 4}},
+		{math.NaN(), []uint64{2, 3, 5}}, // +Inf only
+	}
+	for _, test := range tests {
+		histogram.Observe(test.value)
+		bounds, counts := histogram.Buckets()
+		if len(bounds) != 3 || !math.IsInf(bounds[2], 1) {
+			t.Fatalf("Buckets bounds = %v, want [1 2 +Inf]", bounds)
+		}
+		for index := range counts {
+			if counts[index] != test.counts[index] {
+				t.Errorf("after Observe(%v), Buckets counts = %v, want %v", test.value, counts, test.counts)
+				break
+			}
+		}
+	}
+	if histogram.Count() != 5 {
+		t.Errorf("Count = %d, want 5", histogram.Count())
+	}
+}
+
+func TestUnlabeledFamilies(t *testing.T) {
+	registry := NewRegistry()
+	family, _ := registry.Gauge("temperature", "")
+	var builder strings.Builder
+	registry.WriteText(&builder)
+	if !strings.Contains(builder.String(), "temperature 0\n") {
+		t.Errorf("a new unlabeled family isn't exposed at 0:\n%s", builder.String())
+	}
+
+	gauge, _ := family.With()
+	gauge.Set(21)
+	family.Reset()
+	builder.Reset()
+	registry.WriteText(&builder)
+	if !strings.Contains(builder.String(), "temperature 0\n") {
+		t.Errorf("a reset unlabeled family isn't exposed at 0:\n%s", builder.String())
+	}
+	if again, _ := family.With(); again == gauge {
+		t.Error("Reset kept the old gauge")
+	}
+
+	// a labeled family only shows up once it has metrics
+	labeled, _ := registry.Counter("hits_total", "", "path")
+	builder.Reset()
+	registry.WriteText(&builder)
+	if strings.Contains(builder.String(), "hits_total") {
+		t.Errorf("an empty labeled family is exposed:\n%s", builder.String())
+	}
+	labeled.With("/")
+	if !labeled.Delete("/") || labeled.Delete("/") {
+		t.Error("Delete doesn't report whether the metric existed")
+	}
+}
+
+func TestRegister(t *testing.T) {
+	registry := NewRegistry()
+	first, err := registry.Counter("jobs_total", "Jobs.", "queue")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again, err := registry.Counter("jobs_total", "Jobs.", "queue"); err != nil || again != first {
+		t.Errorf("registering the same counter twice = %p, %v, want %p", again, err, first)
+	}
+
+	tests := []struct {
+		name     string
+		register func() error
+		want     error
+	}{
+		{"other help", func() error { _, err := registry.Counter("jobs_total", "Other.", "queue"); return err }, ErrConflict},
+		{"other labels", func() error { _, err := registry.Counter("jobs_total", "Jobs."); return err }, ErrConflict},
+		{"other type", func() error { _, err := registry.Gauge("jobs_total", "Jobs.", "queue"); return err }, ErrConflict},
+		{"empty name", func() error { _, err := registry.Counter("", ""); return err }, ErrInvalidName},
+		{"leading digit", func() error { _, err := registry.Counter("1x", ""); return err }, ErrInvalidName},
+		{"dash", func() error { _, err := registry.Counter("a-b", ""); return err }, ErrInvalidName},
+		{"colon label", func() error { _, err := registry.Counter("a", "", "b:c"); return err }, ErrInvalidName},
+		{"reserved label", func() error { _, err := registry.Counter("a", "", "__x"); return err }, ErrInvalidName},
+		{"duplicate label", func() error { _, err := registry.Counter("a", "", "x", "x"); return err }, ErrInvalidName},
+		{"le label", func() error { _, err := registry.Histogram("a", "", nil, "le"); return err }, ErrInvalidName},
+		{"unsorted buckets", func() error { _, err := registry.Histogram("a", "", []float64{1, 1}); return err }, ErrInvalidBound},
+		{"NaN bucket", func() error { _, err := registry.Histogram("a", "", []float64{math.NaN()}); return err }, ErrInvalidBound},
+	}
+	for _, test := range tests {
+		if err := test.register(); !errors.Is(err, test.want) {
+			t.Errorf("%s: error %v, want %v", test.name, err, test.want)
+		}
+	}
+	if _, err := registry.Counter("colons:are:fine", ""); err != nil {
+		t.Errorf("colons in a metric name: %v", err)
+	}
+
+	if _, err := first.With(); !errors.Is(err, ErrLabelValues) {
+		t.Errorf("With() on a labeled family error %v, want ErrLabelValues", err)
+	}
+	if _, err := first.With("\xff"); !errors.Is(err, ErrLabelValues) {
+		t.Errorf("With(invalid UTF-8) error %v, want ErrLabelValues", err)
+	}
+	if !registry.Unregister("jobs_total") || registry.Unregister("jobs_total") {
+		t.Error("Unregister doesn't report whether the family existed")
+	}
+}
+
+func TestCounter(t *testing.T) {
+	var counter Counter
+	for _, delta := range []float64{-1, math.NaN()} {
+		if err := counter.Add(delta); !errors.Is(err, ErrNegative) {
+			t.Errorf("Add(%v) error %v, want ErrNegative", delta, err)
+		}
+	}
+
+	var wait sync.WaitGroup
+	for range 8 {
+		wait.Add(1)
+		go func() {
+			defer wait.Done()
+			for range 1000 {
+				counter.Add(0.5)
+			}
+		}()
+	}
+	wait.Wait()
+	if counter.Value() != 4000 {
+		t.Errorf("Value = %v after concurrent adds, want 4000", counter.Value())
+	}
+}
+
+func TestHandler(t *testing.T) {
+	registry := NewRegistry()
+	registry.Counter("up", "")
+	handler := registry.Handler()
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if recorder.Code != http.StatusOK || recorder.Header().Get("Content-Type") != ContentType ||
+		recorder.Body.String() != "# TYPE up counter\nup 0\n" {
+		t.Errorf("GET = %d %q %q", recorder.Code, recorder.Header().Get("Content-Type"), recorder.Body.String())
+	}
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodHead, "/metrics", nil))
+	if recorder.Code != http.StatusOK || recorder.Body.Len() != 0 {
+		t.Errorf("HEAD = %d %q", recorder.Code, recorder.Body.String())
+	}
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/metrics", nil))
+	if recorder.Code != http.StatusMethodNotAllowed || recorder.Header().Get("Allow") != "GET, HEAD" {
+		t.Errorf("POST = %d, Allow %q", recorder.Code, recorder.Header().Get("Allow"))
+	}
+}
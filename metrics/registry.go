@@ -0,0 +1,232 @@
+package metrics
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+var (
+	ErrInvalidName  = errors.New("metrics: invalid metric or label name")
+	ErrConflict     = errors.New("metrics: metric already registered with a different definition")
+	ErrLabelValues  = errors.New("metrics: invalid label values")
+	ErrNegative     = errors.New("metrics: counters can only increase")
+	ErrInvalidBound = errors.New("metrics: histogram buckets must be increasing")
+)
+
+// kind is the metric type, as written in the # TYPE line
+type kind string
+
+const (
+	kindCounter   kind = "counter"
+	kindGauge     kind = "gauge"
+	kindHistogram kind = "histogram"
+)
+
+// Registry holds metric families by name, and writes them all for a scrape
+// registering the same family twice gives back the first one, as long as the
+// definitions match, so packages can register their metrics without coordinating
+type Registry struct {
+	mu       sync.RWMutex
+	families map[string]family
+}
+
+// family is what a Registry needs from a Family, whatever its metric type
+type family interface {
+	definition() *definition
+	write(builder *strings.Builder)
+}
+
+type definition struct {
+	name    string
+	help    string
+	kind    kind
+	labels  []string
+	buckets []float64
+}
+
+func (def *definition) matches(other *definition) bool {
+	return def.kind == other.kind && def.help == other.help &&
+		slices.Equal(def.labels, other.labels) && slices.Equal(def.buckets, other.buckets)
+}
+
+/*
+Family is a set of metrics sharing a name and label names, one metric per
+combination of label values. A family without labels holds a single metric,
+created with the family and returned by With().
+*/
+type Family[M any] struct {
+	def       definition
+	newMetric func() M
+
+	mu      sync.RWMutex
+	metrics map[string]labeled[M] // keyed by the joined label values
+}
+
+type labeled[M any] struct {
+	values []string
+	metric M
+}
+
+func NewRegistry() *Registry {
+	return &Registry{families: make(map[string]family)}
+}
+
+// Counter registers a family of counters
+func (registry *Registry) Counter(name, help string, labels ...string) (*Family[*Counter], error) {
+	def := definition{name: name, help: help, kind: kindCounter, labels: labels}
+	return register(registry, def, func() *Counter { return &Counter{} })
+}
+
+// Gauge registers a family of gauges
+func (registry *Registry) Gauge(name, help string, labels ...string) (*Family[*Gauge], error) {
+	def := definition{name: name, help: help, kind: kindGauge, labels: labels}
+	return register(registry, def, func() *Gauge { return &Gauge{} })
+}
+
+// Histogram registers a family of histograms with the given bucket upper bounds,
+// DefaultBuckets when buckets is nil; the +Inf bucket is always added
+func (registry *Registry) Histogram(name, help string, buckets []float64, labels ...string) (*Family[*Histogram], error) {
+	if buckets == nil {
+		buckets = DefaultBuckets
+	}
+	for index, bound := range buckets {
+		if math.IsNaN(bound) || (index > 0 && bound <= buckets[index-1]) {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidBound, buckets)
+		}
+	}
+	// +Inf is implicit, don't count it twice
+	if len(buckets) > 0 && math.IsInf(buckets[len(buckets)-1], 1) {
+		buckets = buckets[:len(buckets)-1]
+	}
+	buckets = slices.Clone(buckets)
+
+	for _, label := range labels {
+		if label == "le" {
+			return nil, fmt.Errorf("%w: label %q is reserved for histogram buckets", ErrInvalidName, label)
+		}
+	}
+	def := definition{name: name, help: help, kind: kindHistogram, labels: labels, buckets: buckets}
+	return register(registry, def, func() *Histogram { return newHistogram(buckets) })
+}
+
+func register[M any](registry *Registry, def definition, newMetric func() M) (*Family[M], error) {
+	if !validName(def.name, true) {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidName, def.name)
+	}
+	for index, label := range def.labels {
+		if !validName(label, false) || strings.HasPrefix(label, "__") || slices.Contains(def.labels[:index], label) {
+			return nil, fmt.Errorf("%w: label %q", ErrInvalidName, label)
+		}
+	}
+	def.labels = slices.Clone(def.labels)
+
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	if existing, ok := registry.families[def.name]; ok {
+		family, sameType := existing.(*Family[M])
+		if !sameType || !existing.definition().matches(&def) {
+			return nil, fmt.Errorf("%w: %q", ErrConflict, def.name)
+		}
+		return family, nil
+	}
+
+	family := &Family[M]{def: def, newMetric: newMetric, metrics: make(map[string]labeled[M])}
+	if len(def.labels) == 0 {
+		// exposed at zero from the start, like Prometheus clients do, rather
+		// than only once With has been called
+		family.With()
+	}
+	registry.families[def.name] = family
+	return family, nil
+}
+
+// validName reports whether name is a valid metric name (colons allowed) or label name
+func validName(name string, metric bool) bool {
+	if name == "" {
+		return false
+	}
+	for index, r := range name {
+		switch {
+		case r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z'):
+		case r == ':' && metric:
+		case r >= '0' && r <= '9' && index > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// Unregister removes the family called name, it reports whether there was one
+func (registry *Registry) Unregister(name string) bool {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	_, ok := registry.families[name]
+	delete(registry.families, name)
+	return ok
+}
+
+// With returns the metric for the given label values, in the order of the
+// label names of the family, creating it the first time
+func (family *Family[M]) With(values ...string) (M, error) {
+	if len(values) != len(family.def.labels) {
+		var zero M
+		return zero, fmt.Errorf("%w: %q has %d labels, got %d values",
+			ErrLabelValues, family.def.name, len(family.def.labels), len(values))
+	}
+	// label values can be any UTF-8 text, but not invalid UTF-8, so 0xff never
+	// shows up in them and can separate them unambiguously
+	for _, value := range values {
+		if !utf8.ValidString(value) {
+			var zero M
+			return zero, fmt.Errorf("%w: %q is not valid UTF-8", ErrLabelValues, value)
+		}
+	}
+	key := strings.Join(values, "\xff")
+
+	family.mu.RLock()
+	entry, ok := family.metrics[key]
+	family.mu.RUnlock()
+	if ok {
+		return entry.metric, nil
+	}
+
+	family.mu.Lock()
+	defer family.mu.Unlock()
+	if entry, ok := family.metrics[key]; ok {
+		return entry.metric, nil
+	}
+	entry = labeled[M]{values: slices.Clone(values), metric: family.newMetric()}
+	family.metrics[key] = entry
+	return entry.metric, nil
+}
+
+// Delete removes the metric with the given label values, it reports whether there was one
+func (family *Family[M]) Delete(values ...string) bool {
+	key := strings.Join(values, "\xff")
+	family.mu.Lock()
+	defer family.mu.Unlock()
+	_, ok := family.metrics[key]
+	delete(family.metrics, key)
+	return ok
+}
+
+// Reset removes every metric of the family; a family without labels is left
+// with its single metric back at zero
+func (family *Family[M]) Reset() {
+	family.mu.Lock()
+	defer family.mu.Unlock()
+	clear(family.metrics)
+	if len(family.def.labels) == 0 {
+		family.metrics[""] = labeled[M]{metric: family.newMetric()}
+	}
+}
+
+func (family *Family[M]) definition() *definition {
+	return &family.def
+}
@@ -0,0 +1,60 @@
+package money
+
+import "first/numfmt"
+
+/*
+
+Locale describes how an amount is written for the readers of a region:
+
+	LocaleUS   $1,234,567.89     -$1,234,567.89
+	LocaleDE   1.234.567,89 €    -1.234.567,89 €
+	LocaleFR   1 234 567,89 €    (narrow no-break spaces between the groups)
+	LocaleCH   CHF 1’234’567.89
+	LocaleIN   ₹12,34,567.89     (lakh and crore grouping)
+
+Any other numfmt.System works too, numfmt.Devanagari for instance.
+The symbol comes from the currency, not from the locale: 1234.5 EUR is
+€1,234.50 with LocaleUS. Currencies without a known symbol use their code.
+
+*/
+
+// Locale is a numfmt.System, which holds the separators, the grouping and the
+// placement of the symbol
+type Locale = numfmt.System
+
+var (
+	LocaleUS = numfmt.Western
+	LocaleDE = numfmt.German
+	LocaleFR = numfmt.French
+	LocaleCH = numfmt.Swiss
+	LocaleIN = numfmt.Indian
+)
+
+var symbols = map[string]string{
+	"USD": "$",
+	"EUR": "€",
+	"GBP": "£",
+	"JPY": "¥",
+	"INR": "₹",
+	"KRW": "₩",
+	"CNY": "¥",
+	"RUB": "₽",
+	"ILS": "₪",
+	"NGN": "₦",
+	"VND": "₫",
+}
+
+// Symbol returns the symbol of currency, or the currency code itself when it has none
+func Symbol(currency string) string {
+	if symbol, ok := symbols[currency]; ok {
+		return symbol
+	}
+	return currency
+}
+
+// Format writes m with the separators, grouping and symbol placement of locale
+func (m Money) Format(locale Locale) string {
+	// Decimal always writes a valid plain decimal, FormatCurrency can't fail on it
+	formatted, _ := numfmt.FormatCurrency(m.Decimal(), Symbol(m.currency), locale)
+	return formatted
+}
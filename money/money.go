@@ -0,0 +1,507 @@
+package money
+
+import (
+	"errors"
+	mathutil "first/mathUtil"
+	"first/rational"
+	"fmt"
+	"math"
+	"math/bits"
+	"strconv"
+	"strings"
+)
+
+/*
+
+Money is a fixed-point decimal amount in a given currency. It stores an integer
+number of minor units together with the number of decimal digits they stand for:
+
+	amount  scale  value
+	1234    2      12.34
+	1234    3      1.234
+	1234    0      1234
+
+so 0.01 * 3 is exactly 0.03, where the float64 version in main ends up as
+0.030000000000000002 and only looks right because %0.3f rounds it away.
+
+The currency is an ISO 4217 code ("USD", "EUR", "INR", ...). Amounts in
+different currencies never mix: Add, Sub and Cmp fail with ErrCurrencyMismatch.
+Amounts with different scales do mix, the result has the larger scale.
+
+Operations that lose digits (a smaller scale, a division, a multiplication by
+a fraction) take a RoundingMode:
+
+	RoundHalfEven  ties go to the even neighbour, 0.125 -> 0.12, 0.135 -> 0.14
+	               (banker's rounding, unbiased over many operations)
+	RoundHalfUp    ties go away from zero, 0.125 -> 0.13, -0.125 -> -0.13
+
+Money values are immutable and comparable with == when they have the same scale.
+Results that don't fit in int64 minor units fail with mathutil.ErrOverflow.
+
+*/
+
+var (
+	ErrInvalidCurrency  = errors.New("money: invalid currency code")
+	ErrInvalidScale     = errors.New("money: invalid scale")
+	ErrCurrencyMismatch = errors.New("money: currency mismatch")
+	ErrInvalidRatios    = errors.New("money: invalid allocation ratios")
+	ErrSyntax           = errors.New("money: invalid syntax")
+)
+
+// MaxScale is the largest supported scale, 10^18 is the largest power of ten in an int64
+const MaxScale = 18
+
+type RoundingMode int
+
+const (
+	RoundHalfEven RoundingMode = iota
+	RoundHalfUp
+)
+
+func (mode RoundingMode) String() string {
+	switch mode {
+	case RoundHalfEven:
+		return "half-even"
+	case RoundHalfUp:
+		return "half-up"
+	}
+	return fmt.Sprintf("RoundingMode(%d)", int(mode))
+}
+
+type Money struct {
+	amount   int64
+	scale    int
+	currency string
+}
+
+// defaultScales lists the currencies whose minor unit isn't a hundredth
+var defaultScales = map[string]int{
+	"JPY": 0, "KRW": 0, "VND": 0, "CLP": 0, "ISK": 0,
+	"BHD": 3, "KWD": 3, "OMR": 3, "JOD": 3, "TND": 3,
+}
+
+// DefaultScale returns the number of decimal digits of the minor unit of currency,
+// 2 for most currencies
+func DefaultScale(currency string) int {
+	if scale, ok := defaultScales[currency]; ok {
+		return scale
+	}
+	return 2
+}
+
+func checkCurrency(currency string) error {
+	if len(currency) != 3 || strings.Trim(currency, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") != "" {
+		return fmt.Errorf("%w: %q", ErrInvalidCurrency, currency)
+	}
+	return nil
+}
+
+func checkScale(scale int) error {
+	if scale < 0 || scale > MaxScale {
+		return fmt.Errorf("%w: %d not in [0, %d]", ErrInvalidScale, scale, MaxScale)
+	}
+	return nil
+}
+
+// New returns amount minor units of currency with the given scale:
+// New("USD", 1234, 2) is 12.34 USD
+func New(currency string, amount int64, scale int) (Money, error) {
+	if err := checkCurrency(currency); err != nil {
+		return Money{}, err
+	}
+	if err := checkScale(scale); err != nil {
+		return Money{}, err
+	}
+	return Money{amount: amount, scale: scale, currency: currency}, nil
+}
+
+// Zero returns 0 in currency, with the default scale of the currency
+func Zero(currency string) (Money, error) {
+	return New(currency, 0, DefaultScale(currency))
+}
+
+/*
+Parse parses a plain decimal number such as "12.34", "-0.5" or "1000" as an
+amount of currency with the given scale. Digits beyond the scale are rounded
+with mode. Grouping separators and currency symbols are not accepted: Parse
+reads what Decimal writes, not what Format writes.
+*/
+func Parse(currency, text string, scale int, mode RoundingMode) (Money, error) {
+	if err := checkCurrency(currency); err != nil {
+		return Money{}, err
+	}
+	if err := checkScale(scale); err != nil {
+		return Money{}, err
+	}
+
+	negative := strings.HasPrefix(text, "-")
+	unsigned := strings.TrimLeft(text, "+-")
+	intPart, fraction, _ := strings.Cut(unsigned, ".")
+	if len(text)-len(unsigned) > 1 || intPart == "" ||
+		strings.Trim(intPart, "0123456789") != "" || strings.Trim(fraction, "0123456789") != "" {
+		return Money{}, fmt.Errorf("%w: %q", ErrSyntax, text)
+	}
+
+	// split the fraction into the kept digits and the ones that are rounded away
+	kept, dropped := fraction, ""
+	if len(fraction) > scale {
+		kept, dropped = fraction[:scale], fraction[scale:]
+	}
+	kept += strings.Repeat("0", scale-len(kept))
+
+	var units uint64
+	if digits := strings.TrimLeft(intPart+kept, "0"); digits != "" {
+		var err error
+		units, err = strconv.ParseUint(digits, 10, 64)
+		// anything above 2^63 doesn't fit in an int64 anyway, and this leaves
+		// room for the rounding increment below
+		if err != nil || units > 1<<63 {
+			return Money{}, mathutil.ErrOverflow
+		}
+	}
+
+	if dropped != "" {
+		rest := strings.TrimRight(dropped[1:], "0")
+		switch {
+		case dropped[0] > '5' || (dropped[0] == '5' && rest != ""):
+			units++
+		case dropped[0] == '5' && roundsTieUp(units, mode):
+			units++
+		}
+	}
+
+	amount, err := fromMagnitude(negative, units)
+	if err != nil {
+		return Money{}, err
+	}
+	return Money{amount: amount, scale: scale, currency: currency}, nil
+}
+
+// FromFloat converts value to an amount of currency with the given scale.
+// It starts from the shortest decimal that reads back as value, so 0.07 becomes
+// exactly 0.07 rather than 0.070000000000000006661...
+func FromFloat(currency string, value float64, scale int, mode RoundingMode) (Money, error) {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return Money{}, fmt.Errorf("%w: %v", ErrSyntax, value)
+	}
+	return Parse(currency, strconv.FormatFloat(value, 'f', -1, 64), scale, mode)
+}
+
+func (m Money) Currency() string {
+	return m.currency
+}
+
+func (m Money) Scale() int {
+	return m.scale
+}
+
+// Amount returns the value in minor units, 12.34 USD with scale 2 is 1234
+func (m Money) Amount() int64 {
+	return m.amount
+}
+
+// Sign returns -1, 0 or 1 depending on the sign of m
+func (m Money) Sign() int {
+	switch {
+	case m.amount < 0:
+		return -1
+	case m.amount > 0:
+		return 1
+	}
+	return 0
+}
+
+func (m Money) IsZero() bool {
+	return m.amount == 0
+}
+
+// Rational returns the exact value of m as a fraction
+func (m Money) Rational() (rational.Rational, error) {
+	return rational.New(m.amount, pow10[m.scale])
+}
+
+// pow10[n] is 10^n, for every valid scale
+var pow10 = func() (powers [MaxScale + 1]int64) {
+	powers[0] = 1
+	for n := 1; n <= MaxScale; n++ {
+		powers[n] = powers[n-1] * 10
+	}
+	return powers
+}()
+
+// magnitude returns |value| as a uint64, which also fits -MinInt64
+func magnitude(value int64) uint64 {
+	if value < 0 {
+		return uint64(-(value + 1)) + 1
+	}
+	return uint64(value)
+}
+
+// fromMagnitude returns ±value, or ErrOverflow if it doesn't fit in an int64
+func fromMagnitude(negative bool, value uint64) (int64, error) {
+	if negative {
+		if value > 1<<63 {
+			return 0, mathutil.ErrOverflow
+		}
+		return int64(-value), nil
+	}
+	if value > math.MaxInt64 {
+		return 0, mathutil.ErrOverflow
+	}
+	return int64(value), nil
+}
+
+// roundsTieUp reports whether a tie after quotient rounds away from zero
+func roundsTieUp(quotient uint64, mode RoundingMode) bool {
+	return mode == RoundHalfUp || quotient%2 == 1
+}
+
+// quoRound returns ±(hi, lo) / den rounded with mode, where (hi, lo) is a 128-bit magnitude
+func quoRound(negative bool, hi, lo, den uint64, mode RoundingMode) (int64, error) {
+	if hi >= den {
+		return 0, mathutil.ErrOverflow
+	}
+	quotient, remainder := bits.Div64(hi, lo, den)
+	// compare remainder with den/2 without computing 2*remainder, which can overflow
+	switch half := den - remainder; {
+	case remainder > half:
+		quotient++
+	case remainder == half && roundsTieUp(quotient, mode):
+		quotient++
+	}
+	return fromMagnitude(negative, quotient)
+}
+
+// Rescale returns m with the given scale, rounding with mode when digits are dropped
+func (m Money) Rescale(scale int, mode RoundingMode) (Money, error) {
+	if err := checkScale(scale); err != nil {
+		return Money{}, err
+	}
+	if scale >= m.scale {
+		amount, err := mathutil.MulChecked(m.amount, pow10[scale-m.scale])
+		if err != nil {
+			return Money{}, err
+		}
+		return Money{amount: amount, scale: scale, currency: m.currency}, nil
+	}
+	amount, err := quoRound(m.amount < 0, 0, magnitude(m.amount), uint64(pow10[m.scale-scale]), mode)
+	if err != nil {
+		return Money{}, err
+	}
+	return Money{amount: amount, scale: scale, currency: m.currency}, nil
+}
+
+// align returns m and other with the larger of their scales
+func (m Money) align(other Money) (Money, Money, error) {
+	if m.currency != other.currency {
+		return Money{}, Money{}, fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.currency, other.currency)
+	}
+	scale := max(m.scale, other.scale)
+	// growing the scale is exact, the rounding mode is never used
+	left, err := m.Rescale(scale, RoundHalfEven)
+	if err != nil {
+		return Money{}, Money{}, err
+	}
+	right, err := other.Rescale(scale, RoundHalfEven)
+	if err != nil {
+		return Money{}, Money{}, err
+	}
+	return left, right, nil
+}
+
+// Add returns m + other
+func (m Money) Add(other Money) (Money, error) {
+	left, right, err := m.align(other)
+	if err != nil {
+		return Money{}, err
+	}
+	amount, err := mathutil.AddChecked(left.amount, right.amount)
+	if err != nil {
+		return Money{}, err
+	}
+	return Money{amount: amount, scale: left.scale, currency: m.currency}, nil
+}
+
+// Sub returns m - other
+func (m Money) Sub(other Money) (Money, error) {
+	left, right, err := m.align(other)
+	if err != nil {
+		return Money{}, err
+	}
+	amount, err := mathutil.SubChecked(left.amount, right.amount)
+	if err != nil {
+		return Money{}, err
+	}
+	return Money{amount: amount, scale: left.scale, currency: m.currency}, nil
+}
+
+// Neg returns -m
+func (m Money) Neg() (Money, error) {
+	amount, err := mathutil.NegChecked(m.amount)
+	if err != nil {
+		return Money{}, err
+	}
+	return Money{amount: amount, scale: m.scale, currency: m.currency}, nil
+}
+
+// Mul returns m * factor
+func (m Money) Mul(factor int64) (Money, error) {
+	amount, err := mathutil.MulChecked(m.amount, factor)
+	if err != nil {
+		return Money{}, err
+	}
+	return Money{amount: amount, scale: m.scale, currency: m.currency}, nil
+}
+
+// MulRat returns m * factor rounded to the scale of m, for rates and percentages:
+// a 7.5% tax is m multiplied by 75/1000
+func (m Money) MulRat(factor rational.Rational, mode RoundingMode) (Money, error) {
+	hi, lo := bits.Mul64(magnitude(m.amount), magnitude(factor.Num()))
+	negative := (m.amount < 0) != (factor.Num() < 0)
+	amount, err := quoRound(negative, hi, lo, uint64(factor.Den()), mode)
+	if err != nil {
+		return Money{}, err
+	}
+	return Money{amount: amount, scale: m.scale, currency: m.currency}, nil
+}
+
+// Div returns m / divisor rounded to the scale of m
+// to share an amount without losing minor units use Split instead
+func (m Money) Div(divisor int64, mode RoundingMode) (Money, error) {
+	if divisor == 0 {
+		return Money{}, mathutil.ErrDivideByZero
+	}
+	negative := (m.amount < 0) != (divisor < 0)
+	amount, err := quoRound(negative, 0, magnitude(m.amount), magnitude(divisor), mode)
+	if err != nil {
+		return Money{}, err
+	}
+	return Money{amount: amount, scale: m.scale, currency: m.currency}, nil
+}
+
+// Cmp returns -1 if m < other, 0 if m == other and 1 if m > other
+// amounts with different scales are compared by value, 1.5 == 1.50
+func (m Money) Cmp(other Money) (int, error) {
+	if m.currency != other.currency {
+		return 0, fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.currency, other.currency)
+	}
+	// compare through rationals: aligning the scales could overflow
+	left, err := rational.New(m.amount, pow10[m.scale])
+	if err != nil {
+		return 0, err
+	}
+	right, err := rational.New(other.amount, pow10[other.scale])
+	if err != nil {
+		return 0, err
+	}
+	return left.Cmp(right), nil
+}
+
+/*
+Allocate splits m into len(ratios) parts proportional to ratios, without
+losing or creating minor units: the parts always add up to m exactly.
+
+Each part first gets the rounded down share of its ratio, then the few minor
+units left over go one at a time to the parts that lost the most to the
+rounding (the first ones on ties):
+
+	10.00 with ratios 1, 1, 1    3.34, 3.33, 3.33
+	0.05 with ratios 3, 7        0.02, 0.03
+	-10.00 with ratios 1, 1, 1   -3.34, -3.33, -3.33
+
+Ratios must be non negative and not all zero.
+*/
+func (m Money) Allocate(ratios ...int64) ([]Money, error) {
+	if len(ratios) == 0 {
+		return nil, fmt.Errorf("%w: no ratios", ErrInvalidRatios)
+	}
+	var total uint64
+	for _, ratio := range ratios {
+		if ratio < 0 {
+			return nil, fmt.Errorf("%w: negative ratio %d", ErrInvalidRatios, ratio)
+		}
+		var carry uint64
+		total, carry = bits.Add64(total, uint64(ratio), 0)
+		if carry != 0 {
+			return nil, mathutil.ErrOverflow
+		}
+	}
+	if total == 0 {
+		return nil, fmt.Errorf("%w: all ratios are zero", ErrInvalidRatios)
+	}
+
+	// work on magnitudes, so that negative amounts are split symmetrically
+	amount := magnitude(m.amount)
+	shares := make([]uint64, len(ratios))
+	remainders := make([]uint64, len(ratios))
+	leftover := amount
+	for i, ratio := range ratios {
+		// amount * ratio / total <= amount, so the quotient always fits
+		hi, lo := bits.Mul64(amount, uint64(ratio))
+		shares[i], remainders[i] = bits.Div64(hi, lo, total)
+		leftover -= shares[i]
+	}
+
+	// leftover < len(ratios), since every share lost less than one minor unit
+	for ; leftover > 0; leftover-- {
+		largest := 0
+		for i, remainder := range remainders {
+			if remainder > remainders[largest] {
+				largest = i
+			}
+		}
+		shares[largest]++
+		remainders[largest] = 0
+	}
+
+	parts := make([]Money, len(ratios))
+	for i, share := range shares {
+		// every share is at most |m.amount|, so it fits
+		value, _ := fromMagnitude(m.amount < 0, share)
+		parts[i] = Money{amount: value, scale: m.scale, currency: m.currency}
+	}
+	return parts, nil
+}
+
+// Split splits m into n parts as equal as possible that add up to m exactly
+func (m Money) Split(n int) ([]Money, error) {
+	if n <= 0 {
+		return nil, fmt.Errorf("%w: cannot split into %d parts", ErrInvalidRatios, n)
+	}
+	ratios := make([]int64, n)
+	for i := range ratios {
+		ratios[i] = 1
+	}
+	return m.Allocate(ratios...)
+}
+
+// Decimal returns the amount as a plain decimal number with exactly Scale
+// fractional digits, "-1234.50", in the form Parse reads
+func (m Money) Decimal() string {
+	intPart, fraction := m.digits()
+	var builder strings.Builder
+	if m.amount < 0 {
+		builder.WriteByte('-')
+	}
+	builder.WriteString(intPart)
+	if fraction != "" {
+		builder.WriteByte('.')
+		builder.WriteString(fraction)
+	}
+	return builder.String()
+}
+
+// String returns the currency code followed by the decimal amount, "USD -1234.50"
+func (m Money) String() string {
+	return m.currency + " " + m.Decimal()
+}
+
+// digits returns the integer and fractional digits of |m|
+func (m Money) digits() (intPart, fraction string) {
+	text := strconv.FormatUint(magnitude(m.amount), 10)
+	if len(text) <= m.scale {
+		text = strings.Repeat("0", m.scale-len(text)+1) + text
+	}
+	split := len(text) - m.scale
+	return text[:split], text[split:]
+}
@@ -0,0 +1,319 @@
+package money
+
+import (
+	"errors"
+	mathutil "first/mathUtil"
+	"first/rational"
+	"math"
+	"testing"
+)
+
+func mustParse(t *testing.T, currency, text string) Money {
+	t.Helper()
+	m, err := Parse(currency, text, DefaultScale(currency), RoundHalfEven)
+	if err != nil {
+		t.Fatalf("Parse(%q, %q): %v", currency, text, err)
+	}
+	return m
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		text  string
+		scale int
+		mode  RoundingMode
+		want  string
+		err   error
+	}{
+		{"12.34", 2, RoundHalfEven, "12.34", nil},
+		{"-0.5", 2, RoundHalfEven, "-0.50", nil},
+		{"+1000", 0, RoundHalfEven, "1000", nil},
+		{"007.1", 3, RoundHalfEven, "7.100", nil},
+		{"0.125", 2, RoundHalfEven, "0.12", nil},
+		{"0.135", 2, RoundHalfEven, "0.14", nil},
+		{"0.125", 2, RoundHalfUp, "0.13", nil},
+		{"-0.125", 2, RoundHalfUp, "-0.13", nil},
+		{"-0.125", 2, RoundHalfEven, "-0.12", nil},
+		{"0.1250001", 2, RoundHalfEven, "0.13", nil},
+		{"0.12500", 2, RoundHalfEven, "0.12", nil},
+		{"0.004", 2, RoundHalfUp, "0.00", nil},
+		{"2.5", 0, RoundHalfEven, "2", nil},
+		{"9223372036854775807", 0, RoundHalfEven, "9223372036854775807", nil},
+		{"-9223372036854775808", 0, RoundHalfEven, "-9223372036854775808", nil},
+		{"9223372036854775808", 0, RoundHalfEven, "", mathutil.ErrOverflow},
+		{"92233720368547758.08", 2, RoundHalfEven, "", mathutil.ErrOverflow},
+		{"", 2, RoundHalfEven, "", ErrSyntax},
+		{".5", 2, RoundHalfEven, "", ErrSyntax},
+		{"--1", 2, RoundHalfEven, "", ErrSyntax},
+		{"1,000", 2, RoundHalfEven, "", ErrSyntax},
+		{"$1", 2, RoundHalfEven, "", ErrSyntax},
+		{"1", 19, RoundHalfEven, "", ErrInvalidScale},
+	}
+	for _, test := range tests {
+		m, err := Parse("USD", test.text, test.scale, test.mode)
+		if !errors.Is(err, test.err) || (err == nil && m.Decimal() != test.want) {
+			t.Errorf("Parse(%q, %d, %v) = %v, %v, want %s, %v", test.text, test.scale, test.mode, m, err, test.want, test.err)
+		}
+	}
+	for _, currency := range []string{"usd", "US", "USDT", ""} {
+		if _, err := Parse(currency, "1", 2, RoundHalfEven); !errors.Is(err, ErrInvalidCurrency) {
+			t.Errorf("Parse(%q) error %v, want ErrInvalidCurrency", currency, err)
+		}
+	}
+}
+
+func TestFromFloat(t *testing.T) {
+	tests := []struct {
+		value float64
+		want  string
+	}{
+		{0.07, "0.07"},
+		{0.01 * 3, "0.03"},
+		{1.005, "1.00"}, // 1.005 is the shortest decimal, a tie rounded to even
+		{-2.675, "-2.68"},
+		{1e-10, "0.00"},
+	}
+	for _, test := range tests {
+		m, err := FromFloat("EUR", test.value, 2, RoundHalfEven)
+		if err != nil || m.Decimal() != test.want {
+			t.Errorf("FromFloat(%v) = %v, %v, want %s", test.value, m, err, test.want)
+		}
+	}
+	for _, value := range []float64{math.NaN(), math.Inf(1)} {
+		if _, err := FromFloat("EUR", value, 2, RoundHalfEven); !errors.Is(err, ErrSyntax) {
+			t.Errorf("FromFloat(%v) error %v, want ErrSyntax", value, err)
+		}
+	}
+}
+
+func TestRescale(t *testing.T) {
+	tests := []struct {
+		amount   int64
+		from, to int
+		mode     RoundingMode
+		want     int64
+		err      error
+	}{
+		{1234, 2, 4, RoundHalfEven, 123400, nil},
+		{1235, 3, 2, RoundHalfEven, 124, nil},
+		{1245, 3, 2, RoundHalfEven, 124, nil},
+		{1245, 3, 2, RoundHalfUp, 125, nil},
+		{-1245, 3, 2, RoundHalfUp, -125, nil},
+		{-1246, 3, 2, RoundHalfEven, -125, nil},
+		{5, 1, 0, RoundHalfEven, 0, nil},
+		{15, 1, 0, RoundHalfEven, 2, nil},
+		{math.MinInt64, 18, 0, RoundHalfEven, -9, nil},
+		{math.MaxInt64, 0, 1, RoundHalfEven, 0, mathutil.ErrOverflow},
+	}
+	for _, test := range tests {
+		m, _ := New("USD", test.amount, test.from)
+		got, err := m.Rescale(test.to, test.mode)
+		if !errors.Is(err, test.err) || got.Amount() != test.want {
+			t.Errorf("%v.Rescale(%d, %v) = %d, %v, want %d, %v", m, test.to, test.mode, got.Amount(), err, test.want, test.err)
+		}
+	}
+}
+
+func TestArithmetic(t *testing.T) {
+	a := mustParse(t, "USD", "10.50")
+	b, _ := Parse("USD", "0.125", 3, RoundHalfEven)
+
+	if sum, err := a.Add(b); err != nil || sum.String() != "USD 10.625" {
+		t.Errorf("%v + %v = %v, %v", a, b, sum, err)
+	}
+	if diff, err := b.Sub(a); err != nil || diff.String() != "USD -10.375" {
+		t.Errorf("%v - %v = %v, %v", b, a, diff, err)
+	}
+	if product, err := a.Mul(-3); err != nil || product.String() != "USD -31.50" {
+		t.Errorf("%v * -3 = %v, %v", a, product, err)
+	}
+	if _, err := a.Add(mustParse(t, "EUR", "1")); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("USD + EUR error %v, want ErrCurrencyMismatch", err)
+	}
+	max, _ := New("USD", math.MaxInt64, 0)
+	if _, err := max.Add(mustParse(t, "USD", "0.01")); !errors.Is(err, mathutil.ErrOverflow) {
+		t.Errorf("aligning MaxInt64 error %v, want ErrOverflow", err)
+	}
+}
+
+func TestMulRat(t *testing.T) {
+	tax, _ := rational.New(75, 1000)
+	tests := []struct {
+		amount string
+		factor rational.Rational
+		mode   RoundingMode
+		want   string
+	}{
+		{"100.00", tax, RoundHalfEven, "7.50"},
+		{"19.99", tax, RoundHalfEven, "1.50"},
+		{"1.00", tax, RoundHalfEven, "0.08"}, // 0.075
+		{"1.00", tax, RoundHalfUp, "0.08"},
+		{"0.10", rational.One, RoundHalfEven, "0.10"},
+		{"-3.00", rational.Zero, RoundHalfEven, "0.00"},
+		{"-1.00", tax, RoundHalfEven, "-0.08"},
+	}
+	for _, test := range tests {
+		m := mustParse(t, "USD", test.amount)
+		got, err := m.MulRat(test.factor, test.mode)
+		if err != nil || got.Decimal() != test.want {
+			t.Errorf("%v.MulRat(%v, %v) = %v, %v, want %s", m, test.factor, test.mode, got, err, test.want)
+		}
+	}
+}
+
+func TestDiv(t *testing.T) {
+	tests := []struct {
+		amount  string
+		divisor int64
+		mode    RoundingMode
+		want    string
+		err     error
+	}{
+		{"10.00", 3, RoundHalfEven, "3.33", nil},
+		{"10.00", -3, RoundHalfEven, "-3.33", nil},
+		{"0.05", 2, RoundHalfEven, "0.02", nil},
+		{"0.05", 2, RoundHalfUp, "0.03", nil},
+		{"0.07", 2, RoundHalfEven, "0.04", nil},
+		{"-0.05", 2, RoundHalfUp, "-0.03", nil},
+		{"1.00", 0, RoundHalfEven, "", mathutil.ErrDivideByZero},
+	}
+	for _, test := range tests {
+		m := mustParse(t, "USD", test.amount)
+		got, err := m.Div(test.divisor, test.mode)
+		if !errors.Is(err, test.err) || (err == nil && got.Decimal() != test.want) {
+			t.Errorf("%v / %d (%v) = %v, %v, want %s, %v", m, test.divisor, test.mode, got, err, test.want, test.err)
+		}
+	}
+	min, _ := New("USD", math.MinInt64, 2)
+	if got, err := min.Div(-1, RoundHalfEven); !errors.Is(err, mathutil.ErrOverflow) {
+		t.Errorf("MinInt64 / -1 = %v, %v, want ErrOverflow", got, err)
+	}
+}
+
+func TestCmp(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.5", "1.50", 0},
+		{"1.499", "1.5", -1},
+		{"-1.001", "-1", -1},
+		{"0.005", "0", 1},
+	}
+	for _, test := range tests {
+		a, _ := Parse("USD", test.a, 3, RoundHalfEven)
+		b, _ := Parse("USD", test.b, 2, RoundHalfEven)
+		if got, err := a.Cmp(b); err != nil || got != test.want {
+			t.Errorf("Cmp(%s, %s) = %d, %v, want %d", test.a, test.b, got, err, test.want)
+		}
+	}
+	// aligning these scales would overflow, comparing them doesn't
+	huge, _ := New("USD", math.MaxInt64, 0)
+	tiny, _ := New("USD", 1, 18)
+	if got, err := huge.Cmp(tiny); err != nil || got != 1 {
+		t.Errorf("Cmp(MaxInt64, 1e-18) = %d, %v", got, err)
+	}
+	if _, err := huge.Cmp(mustParse(t, "JPY", "1")); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("Cmp(USD, JPY) error %v, want ErrCurrencyMismatch", err)
+	}
+}
+
+func TestAllocate(t *testing.T) {
+	tests := []struct {
+		amount string
+		ratios []int64
+		want   []string
+	}{
+		{"10.00", []int64{1, 1, 1}, []string{"3.34", "3.33", "3.33"}},
+		{"0.05", []int64{3, 7}, []string{"0.02", "0.03"}},
+		{"-10.00", []int64{1, 1, 1}, []string{"-3.34", "-3.33", "-3.33"}},
+		{"1.00", []int64{0, 1}, []string{"0.00", "1.00"}},
+		{"0.02", []int64{1, 1, 1}, []string{"0.01", "0.01", "0.00"}},
+		{"100.00", []int64{50, 30, 20}, []string{"50.00", "30.00", "20.00"}},
+		{"0.00", []int64{1, 2}, []string{"0.00", "0.00"}},
+	}
+	for _, test := range tests {
+		m := mustParse(t, "USD", test.amount)
+		parts, err := m.Allocate(test.ratios...)
+		if err != nil || len(parts) != len(test.want) {
+			t.Errorf("%v.Allocate(%v) = %v, %v, want %v", m, test.ratios, parts, err, test.want)
+			continue
+		}
+		total, _ := Zero("USD")
+		for i, part := range parts {
+			if part.Decimal() != test.want[i] {
+				t.Errorf("%v.Allocate(%v) = %v, want %v", m, test.ratios, parts, test.want)
+				break
+			}
+			total, _ = total.Add(part)
+		}
+		if total != m {
+			t.Errorf("%v.Allocate(%v) adds up to %v", m, test.ratios, total)
+		}
+	}
+
+	extreme, _ := New("USD", math.MinInt64, 2)
+	parts, err := extreme.Allocate(math.MaxInt64, 1)
+	if err != nil || parts[0].Amount()+parts[1].Amount() != math.MinInt64 {
+		t.Errorf("%v.Allocate(MaxInt64, 1) = %v, %v", extreme, parts, err)
+	}
+
+	errorTests := [][]int64{nil, {0, 0}, {1, -1}}
+	for _, ratios := range errorTests {
+		if _, err := extreme.Allocate(ratios...); !errors.Is(err, ErrInvalidRatios) {
+			t.Errorf("Allocate(%v) error %v, want ErrInvalidRatios", ratios, err)
+		}
+	}
+	if _, err := extreme.Allocate(math.MaxInt64, math.MaxInt64, 2); !errors.Is(err, mathutil.ErrOverflow) {
+		t.Errorf("Allocate with a total above 2^64 error %v, want ErrOverflow", err)
+	}
+	if _, err := extreme.Split(0); !errors.Is(err, ErrInvalidRatios) {
+		t.Errorf("Split(0) error %v, want ErrInvalidRatios", err)
+	}
+}
+
+func TestDecimal(t *testing.T) {
+	tests := []struct {
+		amount int64
+		scale  int
+		want   string
+	}{
+		{123450, 2, "1234.50"},
+		{-5, 3, "-0.005"},
+		{0, 2, "0.00"},
+		{7, 0, "7"},
+		{math.MinInt64, 18, "-9.223372036854775808"},
+	}
+	for _, test := range tests {
+		m, _ := New("USD", test.amount, test.scale)
+		if got := m.Decimal(); got != test.want {
+			t.Errorf("New(%d, %d).Decimal() = %q, want %q", test.amount, test.scale, got, test.want)
+		}
+		back, err := Parse("USD", m.Decimal(), test.scale, RoundHalfEven)
+		if err != nil || back != m {
+			t.Errorf("Parse(%q) = %v, %v, want %v", m.Decimal(), back, err, m)
+		}
+	}
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		currency, amount string
+		locale           Locale
+		want             string
+	}{
+		{"USD", "1234567.89", LocaleUS, "$1,234,567.89"},
+		{"USD", "-1234567.89", LocaleUS, "-$1,234,567.89"},
+		{"EUR", "1234567.89", LocaleDE, "1.234.567,89 €"},
+		{"EUR", "1234.5", LocaleUS, "€1,234.50"},
+		{"INR", "1234567.89", LocaleIN, "₹12,34,567.89"},
+		{"CHF", "1234567.89", LocaleCH, "CHF 1’234’567.89"},
+		{"JPY", "1234", LocaleUS, "¥1,234"},
+	}
+	for _, test := range tests {
+		m := mustParse(t, test.currency, test.amount)
+		if got := m.Format(test.locale); got != test.want {
+			t.Errorf("%v.Format() = %q, want %q", m, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package numfmt
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+/*
+
+Number formatting for human readers. The same value is written differently
+depending on who reads it:
+
+	Western     12,345,678.9
+	Indian      1,23,45,678.9        (lakh and crore: groups of 2 after the first 3)
+	EastAsian   1234,5678.9          (groups of 4: 万, 億)
+	German      12.345.678,9
+	French      12 345 678,9         (narrow no-break spaces)
+	Swiss       12’345’678.9
+	Devanagari  १,२३,४५,६७८.९        (Indian grouping, Devanagari digits)
+
+A System holds all of it, including where a currency symbol goes, so
+FormatCurrency can write amounts too. money.Money.Format is built on it.
+
+*/
+
+var ErrSyntax = errors.New("numfmt: invalid number")
+
+type System struct {
+	Decimal string // separator between the integer and the fractional digits
+	Group   string // separator between the groups of integer digits
+	// Grouping lists the sizes of the digit groups from the right, the last one
+	// repeats: {3} gives 1,234,567 and {3, 2} gives 12,34,567
+	Grouping []int
+	// Digits replaces 0 to 9, in order, "" keeps the ASCII digits
+	Digits      string
+	SymbolAfter bool // "1,00 €" instead of "€1.00"
+	SymbolSpace bool // a space between the symbol and the number
+}
+
+var (
+	Western    = System{Decimal: ".", Group: ",", Grouping: []int{3}}
+	Indian     = System{Decimal: ".", Group: ",", Grouping: []int{3, 2}}
+	EastAsian  = System{Decimal: ".", Group: ",", Grouping: []int{4}}
+	German     = System{Decimal: ",", Group: ".", Grouping: []int{3}, SymbolAfter: true, SymbolSpace: true}
+	French     = System{Decimal: ",", Group: "\u202f", Grouping: []int{3}, SymbolAfter: true, SymbolSpace: true}
+	Swiss      = System{Decimal: ".", Group: "’", Grouping: []int{3}, SymbolSpace: true}
+	Devanagari = System{Decimal: ".", Group: ",", Grouping: []int{3, 2}, Digits: "०१२३४५६७८९"}
+)
+
+// FormatInt writes n with the grouping and digits of system
+func FormatInt(n int64, system System) string {
+	return system.format(n < 0, strconv.FormatUint(magnitude(n), 10), "")
+}
+
+// FormatUint writes n with the grouping and digits of system
+func FormatUint(n uint64, system System) string {
+	return system.format(false, strconv.FormatUint(n, 10), "")
+}
+
+// FormatFloat writes f with the given number of fractional digits, or with as
+// many as needed to read f back when decimals is negative
+// NaN and infinities are written as strconv writes them
+func FormatFloat(f float64, decimals int, system System) string {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	text := strconv.FormatFloat(math.Abs(f), 'f', decimals, 64)
+	intPart, fraction, _ := strings.Cut(text, ".")
+	// like FormatDecimal, no sign for a value that rounds to zero, -0.004 is 0.00
+	return system.format(math.Signbit(f) && strings.Trim(intPart+fraction, "0") != "", intPart, fraction)
+}
+
+// FormatDecimal rewrites a plain decimal number such as "-1234567.50", the
+// form strconv and money.Money.Decimal write, with the conventions of system
+func FormatDecimal(text string, system System) (string, error) {
+	negative, intPart, fraction, err := splitDecimal(text)
+	if err != nil {
+		return "", err
+	}
+	return system.format(negative, intPart, fraction), nil
+}
+
+// FormatCurrency is FormatDecimal with a currency symbol placed as system
+// wants it; a symbol made of letters, like "CHF", is always set apart by a space
+func FormatCurrency(text, symbol string, system System) (string, error) {
+	negative, intPart, fraction, err := splitDecimal(text)
+	if err != nil {
+		return "", err
+	}
+	number := system.format(false, intPart, fraction)
+
+	separator := ""
+	if symbol != "" && (system.SymbolSpace || strings.Trim(symbol, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") == "") {
+		separator = " "
+	}
+
+	var builder strings.Builder
+	if negative {
+		builder.WriteByte('-')
+	}
+	if system.SymbolAfter {
+		builder.WriteString(number + separator + symbol)
+	} else {
+		builder.WriteString(symbol + separator + number)
+	}
+	return builder.String(), nil
+}
+
+// splitDecimal splits a plain decimal number into its sign and its digits
+func splitDecimal(text string) (negative bool, intPart, fraction string, err error) {
+	negative = strings.HasPrefix(text, "-")
+	unsigned := strings.TrimLeft(text, "+-")
+	intPart, fraction, hasPoint := strings.Cut(unsigned, ".")
+	if len(text)-len(unsigned) > 1 || intPart == "" || (hasPoint && fraction == "") ||
+		strings.Trim(intPart, "0123456789") != "" || strings.Trim(fraction, "0123456789") != "" {
+		return false, "", "", fmt.Errorf("%w: %q", ErrSyntax, text)
+	}
+	// -0 and -0.00 are just zero
+	if strings.Trim(intPart+fraction, "0") == "" {
+		negative = false
+	}
+	return negative, intPart, fraction, nil
+}
+
+// format writes the sign, the grouped integer digits and the fraction
+func (system System) format(negative bool, intPart, fraction string) string {
+	var builder strings.Builder
+	if negative {
+		builder.WriteByte('-')
+	}
+	builder.WriteString(system.digits(group(intPart, system.Group, system.Grouping)))
+	if fraction != "" {
+		builder.WriteString(system.Decimal)
+		builder.WriteString(system.digits(fraction))
+	}
+	return builder.String()
+}
+
+// digits replaces the ASCII digits of text with the digits of system
+func (system System) digits(text string) string {
+	if system.Digits == "" {
+		return text
+	}
+	native := []rune(system.Digits)
+	return strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' && len(native) == 10 {
+			return native[r-'0']
+		}
+		return r
+	}, text)
+}
+
+// group inserts separator between the groups of digits described by grouping
+func group(digits, separator string, grouping []int) string {
+	if separator == "" || len(grouping) == 0 {
+		return digits
+	}
+
+	// collect the groups from the right, then write them back in order
+	var groups []string
+	for index := 0; len(digits) > 0; index++ {
+		size := grouping[min(index, len(grouping)-1)]
+		if size <= 0 || size >= len(digits) {
+			groups = append(groups, digits)
+			break
+		}
+		groups = append(groups, digits[len(digits)-size:])
+		digits = digits[:len(digits)-size]
+	}
+
+	var builder strings.Builder
+	for index := len(groups) - 1; index >= 0; index-- {
+		builder.WriteString(groups[index])
+		if index > 0 {
+			builder.WriteString(separator)
+		}
+	}
+	return builder.String()
+}
+
+// magnitude returns |value| as a uint64, which also fits -MinInt64
+func magnitude(value int64) uint64 {
+	if value < 0 {
+		return uint64(-(value + 1)) + 1
+	}
+	return uint64(value)
+}
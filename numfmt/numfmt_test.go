@@ -0,0 +1,131 @@
+package numfmt
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestFormatInt(t *testing.T) {
+	tests := []struct {
+		n      int64
+		system System
+		want   string
+	}{
+		{12345678, Western, "12,345,678"},
+		{12345678, Indian, "1,23,45,678"},
+		{12345678, EastAsian, "1234,5678"},
+		{12345678, German, "12.345.678"},
+		{12345678, French, "12 345 678"},
+		{12345678, Swiss, "12’345’678"},
+		{12345678, Devanagari, "१,२३,४५,६७८"},
+		{-1234, Western, "-1,234"},
+		{999, Western, "999"},
+		{100000, Indian, "1,00,000"},
+		{0, Indian, "0"},
+		{math.MinInt64, Western, "-9,223,372,036,854,775,808"},
+	}
+	for _, test := range tests {
+		if got := FormatInt(test.n, test.system); got != test.want {
+			t.Errorf("FormatInt(%d, %+v) = %q, want %q", test.n, test.system, got, test.want)
+		}
+	}
+	if got := FormatUint(math.MaxUint64, Indian); got != "1,84,46,74,40,73,70,95,51,615" {
+		t.Errorf("FormatUint(MaxUint64, Indian) = %q", got)
+	}
+}
+
+func TestFormatFloat(t *testing.T) {
+	tests := []struct {
+		f        float64
+		decimals int
+		system   System
+		want     string
+	}{
+		{12345678.9, 1, Western, "12,345,678.9"},
+		{12345678.9, -1, German, "12.345.678,9"},
+		{1234.5, 2, Indian, "1,234.50"},
+		{-0.004, 2, Western, "0.00"},
+		{math.Copysign(0, -1), 0, Western, "0"},
+		{-1234.5, 0, Western, "-1,234"},
+		{math.Inf(-1), 2, Western, "-Inf"},
+		{math.NaN(), 2, German, "NaN"},
+	}
+	for _, test := range tests {
+		if got := FormatFloat(test.f, test.decimals, test.system); got != test.want {
+			t.Errorf("FormatFloat(%v, %d) = %q, want %q", test.f, test.decimals, got, test.want)
+		}
+	}
+}
+
+func TestFormatDecimal(t *testing.T) {
+	tests := []struct {
+		text   string
+		system System
+		want   string
+		err    error
+	}{
+		{"-1234567.50", Western, "-1,234,567.50", nil},
+		{"+1234567.50", Indian, "12,34,567.50", nil},
+		{"-0.00", Western, "0.00", nil},
+		{"1", French, "1", nil},
+		{"", Western, "", ErrSyntax},
+		{"1.", Western, "", ErrSyntax},
+		{".5", Western, "", ErrSyntax},
+		{"1,000", Western, "", ErrSyntax},
+		{"--1", Western, "", ErrSyntax},
+	}
+	for _, test := range tests {
+		got, err := FormatDecimal(test.text, test.system)
+		if got != test.want || !errors.Is(err, test.err) {
+			t.Errorf("FormatDecimal(%q) = %q, %v, want %q, %v", test.text, got, err, test.want, test.err)
+		}
+	}
+}
+
+func TestFormatCurrency(t *testing.T) {
+	tests := []struct {
+		text, symbol string
+		system       System
+		want         string
+	}{
+		{"1234567.89", "$", Western, "$1,234,567.89"},
+		{"-1234567.89", "$", Western, "-$1,234,567.89"},
+		{"-1234567.89", "€", German, "-1.234.567,89 €"},
+		{"1234567.89", "CHF", Western, "CHF 1,234,567.89"},
+		{"1234567.89", "₹", Devanagari, "₹१२,३४,५६७.८९"},
+		{"5", "", German, "5"},
+	}
+	for _, test := range tests {
+		got, err := FormatCurrency(test.text, test.symbol, test.system)
+		if err != nil || got != test.want {
+			t.Errorf("FormatCurrency(%q, %q) = %q, %v, want %q", test.text, test.symbol, got, err, test.want)
+		}
+	}
+}
+
+func TestWords(t *testing.T) {
+	tests := []struct {
+		n        int64
+		language Language
+		want     string
+	}{
+		{0, English, "zero"},
+		{15, English, "fifteen"},
+		{40, English, "forty"},
+		{-21, English, "minus twenty-one"},
+		{1_000_000, English, "one million"},
+		{12345678, English, "twelve million three hundred forty-five thousand six hundred seventy-eight"},
+		{12345678, IndianEnglish, "one crore twenty-three lakh forty-five thousand six hundred seventy-eight"},
+		{1_000_000_000_000, IndianEnglish, "one lakh crore"},
+		{12345678, Hindi, "एक करोड़ तेईस लाख पैंतालीस हज़ार छह सौ अठहत्तर"},
+		{-100, Hindi, "ऋण एक सौ"},
+		{math.MinInt64, English, "minus nine quintillion two hundred twenty-three quadrillion three hundred seventy-two trillion " +
+			"thirty-six billion eight hundred fifty-four million seven hundred seventy-five thousand eight hundred eight"},
+	}
+	for _, test := range tests {
+		if got := Words(test.n, test.language); got != test.want {
+			t.Errorf("Words(%d, %v) = %q, want %q", test.n, test.language, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package numfmt
+
+import (
+	"fmt"
+	"strings"
+)
+
+/*
+
+Words spells an integer out:
+
+	English        12,345,678    twelve million three hundred forty-five thousand six hundred seventy-eight
+	IndianEnglish  1,23,45,678   one crore twenty-three lakh forty-five thousand six hundred seventy-eight
+	Hindi          1,23,45,678   एक करोड़ तेईस लाख पैंतालीस हज़ार छह सौ अठहत्तर
+
+English uses the short scale (a billion is 10^9) and the American style,
+without "and" after the hundreds. Indian English stops at crore and counts
+the crores in words, as people do: 10^12 is "one lakh crore". Hindi keeps going
+with अरब (10^9), खरब (10^11), नील, पद्म and शंख (10^17), which covers int64.
+
+Hindi has a distinct word for every number below 100, so they are all listed.
+
+*/
+
+type Language int
+
+const (
+	English Language = iota
+	IndianEnglish
+	Hindi
+)
+
+func (language Language) String() string {
+	switch language {
+	case English:
+		return "English"
+	case IndianEnglish:
+		return "Indian English"
+	case Hindi:
+		return "Hindi"
+	}
+	return fmt.Sprintf("Language(%d)", int(language))
+}
+
+var englishOnes = [...]string{
+	"zero", "one", "two", "three", "four", "five", "six", "seven", "eight", "nine",
+	"ten", "eleven", "twelve", "thirteen", "fourteen", "fifteen", "sixteen",
+	"seventeen", "eighteen", "nineteen",
+}
+
+var englishTens = [...]string{
+	"", "", "twenty", "thirty", "forty", "fifty", "sixty", "seventy", "eighty", "ninety",
+}
+
+var hindiBelowHundred = [...]string{
+	"शून्य", "एक", "दो", "तीन", "चार", "पाँच", "छह", "सात", "आठ", "नौ",
+	"दस", "ग्यारह", "बारह", "तेरह", "चौदह", "पंद्रह", "सोलह", "सत्रह", "अठारह", "उन्नीस",
+	"बीस", "इक्कीस", "बाईस", "तेईस", "चौबीस", "पच्चीस", "छब्बीस", "सत्ताईस", "अट्ठाईस", "उनतीस",
+	"तीस", "इकतीस", "बत्तीस", "तैंतीस", "चौंतीस", "पैंतीस", "छत्तीस", "सैंतीस", "अड़तीस", "उनतालीस",
+	"चालीस", "इकतालीस", "बयालीस", "तैंतालीस", "चवालीस", "पैंतालीस", "छियालीस", "सैंतालीस", "अड़तालीस", "उनचास",
+	"पचास", "इक्यावन", "बावन", "तिरेपन", "चौवन", "पचपन", "छप्पन", "सत्तावन", "अट्ठावन", "उनसठ",
+	"साठ", "इकसठ", "बासठ", "तिरेसठ", "चौंसठ", "पैंसठ", "छियासठ", "सड़सठ", "अड़सठ", "उनहत्तर",
+	"सत्तर", "इकहत्तर", "बहत्तर", "तिहत्तर", "चौहत्तर", "पचहत्तर", "छिहत्तर", "सतहत्तर", "अठहत्तर", "उन्यासी",
+	"अस्सी", "इक्यासी", "बयासी", "तिरासी", "चौरासी", "पचासी", "छियासी", "सत्तासी", "अट्ठासी", "नवासी",
+	"नब्बे", "इक्यानबे", "बानबे", "तिरानबे", "चौरानबे", "पंचानबे", "छियानबे", "सत्तानबे", "अट्ठानबे", "निन्यानबे",
+}
+
+// scale is a named power of ten, the scales of a language go from the largest down
+type scale struct {
+	value uint64
+	name  string
+}
+
+var englishScales = []scale{
+	{1_000_000_000_000_000_000, "quintillion"},
+	{1_000_000_000_000_000, "quadrillion"},
+	{1_000_000_000_000, "trillion"},
+	{1_000_000_000, "billion"},
+	{1_000_000, "million"},
+	{1_000, "thousand"},
+	{100, "hundred"},
+}
+
+var indianEnglishScales = []scale{
+	{10_000_000, "crore"},
+	{100_000, "lakh"},
+	{1_000, "thousand"},
+	{100, "hundred"},
+}
+
+var hindiScales = []scale{
+	{100_000_000_000_000_000, "शंख"},
+	{1_000_000_000_000_000, "पद्म"},
+	{10_000_000_000_000, "नील"},
+	{100_000_000_000, "खरब"},
+	{1_000_000_000, "अरब"},
+	{10_000_000, "करोड़"},
+	{100_000, "लाख"},
+	{1_000, "हज़ार"},
+	{100, "सौ"},
+}
+
+// Words spells n out in language
+func Words(n int64, language Language) string {
+	var words []string
+	switch language {
+	case Hindi:
+		if n < 0 {
+			words = append(words, "ऋण")
+		}
+		words = spell(words, magnitude(n), hindiScales, hindiSmall)
+	case IndianEnglish:
+		if n < 0 {
+			words = append(words, "minus")
+		}
+		words = spell(words, magnitude(n), indianEnglishScales, englishSmall)
+	default:
+		if n < 0 {
+			words = append(words, "minus")
+		}
+		words = spell(words, magnitude(n), englishScales, englishSmall)
+	}
+	return strings.Join(words, " ")
+}
+
+// spell appends the words of n, using small for the numbers below the smallest scale
+func spell(words []string, n uint64, scales []scale, small func(uint64) string) []string {
+	if n == 0 {
+		return append(words, small(0))
+	}
+	for _, s := range scales {
+		if n < s.value {
+			continue
+		}
+		// the count of the largest scale can be a large number itself
+		// ("one lakh crore"), the others are always below the next scale
+		words = spell(words, n/s.value, scales, small)
+		words = append(words, s.name)
+		n %= s.value
+		if n == 0 {
+			return words
+		}
+	}
+	return append(words, small(n))
+}
+
+// englishSmall spells n < 100
+func englishSmall(n uint64) string {
+	if n < 20 {
+		return englishOnes[n]
+	}
+	if n%10 == 0 {
+		return englishTens[n/10]
+	}
+	return englishTens[n/10] + "-" + englishOnes[n%10]
+}
+
+// hindiSmall spells n < 100
+func hindiSmall(n uint64) string {
+	return hindiBelowHundred[n]
+}
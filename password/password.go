@@ -0,0 +1,210 @@
+package password
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+/*
+
+Passwords are never stored, only a slow salted hash of them, PBKDF2 with
+HMAC-SHA256, encoded with everything needed to check a password against it:
+
+	$pbkdf2-sha256$i=600000$<salt>$<hash>
+
+salt and hash in unpadded standard base64. Since the cost travels with the
+hash, raising DefaultParams doesn't invalidate existing hashes: they still
+verify, NeedsRehash reports them, and VerifyAndUpgrade replaces them on the
+next successful login, the only time the password is known.
+
+Records from before hashing hold the password itself; Migrate turns those
+into hashes and leaves hashes alone, so it can run over all the records, and
+more than once.
+
+*/
+
+var (
+	ErrFormat    = errors.New("password: malformed hash")
+	ErrAlgorithm = errors.New("password: unknown algorithm")
+	ErrParams    = errors.New("password: invalid parameters")
+)
+
+const (
+	algorithm = "pbkdf2-sha256"
+	prefix    = "$" + algorithm + "$"
+
+	// maxWork bounds the work a stored hash can ask for, in HMAC rounds
+	// (iterations times the 32-byte blocks of the key), so a tampered record
+	// can't make verification hang
+	maxWork = 1 << 24
+
+	minSaltLength, maxSaltLength = 8, 1024
+	minKeyLength, maxKeyLength   = 16, 1024
+)
+
+type Params struct {
+	Iterations int
+	SaltLength int // in bytes
+	KeyLength  int // in bytes
+}
+
+// DefaultParams follow the OWASP recommendation for PBKDF2-HMAC-SHA256
+var DefaultParams = Params{Iterations: 600_000, SaltLength: 16, KeyLength: 32}
+
+func (params Params) validate() error {
+	if !withinLimits(params.Iterations, params.SaltLength, params.KeyLength) {
+		return fmt.Errorf("%w: %+v", ErrParams, params)
+	}
+	return nil
+}
+
+// withinLimits reports whether a hash with these parameters is acceptable,
+// whether it is about to be made or was read from a record
+func withinLimits(iterations, saltLength, keyLength int) bool {
+	if iterations < 1 || saltLength < minSaltLength || saltLength > maxSaltLength ||
+		keyLength < minKeyLength || keyLength > maxKeyLength {
+		return false
+	}
+	blocks := (keyLength + sha256.Size - 1) / sha256.Size
+	return iterations <= maxWork/blocks
+}
+
+// Hash returns the encoded hash of password, with a new random salt
+func Hash(password string, params Params) (string, error) {
+	if err := params.validate(); err != nil {
+		return "", err
+	}
+	salt := make([]byte, params.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := pbkdf2([]byte(password), salt, params.Iterations, params.KeyLength)
+	return encode(params.Iterations, salt, key), nil
+}
+
+// Verify reports whether password matches the encoded hash, in a time that
+// doesn't depend on where they differ; the error is for malformed hashes only
+func Verify(password, encoded string) (bool, error) {
+	iterations, salt, key, err := decode(encoded)
+	if err != nil {
+		return false, err
+	}
+	computed := pbkdf2([]byte(password), salt, iterations, len(key))
+	return subtle.ConstantTimeCompare(computed, key) == 1, nil
+}
+
+// NeedsRehash reports whether the encoded hash was made with other parameters than params
+func NeedsRehash(encoded string, params Params) bool {
+	iterations, salt, key, err := decode(encoded)
+	return err != nil || iterations != params.Iterations ||
+		len(salt) != params.SaltLength || len(key) != params.KeyLength
+}
+
+// VerifyAndUpgrade verifies password like Verify, and when it matches a hash
+// made with other parameters, also returns the hash with params to store
+// instead; upgraded is "" otherwise
+func VerifyAndUpgrade(password, encoded string, params Params) (ok bool, upgraded string, err error) {
+	ok, err = Verify(password, encoded)
+	if !ok || err != nil || !NeedsRehash(encoded, params) {
+		return ok, "", err
+	}
+	upgraded, err = Hash(password, params)
+	if err != nil {
+		// the password was right, failing to upgrade shouldn't lock the user out
+		return true, "", err
+	}
+	return true, upgraded, nil
+}
+
+// IsHash reports whether stored looks like a hash of this package rather than a
+// plaintext password; a password starting with "$pbkdf2-sha256$" fools it
+func IsHash(stored string) bool {
+	return strings.HasPrefix(stored, prefix)
+}
+
+// Migrate returns the hash of stored when it is a plaintext password, and
+// stored itself when it already is a hash; migrated tells which
+func Migrate(stored string, params Params) (encoded string, migrated bool, err error) {
+	if IsHash(stored) {
+		return stored, false, nil
+	}
+	encoded, err = Hash(stored, params)
+	if err != nil {
+		return "", false, err
+	}
+	return encoded, true, nil
+}
+
+func encode(iterations int, salt, key []byte) string {
+	return prefix + "i=" + strconv.Itoa(iterations) + "$" +
+		base64.RawStdEncoding.EncodeToString(salt) + "$" +
+		base64.RawStdEncoding.EncodeToString(key)
+}
+
+func decode(encoded string) (iterations int, salt, key []byte, err error) {
+	// "", algorithm, cost, salt, hash
+	fields := strings.Split(encoded, "$")
+	if len(fields) != 5 || fields[0] != "" {
+		return 0, nil, nil, ErrFormat
+	}
+	if fields[1] != algorithm {
+		return 0, nil, nil, fmt.Errorf("%w: %q", ErrAlgorithm, fields[1])
+	}
+	cost, ok := strings.CutPrefix(fields[2], "i=")
+	if !ok {
+		return 0, nil, nil, ErrFormat
+	}
+	iterations, err = strconv.Atoi(cost)
+	if err != nil {
+		return 0, nil, nil, fmt.Errorf("%w: iterations %q", ErrFormat, cost)
+	}
+	salt, err = base64.RawStdEncoding.DecodeString(fields[3])
+	if err != nil {
+		return 0, nil, nil, fmt.Errorf("%w: salt", ErrFormat)
+	}
+	key, err = base64.RawStdEncoding.DecodeString(fields[4])
+	if err != nil {
+		return 0, nil, nil, fmt.Errorf("%w: hash", ErrFormat)
+	}
+	// the same limits as for new hashes, checked before any work is done
+	if !withinLimits(iterations, len(salt), len(key)) {
+		return 0, nil, nil, fmt.Errorf("%w: iterations %d, salt %d bytes, hash %d bytes",
+			ErrFormat, iterations, len(salt), len(key))
+	}
+	return iterations, salt, key, nil
+}
+
+// pbkdf2 derives a key of keyLength bytes from password, as in RFC 8018
+// section 5.2, with HMAC-SHA256 as the pseudorandom function
+func pbkdf2(password, salt []byte, iterations, keyLength int) []byte {
+	prf := hmac.New(sha256.New, password)
+	blocks := (keyLength + sha256.Size - 1) / sha256.Size
+	key := make([]byte, 0, blocks*sha256.Size)
+	u := make([]byte, sha256.Size)
+	for block := 1; block <= blocks; block++ {
+		// U1 = PRF(password, salt || INT(block)), Ui = PRF(password, Ui-1),
+		// and the block is U1 ^ U2 ^ ... ^ Uiterations
+		prf.Reset()
+		prf.Write(salt)
+		prf.Write([]byte{byte(block >> 24), byte(block >> 16), byte(block >> 8), byte(block)})
+		u = prf.Sum(u[:0])
+		t := append([]byte(nil), u...)
+		for range iterations - 1 {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for index := range t {
+				t[index] ^= u[index]
+			}
+		}
+		key = append(key, t...)
+	}
+	return key[:keyLength]
+}
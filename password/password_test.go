@@ -0,0 +1,121 @@
+package password
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// the PBKDF2-HMAC-SHA256 test vectors of RFC 7914 section 11
+func TestPBKDF2(t *testing.T) {
+	tests := []struct {
+		password, salt string
+		iterations     int
+		want           string
+	}{
+		{"passwd", "salt", 1,
+			"55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc" +
+				"49ca9cccf179b645991664b39d77ef317c71b845b1e30bd509112041d3a19783"},
+		{"Password", "NaCl", 80000,
+			"4ddcd8f60b98be21830cee5ef22701f9641a4418d04c0414aeff08876b34ab56" +
+				"a1d425a1225833549adb841b51c9b3176a272bdebba1d078478f62b397f33c8d"},
+	}
+	for _, test := range tests {
+		want, _ := hex.DecodeString(test.want)
+		// every prefix too, to cover keys that end inside a block
+		for _, length := range []int{1, 31, 32, 33, 64} {
+			got := pbkdf2([]byte(test.password), []byte(test.salt), test.iterations, length)
+			if hex.EncodeToString(got) != hex.EncodeToString(want[:length]) {
+				t.Errorf("pbkdf2(%q, %q, %d, %d) = %x, want %x",
+					test.password, test.salt, test.iterations, length, got, want[:length])
+			}
+		}
+	}
+}
+
+var cheap = Params{Iterations: 1000, SaltLength: 16, KeyLength: 32}
+
+func TestHashVerify(t *testing.T) {
+	encoded, err := Hash("rishika", cheap)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(encoded, "$pbkdf2-sha256$i=1000$") {
+		t.Errorf("Hash = %q", encoded)
+	}
+	other, _ := Hash("rishika", cheap)
+	if other == encoded {
+		t.Error("two hashes of the same password share their salt")
+	}
+
+	tests := []struct {
+		password string
+		want     bool
+	}{
+		{"rishika", true},
+		{"Rishika", false},
+		{"", false},
+		{"rishika ", false},
+	}
+	for _, test := range tests {
+		if got, err := Verify(test.password, encoded); got != test.want || err != nil {
+			t.Errorf("Verify(%q) = %v, %v, want %v", test.password, got, err, test.want)
+		}
+	}
+}
+
+func TestDecodeLimits(t *testing.T) {
+	salt := base64.RawStdEncoding.EncodeToString(make([]byte, 16))
+	key := base64.RawStdEncoding.EncodeToString(make([]byte, 32))
+	bigKey := base64.RawStdEncoding.EncodeToString(make([]byte, 1024))
+
+	tests := []struct {
+		name    string
+		encoded string
+		want    error
+	}{
+		{"fields", "$pbkdf2-sha256$i=1$" + salt, ErrFormat},
+		{"algorithm", "$bcrypt$i=1$" + salt + "$" + key, ErrAlgorithm},
+		{"iterations", "$pbkdf2-sha256$i=x$" + salt + "$" + key, ErrFormat},
+		{"zero iterations", "$pbkdf2-sha256$i=0$" + salt + "$" + key, ErrFormat},
+		{"too many iterations", "$pbkdf2-sha256$i=99999999$" + salt + "$" + key, ErrFormat},
+		{"short salt", "$pbkdf2-sha256$i=1$AAAA$" + key, ErrFormat},
+		{"short hash", "$pbkdf2-sha256$i=1$" + salt + "$AAAA", ErrFormat},
+		// each allowed on its own, but 32 blocks of 1<<20 iterations is too much work
+		{"too much work", "$pbkdf2-sha256$i=1048576$" + salt + "$" + bigKey, ErrFormat},
+	}
+	for _, test := range tests {
+		if _, err := Verify("x", test.encoded); !errors.Is(err, test.want) {
+			t.Errorf("%s: Verify error %v, want %v", test.name, err, test.want)
+		}
+	}
+}
+
+func TestUpgradeAndMigrate(t *testing.T) {
+	encoded, _ := Hash("rishika", cheap)
+	if NeedsRehash(encoded, cheap) {
+		t.Error("NeedsRehash with the same parameters")
+	}
+
+	stronger := Params{Iterations: 2000, SaltLength: 16, KeyLength: 32}
+	ok, upgraded, err := VerifyAndUpgrade("rishika", encoded, stronger)
+	if !ok || err != nil || NeedsRehash(upgraded, stronger) {
+		t.Fatalf("VerifyAndUpgrade = %v, %q, %v", ok, upgraded, err)
+	}
+	if ok, _ := Verify("rishika", upgraded); !ok {
+		t.Error("upgraded hash doesn't verify")
+	}
+	if ok, upgraded, _ := VerifyAndUpgrade("raj", encoded, stronger); ok || upgraded != "" {
+		t.Errorf("VerifyAndUpgrade with a wrong password = %v, %q", ok, upgraded)
+	}
+
+	migrated, changed, err := Migrate("rishika", cheap)
+	if !changed || err != nil || !IsHash(migrated) {
+		t.Fatalf("Migrate(plaintext) = %q, %v, %v", migrated, changed, err)
+	}
+	if again, changed, _ := Migrate(migrated, cheap); changed || again != migrated {
+		t.Errorf("Migrate(hash) = %q, %v", again, changed)
+	}
+}
@@ -0,0 +1,419 @@
+package rational
+
+import (
+	"errors"
+	mathutil "first/mathUtil"
+	"fmt"
+	"math"
+	"math/big"
+	"math/bits"
+	"strconv"
+	"strings"
+)
+
+/*
+
+Rational is an exact fraction num/den of two int64 values. Where test() in main
+only gives back an integer quotient and remainder, a Rational keeps the exact
+result of the division:
+
+	test(7, 2)             3, 1
+	rational.New(7, 2)     7/2
+
+Every Rational is kept normalized:
+
+	- the denominator is always positive, the sign lives in the numerator
+	- the numerator and the denominator have no common factor (6/8 becomes 3/4)
+	- zero is always 0/1
+
+so two equal fractions always have the same representation and can be compared
+with ==. The zero value of Rational is 0, like Zero.
+
+The arithmetic is exact. When a result doesn't fit in int64 the operations
+fail with mathutil.ErrOverflow instead of silently wrapping around, and a zero
+denominator fails with ErrDivideByZero, which is mathutil.ErrDivideByZero.
+
+*/
+
+// ErrDivideByZero is returned for a zero denominator, it is mathutil's so that
+// one errors.Is matches it for money and mathutil.DivMod too
+var ErrDivideByZero = mathutil.ErrDivideByZero
+
+var ErrSyntax = errors.New("rational: invalid syntax")
+
+// Rational is ready to use as is, the zero value is 0: the denominator is
+// stored minus one, so that zero means 0/1 rather than 0/0
+type Rational struct {
+	num       int64
+	denMinus1 int64
+}
+
+var (
+	Zero = Rational{}
+	One  = Rational{num: 1}
+)
+
+func (r Rational) den() int64 {
+	return r.denMinus1 + 1
+}
+
+// abs returns the magnitude of value as a uint64, which also fits -MinInt64
+func abs(value int64) uint64 {
+	if value < 0 {
+		return uint64(-(value + 1)) + 1
+	}
+	return uint64(value)
+}
+
+func gcd(a, b uint64) uint64 {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+// fromMagnitudes builds the normalized Rational ±num/den
+func fromMagnitudes(negative bool, num, den uint64) (Rational, error) {
+	if den == 0 {
+		return Rational{}, ErrDivideByZero
+	}
+	if num == 0 {
+		return Zero, nil
+	}
+
+	g := gcd(num, den)
+	num /= g
+	den /= g
+	if den > math.MaxInt64 {
+		return Rational{}, mathutil.ErrOverflow
+	}
+	if negative {
+		if num > 1<<63 {
+			return Rational{}, mathutil.ErrOverflow
+		}
+		return Rational{num: int64(-num), denMinus1: int64(den) - 1}, nil
+	}
+	if num > math.MaxInt64 {
+		return Rational{}, mathutil.ErrOverflow
+	}
+	return Rational{num: int64(num), denMinus1: int64(den) - 1}, nil
+}
+
+// New returns the normalized fraction num/den
+func New(num, den int64) (Rational, error) {
+	return fromMagnitudes((num < 0) != (den < 0), abs(num), abs(den))
+}
+
+// FromInt returns the fraction n/1
+func FromInt(n int64) Rational {
+	return Rational{num: n}
+}
+
+// Num returns the numerator, which carries the sign
+func (r Rational) Num() int64 {
+	return r.num
+}
+
+// Den returns the denominator, which is always positive
+func (r Rational) Den() int64 {
+	return r.den()
+}
+
+// Sign returns -1, 0 or 1 depending on the sign of r
+func (r Rational) Sign() int {
+	switch {
+	case r.num < 0:
+		return -1
+	case r.num > 0:
+		return 1
+	}
+	return 0
+}
+
+// IsInt reports whether r is an integer
+func (r Rational) IsInt() bool {
+	return r.den() == 1
+}
+
+// Neg returns -r
+func (r Rational) Neg() (Rational, error) {
+	num, err := mathutil.NegChecked(r.num)
+	if err != nil {
+		return Rational{}, err
+	}
+	return Rational{num: num, denMinus1: r.denMinus1}, nil
+}
+
+// Inv returns 1/r
+func (r Rational) Inv() (Rational, error) {
+	return New(r.den(), r.num)
+}
+
+// Add returns r + other
+func (r Rational) Add(other Rational) (Rational, error) {
+	// a/b + c/d = (a*(d/g) + c*(b/g)) / (b/g*d) with g = gcd(b, d), which keeps the
+	// intermediate values small enough to avoid many needless overflows
+	g := int64(gcd(uint64(r.den()), uint64(other.den())))
+	left, err := mathutil.MulChecked(r.num, other.den()/g)
+	if err != nil {
+		return Rational{}, err
+	}
+	right, err := mathutil.MulChecked(other.num, r.den()/g)
+	if err != nil {
+		return Rational{}, err
+	}
+	num, err := mathutil.AddChecked(left, right)
+	if err != nil {
+		return Rational{}, err
+	}
+	den, err := mathutil.MulChecked(r.den()/g, other.den())
+	if err != nil {
+		return Rational{}, err
+	}
+	return New(num, den)
+}
+
+// Sub returns r - other
+func (r Rational) Sub(other Rational) (Rational, error) {
+	negated, err := other.Neg()
+	if err != nil {
+		return Rational{}, err
+	}
+	return r.Add(negated)
+}
+
+// Mul returns r * other
+func (r Rational) Mul(other Rational) (Rational, error) {
+	// cross reduce first: a/b * c/d = (a/g1 * c/g2) / (b/g2 * d/g1)
+	// with g1 = gcd(a, d) and g2 = gcd(c, b), so the result is already normalized
+	g1 := gcd(abs(r.num), uint64(other.den()))
+	g2 := gcd(abs(other.num), uint64(r.den()))
+	if g1 == 0 || g2 == 0 {
+		// one of the numerators is 0
+		return Zero, nil
+	}
+
+	numHi, num := bits.Mul64(abs(r.num)/g1, abs(other.num)/g2)
+	denHi, den := bits.Mul64(uint64(r.den())/g2, uint64(other.den())/g1)
+	if numHi != 0 || denHi != 0 {
+		return Rational{}, mathutil.ErrOverflow
+	}
+	return fromMagnitudes((r.num < 0) != (other.num < 0), num, den)
+}
+
+// Div returns r / other, or ErrDivideByZero if other is zero
+func (r Rational) Div(other Rational) (Rational, error) {
+	if other.num == 0 {
+		return Rational{}, ErrDivideByZero
+	}
+	inverse, err := other.Inv()
+	if err != nil {
+		return Rational{}, err
+	}
+	return r.Mul(inverse)
+}
+
+// Cmp returns -1 if r < other, 0 if r == other and 1 if r > other
+// it never overflows, the cross products are computed on 128 bits
+func (r Rational) Cmp(other Rational) int {
+	if r.Sign() != other.Sign() {
+		if r.Sign() < other.Sign() {
+			return -1
+		}
+		return 1
+	}
+
+	// same sign: compare |a|*d with |c|*b, and flip the result for negative values
+	leftHi, leftLo := bits.Mul64(abs(r.num), uint64(other.den()))
+	rightHi, rightLo := bits.Mul64(abs(other.num), uint64(r.den()))
+	result := 0
+	switch {
+	case leftHi < rightHi || (leftHi == rightHi && leftLo < rightLo):
+		result = -1
+	case leftHi > rightHi || leftLo > rightLo:
+		result = 1
+	}
+	if r.Sign() < 0 {
+		result = -result
+	}
+	return result
+}
+
+// Less reports whether r < other
+func (r Rational) Less(other Rational) bool {
+	return r.Cmp(other) < 0
+}
+
+// Float64 returns the nearest float64 to r
+func (r Rational) Float64() float64 {
+	// big.Rat rounds correctly, which float64(num) / float64(den) doesn't when
+	// num or den have more than 53 significant bits
+	value, _ := big.NewRat(r.num, r.den()).Float64()
+	return value
+}
+
+// String returns r as "num/den", or just "num" for integers
+func (r Rational) String() string {
+	if r.den() == 1 {
+		return strconv.FormatInt(r.num, 10)
+	}
+	return fmt.Sprintf("%d/%d", r.num, r.den())
+}
+
+/*
+DecimalString returns r in decimal notation, with the repeating part of the
+fraction between parentheses:
+
+	1/4    0.25
+	1/3    0.(3)
+	1/6    0.1(6)
+	22/7   3.(142857)
+
+It's found by long division: a digit sequence starts repeating as soon as the
+same remainder shows up twice. The repeating part can be as long as den - 1
+digits, so after maxDigits fractional digits DecimalString gives up and returns
+the digits computed so far followed by "...", "3..." for 10/3 when maxDigits
+is 0.
+*/
+func (r Rational) DecimalString(maxDigits int) string {
+	var builder strings.Builder
+	if r.num < 0 {
+		builder.WriteByte('-')
+	}
+	den := uint64(r.den())
+	builder.WriteString(strconv.FormatUint(abs(r.num)/den, 10))
+
+	remainder := abs(r.num) % den
+	if remainder == 0 {
+		return builder.String()
+	}
+	if maxDigits <= 0 {
+		// no fractional digit at all, so no decimal point either
+		builder.WriteString("...")
+		return builder.String()
+	}
+	builder.WriteByte('.')
+
+	digits := make([]byte, 0, 16)
+	seen := make(map[uint64]int) // remainder -> index of the digit it produced
+	for remainder != 0 {
+		if start, ok := seen[remainder]; ok {
+			builder.Write(digits[:start])
+			builder.WriteByte('(')
+			builder.Write(digits[start:])
+			builder.WriteByte(')')
+			return builder.String()
+		}
+		if len(digits) == maxDigits {
+			builder.Write(digits)
+			builder.WriteString("...")
+			return builder.String()
+		}
+		seen[remainder] = len(digits)
+
+		// remainder < den <= MaxInt64, so remainder*10 fits in 128 bits and
+		// its high half is smaller than den, as bits.Div64 requires
+		hi, lo := bits.Mul64(remainder, 10)
+		digit, rem := bits.Div64(hi, lo, den)
+		digits = append(digits, byte('0'+digit))
+		remainder = rem
+	}
+	builder.Write(digits)
+	return builder.String()
+}
+
+/*
+Parse parses a fraction in any of these forms:
+
+	"3/4"  "-3/4"  "3/-4"  " 3 / 4 "   a fraction
+	"5"    "-5"                       an integer
+	"1.25" "-0.5"                     a terminating decimal
+	"0.(3)" "0.1(6)"                  a repeating decimal, as DecimalString writes it
+*/
+func Parse(text string) (Rational, error) {
+	text = strings.TrimSpace(text)
+	if numText, denText, found := strings.Cut(text, "/"); found {
+		num, errNum := strconv.ParseInt(strings.TrimSpace(numText), 10, 64)
+		den, errDen := strconv.ParseInt(strings.TrimSpace(denText), 10, 64)
+		if err := parseError(text, errNum, errDen); err != nil {
+			return Rational{}, err
+		}
+		return New(num, den)
+	}
+	return parseDecimal(text)
+}
+
+func parseError(text string, errs ...error) error {
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		if errors.Is(err, strconv.ErrRange) {
+			return mathutil.ErrOverflow
+		}
+		return fmt.Errorf("%w: %q", ErrSyntax, text)
+	}
+	return nil
+}
+
+// parseDecimal parses integers and decimals, with an optional repeating part
+func parseDecimal(text string) (Rational, error) {
+	negative := strings.HasPrefix(text, "-")
+	unsigned := strings.TrimLeft(text, "+-")
+	if len(text)-len(unsigned) > 1 {
+		return Rational{}, fmt.Errorf("%w: %q", ErrSyntax, text)
+	}
+
+	intPart, fraction, _ := strings.Cut(unsigned, ".")
+	fixed, repeating := fraction, ""
+	if open := strings.IndexByte(fraction, '('); open >= 0 {
+		if !strings.HasSuffix(fraction, ")") || open == len(fraction)-2 {
+			return Rational{}, fmt.Errorf("%w: %q", ErrSyntax, text)
+		}
+		fixed, repeating = fraction[:open], fraction[open+1:len(fraction)-1]
+	}
+	for _, part := range []string{intPart, fixed, repeating} {
+		if strings.Trim(part, "0123456789") != "" {
+			return Rational{}, fmt.Errorf("%w: %q", ErrSyntax, text)
+		}
+	}
+	if intPart == "" {
+		return Rational{}, fmt.Errorf("%w: %q", ErrSyntax, text)
+	}
+
+	// x = I.F(R) is (IFR - IF) / (10^len(F) * (10^len(R) - 1)), or IF / 10^len(F)
+	// when there is no repeating part
+	whole, err := parseDigits(intPart + fixed)
+	if err != nil {
+		return Rational{}, err
+	}
+	scale, err := mathutil.PowChecked(uint64(10), uint(len(fixed)))
+	if err != nil {
+		return Rational{}, err
+	}
+	if repeating == "" {
+		return fromMagnitudes(negative, whole, scale)
+	}
+
+	withRepeating, err := parseDigits(intPart + fixed + repeating)
+	if err != nil {
+		return Rational{}, err
+	}
+	nines, err := mathutil.PowChecked(uint64(10), uint(len(repeating)))
+	if err != nil {
+		return Rational{}, err
+	}
+	den, err := mathutil.MulChecked(scale, nines-1)
+	if err != nil {
+		return Rational{}, err
+	}
+	return fromMagnitudes(negative, withRepeating-whole, den)
+}
+
+func parseDigits(digits string) (uint64, error) {
+	value, err := strconv.ParseUint(digits, 10, 64)
+	if err != nil {
+		return 0, parseError(digits, err)
+	}
+	return value, nil
+}
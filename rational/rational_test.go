@@ -0,0 +1,218 @@
+package rational
+
+import (
+	"errors"
+	mathutil "first/mathUtil"
+	"math"
+	"testing"
+)
+
+func mustParse(t *testing.T, text string) Rational {
+	t.Helper()
+	r, err := Parse(text)
+	if err != nil {
+		t.Fatalf("Parse(%q): %v", text, err)
+	}
+	return r
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		num, den int64
+		want     string
+		err      error
+	}{
+		{6, 8, "3/4", nil},
+		{-6, 8, "-3/4", nil},
+		{6, -8, "-3/4", nil},
+		{-6, -8, "3/4", nil},
+		{0, -5, "0", nil},
+		{10, 5, "2", nil},
+		{1, 0, "", ErrDivideByZero},
+		{math.MinInt64, 2, "-4611686018427387904", nil},
+		{math.MinInt64, -1, "", mathutil.ErrOverflow},
+		{1, math.MinInt64, "", mathutil.ErrOverflow},
+		{2, math.MinInt64, "-1/4611686018427387904", nil},
+	}
+	for _, test := range tests {
+		r, err := New(test.num, test.den)
+		if !errors.Is(err, test.err) || (err == nil && r.String() != test.want) {
+			t.Errorf("New(%d, %d) = %v, %v, want %s, %v", test.num, test.den, r, err, test.want, test.err)
+		}
+	}
+}
+
+func TestZeroValue(t *testing.T) {
+	var zero Rational
+	if zero != Zero || zero.Den() != 1 || zero.String() != "0" {
+		t.Errorf("zero value = %v (den %d), want 0/1", zero, zero.Den())
+	}
+	if sum, err := zero.Add(One); err != nil || sum != One {
+		t.Errorf("Rational{} + 1 = %v, %v", sum, err)
+	}
+	if r, _ := New(0, 7); r != zero {
+		t.Errorf("New(0, 7) != Rational{}")
+	}
+	if _, err := One.Div(zero); !errors.Is(err, mathutil.ErrDivideByZero) {
+		t.Errorf("1 / Rational{} error %v, want mathutil.ErrDivideByZero", err)
+	}
+}
+
+func TestArithmetic(t *testing.T) {
+	tests := []struct {
+		a, b               string
+		sum, diff, product string
+		quotient           string
+	}{
+		{"1/2", "1/3", "5/6", "1/6", "1/6", "3/2"},
+		{"3/4", "-3/4", "0", "3/2", "-9/16", "-1"},
+		{"-7/2", "2", "-3/2", "-11/2", "-7", "-7/4"},
+		{"0", "5/3", "5/3", "-5/3", "0", "0"},
+		// the cross reduction keeps these from overflowing
+		{"9223372036854775807/2", "2/9223372036854775807", "", "", "1", ""},
+	}
+	for _, test := range tests {
+		a, b := mustParse(t, test.a), mustParse(t, test.b)
+		results := []struct {
+			op, want string
+			got      func() (Rational, error)
+		}{
+			{"+", test.sum, func() (Rational, error) { return a.Add(b) }},
+			{"-", test.diff, func() (Rational, error) { return a.Sub(b) }},
+			{"*", test.product, func() (Rational, error) { return a.Mul(b) }},
+			{"/", test.quotient, func() (Rational, error) { return a.Div(b) }},
+		}
+		for _, result := range results {
+			if result.want == "" {
+				continue
+			}
+			got, err := result.got()
+			if err != nil || got.String() != result.want {
+				t.Errorf("%s %s %s = %v, %v, want %s", test.a, result.op, test.b, got, err, result.want)
+			}
+		}
+	}
+}
+
+func TestOverflow(t *testing.T) {
+	big := FromInt(math.MaxInt64)
+	if _, err := big.Add(One); !errors.Is(err, mathutil.ErrOverflow) {
+		t.Errorf("MaxInt64 + 1 error %v, want ErrOverflow", err)
+	}
+	if _, err := big.Mul(FromInt(2)); !errors.Is(err, mathutil.ErrOverflow) {
+		t.Errorf("MaxInt64 * 2 error %v, want ErrOverflow", err)
+	}
+	if _, err := FromInt(math.MinInt64).Neg(); !errors.Is(err, mathutil.ErrOverflow) {
+		t.Errorf("-MinInt64 error %v, want ErrOverflow", err)
+	}
+}
+
+func TestCmp(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1/3", "1/2", -1},
+		{"-1/3", "-1/2", 1},
+		{"2/4", "1/2", 0},
+		{"-1", "0", -1},
+		// the cross products don't fit in 64 bits
+		{"9223372036854775806/9223372036854775807", "9223372036854775805/9223372036854775806", 1},
+	}
+	for _, test := range tests {
+		a, b := mustParse(t, test.a), mustParse(t, test.b)
+		if got := a.Cmp(b); got != test.want {
+			t.Errorf("Cmp(%s, %s) = %d, want %d", test.a, test.b, got, test.want)
+		}
+		if got := b.Cmp(a); got != -test.want {
+			t.Errorf("Cmp(%s, %s) = %d, want %d", test.b, test.a, got, -test.want)
+		}
+	}
+}
+
+func TestDecimalString(t *testing.T) {
+	tests := []struct {
+		r         string
+		maxDigits int
+		want      string
+	}{
+		{"1/4", 10, "0.25"},
+		{"1/3", 10, "0.(3)"},
+		{"1/6", 10, "0.1(6)"},
+		{"22/7", 10, "3.(142857)"},
+		{"-1/7", 10, "-0.(142857)"},
+		{"-1/2", 10, "-0.5"},
+		{"5", 10, "5"},
+		{"0", 0, "0"},
+		{"1/7", 3, "0.142..."},
+		{"10/3", 0, "3..."},
+		{"-10/3", -1, "-3..."},
+		{"1/9223372036854775807", 5, "0.00000..."},
+	}
+	for _, test := range tests {
+		if got := mustParse(t, test.r).DecimalString(test.maxDigits); got != test.want {
+			t.Errorf("%s.DecimalString(%d) = %q, want %q", test.r, test.maxDigits, got, test.want)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+		err  error
+	}{
+		{" 3 / 4 ", "3/4", nil},
+		{"3/-4", "-3/4", nil},
+		{"-5", "-5", nil},
+		{"+5", "5", nil},
+		{"1.25", "5/4", nil},
+		{"-0.5", "-1/2", nil},
+		{"0.(3)", "1/3", nil},
+		{"0.1(6)", "1/6", nil},
+		{"3.(142857)", "22/7", nil},
+		{"0.(9)", "1", nil},
+		{"1/0", "", ErrDivideByZero},
+		{"", "", ErrSyntax},
+		{"abc", "", ErrSyntax},
+		{"--1", "", ErrSyntax},
+		{".5", "", ErrSyntax},
+		{"0.()", "", ErrSyntax},
+		{"0.(3", "", ErrSyntax},
+		{"1/x", "", ErrSyntax},
+		{"99999999999999999999", "", mathutil.ErrOverflow},
+		{"0.00000000000000000001", "", mathutil.ErrOverflow},
+	}
+	for _, test := range tests {
+		r, err := Parse(test.text)
+		if !errors.Is(err, test.err) || (err == nil && r.String() != test.want) {
+			t.Errorf("Parse(%q) = %v, %v, want %s, %v", test.text, r, err, test.want, test.err)
+		}
+	}
+
+	// DecimalString and Parse round trip
+	for _, text := range []string{"1/7", "-22/7", "1/12", "5/4", "123456/999"} {
+		r := mustParse(t, text)
+		if back := mustParse(t, r.DecimalString(100)); back != r {
+			t.Errorf("Parse(%q.DecimalString()) = %v", text, back)
+		}
+	}
+}
+
+func TestFloat64(t *testing.T) {
+	tests := []struct {
+		r    string
+		want float64
+	}{
+		{"1/4", 0.25},
+		{"-1/3", -1.0 / 3},
+		{"1/9223372036854775807", 1.0 / math.MaxInt64},
+		// exactly halfway between 1 and the next float64, rounded to even
+		{"9007199254740993/9007199254740992", 1},
+	}
+	for _, test := range tests {
+		if got := mustParse(t, test.r).Float64(); got != test.want {
+			t.Errorf("%s.Float64() = %g, want %g", test.r, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package seq
+
+import "iter"
+
+// collectors turning sequences into maps, in the ways slices.Collect and
+// maps.Collect don't
+
+// ToMapBy collects the values of s into a map, under the key key(value);
+// later values overwrite earlier ones with the same key
+func ToMapBy[T any, K comparable](s iter.Seq[T], key func(T) K) map[K]T {
+	result := make(map[K]T)
+	for value := range s {
+		result[key(value)] = value
+	}
+	return result
+}
+
+// GroupBy collects the values of s into slices by key, keeping their order
+func GroupBy[T any, K comparable](s iter.Seq[T], key func(T) K) map[K][]T {
+	result := make(map[K][]T)
+	for value := range s {
+		k := key(value)
+		result[k] = append(result[k], value)
+	}
+	return result
+}
+
+// Frequencies counts how many times each value appears in s
+func Frequencies[T comparable](s iter.Seq[T]) map[T]int {
+	result := make(map[T]int)
+	for value := range s {
+		result[value]++
+	}
+	return result
+}
+
+// Partition collects the values of s for which keep returns true and the others
+func Partition[T any](s iter.Seq[T], keep func(T) bool) (kept, rest []T) {
+	for value := range s {
+		if keep(value) {
+			kept = append(kept, value)
+		} else {
+			rest = append(rest, value)
+		}
+	}
+	return kept, rest
+}
@@ -0,0 +1,231 @@
+package seq
+
+import (
+	mathutil "first/mathUtil"
+	"iter"
+)
+
+/*
+
+Lazy sequences on top of iter.Seq and iter.Seq2.
+
+Nothing is computed until the sequence is ranged over, and only as much as
+the loop consumes, so the stages can be chained without building the
+intermediate slices main builds with its index loops:
+
+	evens := seq.Filter(seq.Range(1, 1_000_000), func(n int) bool { return n%2 == 0 })
+	squares := seq.Map(evens, mathutil.GetSquare)
+	first := slices.Collect(seq.Take(squares, 3)) // [4 16 36], only 6 values generated
+
+The standard library already covers part of it and is not repeated here:
+slices.Values and maps.All turn collections into sequences, slices.Collect
+and maps.Collect turn sequences back into collections.
+
+The sequences are as reusable as their source: ranging over a stage again
+ranges over its source again.
+
+*/
+
+// Range yields start, start+1, ..., end-1
+func Range[T mathutil.Integer](start, end T) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for value := start; value < end; value++ {
+			if !yield(value) {
+				return
+			}
+		}
+	}
+}
+
+// Iterate yields seed, next(seed), next(next(seed)), ... forever,
+// use Take or a break to stop it
+func Iterate[T any](seed T, next func(T) T) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for value := seed; yield(value); value = next(value) {
+		}
+	}
+}
+
+// Map yields f(value) for every value of s
+func Map[T, U any](s iter.Seq[T], f func(T) U) iter.Seq[U] {
+	return func(yield func(U) bool) {
+		for value := range s {
+			if !yield(f(value)) {
+				return
+			}
+		}
+	}
+}
+
+// Filter yields the values of s for which keep returns true
+func Filter[T any](s iter.Seq[T], keep func(T) bool) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for value := range s {
+			if keep(value) && !yield(value) {
+				return
+			}
+		}
+	}
+}
+
+// FlatMap yields every value of the sequences f returns for the values of s
+func FlatMap[T, U any](s iter.Seq[T], f func(T) iter.Seq[U]) iter.Seq[U] {
+	return func(yield func(U) bool) {
+		for value := range s {
+			for inner := range f(value) {
+				if !yield(inner) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Take yields the first n values of s, and stops pulling values from s after them
+func Take[T any](s iter.Seq[T], n int) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		if n <= 0 {
+			return
+		}
+		taken := 0
+		for value := range s {
+			if !yield(value) {
+				return
+			}
+			taken++
+			if taken == n {
+				return
+			}
+		}
+	}
+}
+
+// Skip yields the values of s after the first n
+func Skip[T any](s iter.Seq[T], n int) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		skipped := 0
+		for value := range s {
+			if skipped < n {
+				skipped++
+				continue
+			}
+			if !yield(value) {
+				return
+			}
+		}
+	}
+}
+
+// Zip yields pairs of values of a and b, in step, until the shorter one ends
+func Zip[A, B any](a iter.Seq[A], b iter.Seq[B]) iter.Seq2[A, B] {
+	return func(yield func(A, B) bool) {
+		nextB, stop := iter.Pull(b)
+		defer stop()
+		for valueA := range a {
+			valueB, ok := nextB()
+			if !ok || !yield(valueA, valueB) {
+				return
+			}
+		}
+	}
+}
+
+// Enumerate yields the values of s with their index, like range over a slice
+func Enumerate[T any](s iter.Seq[T]) iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		index := 0
+		for value := range s {
+			if !yield(index, value) {
+				return
+			}
+			index++
+		}
+	}
+}
+
+// Chunk yields the values of s in consecutive slices of size values, the last
+// one can be shorter; every chunk is a new slice the caller can keep
+// like slices.Chunk, it panics if size is less than 1
+func Chunk[T any](s iter.Seq[T], size int) iter.Seq[[]T] {
+	if size < 1 {
+		panic("seq: chunk size must be positive")
+	}
+	return func(yield func([]T) bool) {
+		chunk := make([]T, 0, size)
+		for value := range s {
+			chunk = append(chunk, value)
+			if len(chunk) == size {
+				if !yield(chunk) {
+					return
+				}
+				chunk = make([]T, 0, size)
+			}
+		}
+		if len(chunk) > 0 {
+			yield(chunk)
+		}
+	}
+}
+
+// Window yields every run of size consecutive values of s, sliding by one value:
+// Window of 1 2 3 4 with size 3 yields [1 2 3] and [2 3 4]
+// nothing is yielded when s has less than size values
+// every window is a new slice the caller can keep, it panics if size is less than 1
+func Window[T any](s iter.Seq[T], size int) iter.Seq[[]T] {
+	if size < 1 {
+		panic("seq: window size must be positive")
+	}
+	return func(yield func([]T) bool) {
+		window := make([]T, 0, size)
+		for value := range s {
+			if len(window) == size {
+				// drop the oldest value into a new slice, the previous window
+				// belongs to the caller now
+				window = append(make([]T, 0, size), window[1:]...)
+			}
+			window = append(window, value)
+			if len(window) == size && !yield(window) {
+				return
+			}
+		}
+	}
+}
+
+// Reduce folds the values of s into an accumulator, starting from initial:
+// it is aggregate in main for any number of values
+func Reduce[T, A any](s iter.Seq[T], initial A, f func(A, T) A) A {
+	accumulator := initial
+	for value := range s {
+		accumulator = f(accumulator, value)
+	}
+	return accumulator
+}
+
+// Count returns the number of values of s
+func Count[T any](s iter.Seq[T]) int {
+	count := 0
+	for range s {
+		count++
+	}
+	return count
+}
+
+// Any reports whether match returns true for a value of s, it stops at the first one
+func Any[T any](s iter.Seq[T], match func(T) bool) bool {
+	for value := range s {
+		if match(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// All reports whether match returns true for every value of s, it stops at the first miss
+func All[T any](s iter.Seq[T], match func(T) bool) bool {
+	for value := range s {
+		if !match(value) {
+			return false
+		}
+	}
+	return true
+}
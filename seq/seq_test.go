@@ -0,0 +1,162 @@
+package seq
+
+import (
+	"iter"
+	"maps"
+	"math"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+func isEven(n int) bool { return n%2 == 0 }
+
+func TestStages(t *testing.T) {
+	tests := []struct {
+		name string
+		got  iter.Seq[int]
+		want []int
+	}{
+		{"Range", Range(3, 7), []int{3, 4, 5, 6}},
+		{"empty Range", Range(7, 3), nil},
+		{"Iterate", Take(Iterate(1, func(n int) int { return n * 3 }), 4), []int{1, 3, 9, 27}},
+		{"Map", Map(Range(1, 4), func(n int) int { return n * n }), []int{1, 4, 9}},
+		{"Filter", Filter(Range(1, 10), isEven), []int{2, 4, 6, 8}},
+		{"FlatMap", FlatMap(Range(1, 4), func(n int) iter.Seq[int] { return Range(0, n) }), []int{0, 0, 1, 0, 1, 2}},
+		{"Take", Take(Range(0, 100), 3), []int{0, 1, 2}},
+		{"Take 0", Take(Range(0, 100), 0), nil},
+		{"Take more", Take(Range(0, 2), 5), []int{0, 1}},
+		{"Skip", Skip(Range(0, 5), 3), []int{3, 4}},
+		{"Skip all", Skip(Range(0, 5), 9), nil},
+		{"Skip negative", Skip(Range(0, 2), -1), []int{0, 1}},
+	}
+	for _, test := range tests {
+		if got := slices.Collect(test.got); !slices.Equal(got, test.want) {
+			t.Errorf("%s = %v, want %v", test.name, got, test.want)
+		}
+		// ranging over a stage again ranges over its source again
+		if again := slices.Collect(test.got); !slices.Equal(again, test.want) {
+			t.Errorf("%s the second time = %v, want %v", test.name, again, test.want)
+		}
+	}
+
+	// no overflow at the end of the type
+	if got := slices.Collect(Range[int8](125, math.MaxInt8)); !slices.Equal(got, []int8{125, 126}) {
+		t.Errorf("Range(125, 127) = %v", got)
+	}
+}
+
+func TestLaziness(t *testing.T) {
+	generated := 0
+	source := Map(Range(1, 1_000_000), func(n int) int { generated++; return n })
+	first := slices.Collect(Take(Map(Filter(source, isEven), func(n int) int { return n * n }), 3))
+	if !slices.Equal(first, []int{4, 16, 36}) || generated != 6 {
+		t.Errorf("first 3 even squares = %v, after generating %d values, want [4 16 36] after 6", first, generated)
+	}
+
+	generated = 0
+	if !Any(source, func(n int) bool { return n == 5 }) || generated != 5 {
+		t.Errorf("Any stopped after %d values, want 5", generated)
+	}
+	generated = 0
+	if All(source, func(n int) bool { return n < 3 }) || generated != 3 {
+		t.Errorf("All stopped after %d values, want 3", generated)
+	}
+}
+
+func TestZipEnumerate(t *testing.T) {
+	letters := slices.Values([]string{"a", "b", "c"})
+	var zipped []string
+	for n, letter := range Zip(Range(1, 100), letters) {
+		zipped = append(zipped, strconv.Itoa(n)+letter)
+	}
+	if !slices.Equal(zipped, []string{"1a", "2b", "3c"}) {
+		t.Errorf("Zip = %v", zipped)
+	}
+	for n, letter := range Zip(Range(1, 100), letters) {
+		if n != 1 || letter != "a" {
+			t.Errorf("Zip first pair = %d, %s", n, letter)
+		}
+		break
+	}
+
+	if got := maps.Collect(Enumerate(letters)); !maps.Equal(got, map[int]string{0: "a", 1: "b", 2: "c"}) {
+		t.Errorf("Enumerate = %v", got)
+	}
+}
+
+func TestChunkWindow(t *testing.T) {
+	tests := []struct {
+		name string
+		got  iter.Seq[[]int]
+		want [][]int
+	}{
+		{"Chunk", Chunk(Range(1, 8), 3), [][]int{{1, 2, 3}, {4, 5, 6}, {7}}},
+		{"Chunk exact", Chunk(Range(1, 5), 2), [][]int{{1, 2}, {3, 4}}},
+		{"Chunk empty", Chunk(Range(0, 0), 2), nil},
+		{"Window", Window(Range(1, 5), 3), [][]int{{1, 2, 3}, {2, 3, 4}}},
+		{"Window short", Window(Range(1, 3), 3), nil},
+		{"Window 1", Window(Range(1, 3), 1), [][]int{{1}, {2}}},
+	}
+	for _, test := range tests {
+		// collected, so every slice must still hold its values after the next ones
+		got := slices.Collect(test.got)
+		if !slices.EqualFunc(got, test.want, slices.Equal) {
+			t.Errorf("%s = %v, want %v", test.name, got, test.want)
+		}
+	}
+
+	for _, size := range []int{0, -1} {
+		for name, build := range map[string]func(){
+			"Chunk":  func() { Chunk(Range(0, 1), size) },
+			"Window": func() { Window(Range(0, 1), size) },
+		} {
+			func() {
+				defer func() {
+					if recover() == nil {
+						t.Errorf("%s with size %d didn't panic", name, size)
+					}
+				}()
+				build()
+			}()
+		}
+	}
+}
+
+func TestReduceCount(t *testing.T) {
+	if sum := Reduce(Range(1, 101), 0, func(total, n int) int { return total + n }); sum != 5050 {
+		t.Errorf("Reduce sum = %d, want 5050", sum)
+	}
+	joined := Reduce(Range(1, 4), "", func(text string, n int) string { return text + strconv.Itoa(n) })
+	if joined != "123" {
+		t.Errorf("Reduce join = %q", joined)
+	}
+	if count := Count(Filter(Range(0, 10), isEven)); count != 5 {
+		t.Errorf("Count = %d, want 5", count)
+	}
+}
+
+func TestCollectors(t *testing.T) {
+	words := slices.Values([]string{"apple", "avocado", "banana", "blueberry", "cherry", "apple"})
+	first := func(word string) byte { return word[0] }
+
+	groups := GroupBy(words, first)
+	want := map[byte][]string{'a': {"apple", "avocado", "apple"}, 'b': {"banana", "blueberry"}, 'c': {"cherry"}}
+	if !maps.EqualFunc(groups, want, slices.Equal) {
+		t.Errorf("GroupBy = %v, want %v", groups, want)
+	}
+	if byLetter := ToMapBy(words, first); !maps.Equal(byLetter, map[byte]string{'a': "apple", 'b': "blueberry", 'c': "cherry"}) {
+		t.Errorf("ToMapBy = %v", byLetter)
+	}
+	if counts := Frequencies(words); counts["apple"] != 2 || counts["cherry"] != 1 || len(counts) != 5 {
+		t.Errorf("Frequencies = %v", counts)
+	}
+	if pairs := maps.Collect(Enumerate(words)); len(pairs) != 6 || pairs[5] != "apple" {
+		t.Errorf("maps.Collect(Enumerate) = %v", pairs)
+	}
+
+	kept, rest := Partition(Range(1, 7), isEven)
+	if !slices.Equal(kept, []int{2, 4, 6}) || !slices.Equal(rest, []int{1, 3, 5}) {
+		t.Errorf("Partition = %v, %v", kept, rest)
+	}
+}
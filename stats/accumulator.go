@@ -0,0 +1,122 @@
+package stats
+
+import "math"
+
+/*
+
+Accumulator computes the count, mean, variance, min and max of a stream of
+values in constant memory, so inputs too large to be held in memory can still
+be summarised. It uses Welford's online algorithm:
+
+	for every new value x:
+		count += 1
+		delta := x - mean
+		mean  += delta / count
+		m2    += delta * (x - mean)  // m2 is the sum of squared distances from the mean
+
+	variance = m2 / count
+
+Two accumulators can be merged, so a large input can be split in chunks
+summarised in parallel.
+
+The zero value is an empty accumulator ready to use.
+An Accumulator isn't safe for concurrent use, give every goroutine its own
+and Merge them at the end.
+
+*/
+
+type Accumulator struct {
+	count    int
+	mean, m2 float64
+	min, max float64
+}
+
+// Add adds value to the stream
+func (acc *Accumulator) Add(value float64) {
+	if acc.count == 0 {
+		acc.min, acc.max = value, value
+	} else {
+		acc.min = math.Min(acc.min, value)
+		acc.max = math.Max(acc.max, value)
+	}
+	acc.count++
+	delta := value - acc.mean
+	acc.mean += delta / float64(acc.count)
+	acc.m2 += delta * (value - acc.mean)
+}
+
+// Merge adds all the values of other to acc, as if they had been added one by one
+func (acc *Accumulator) Merge(other *Accumulator) {
+	if other.count == 0 {
+		return
+	}
+	if acc.count == 0 {
+		*acc = *other
+		return
+	}
+
+	// Chan et al.'s formula for combining two partial results
+	count := acc.count + other.count
+	delta := other.mean - acc.mean
+	acc.mean += delta * float64(other.count) / float64(count)
+	acc.m2 += other.m2 + delta*delta*float64(acc.count)*float64(other.count)/float64(count)
+	acc.min = math.Min(acc.min, other.min)
+	acc.max = math.Max(acc.max, other.max)
+	acc.count = count
+}
+
+// Reset empties acc
+func (acc *Accumulator) Reset() {
+	*acc = Accumulator{}
+}
+
+// Count returns the number of values added
+func (acc *Accumulator) Count() int {
+	return acc.count
+}
+
+// Mean returns the mean of the values, 0 if there are none
+func (acc *Accumulator) Mean() float64 {
+	return acc.mean
+}
+
+// Sum returns the sum of the values
+func (acc *Accumulator) Sum() float64 {
+	return acc.mean * float64(acc.count)
+}
+
+// Variance returns the population variance of the values, 0 if there are none
+func (acc *Accumulator) Variance() float64 {
+	if acc.count == 0 {
+		return 0
+	}
+	return acc.m2 / float64(acc.count)
+}
+
+// SampleVariance returns the sample variance of the values, 0 if there are fewer than two
+func (acc *Accumulator) SampleVariance() float64 {
+	if acc.count < 2 {
+		return 0
+	}
+	return acc.m2 / float64(acc.count-1)
+}
+
+// StdDev returns the population standard deviation of the values
+func (acc *Accumulator) StdDev() float64 {
+	return math.Sqrt(acc.Variance())
+}
+
+// SampleStdDev returns the sample standard deviation of the values
+func (acc *Accumulator) SampleStdDev() float64 {
+	return math.Sqrt(acc.SampleVariance())
+}
+
+// Min returns the smallest value, 0 if there are none
+func (acc *Accumulator) Min() float64 {
+	return acc.min
+}
+
+// Max returns the largest value, 0 if there are none
+func (acc *Accumulator) Max() float64 {
+	return acc.max
+}
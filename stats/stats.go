@@ -0,0 +1,211 @@
+package stats
+
+import (
+	"errors"
+	mathutil "first/mathUtil"
+	"fmt"
+	"math"
+	"slices"
+)
+
+/*
+
+Descriptive statistics over any real number type.
+
+Like sums in main, every function takes its values as variadic arguments, so
+they can be called with literal values or with a slice expanded with ...
+
+	stats.Mean(1, 2, 3, 4)
+	stats.Median(values...)
+
+The results are float64, whatever the input type is, since the mean of ints
+is rarely an int. None of the functions modifies the values passed in.
+
+The variance functions use Welford's algorithm (see Accumulator), which doesn't
+lose precision when the values are large and close to each other, unlike the
+textbook sum(x²)/n - mean² formula.
+
+*/
+
+var (
+	ErrEmpty             = errors.New("stats: no values")
+	ErrTooFew            = errors.New("stats: need at least 2 values")
+	ErrInvalidPercentile = errors.New("stats: percentile must be between 0 and 100")
+	ErrInvalidBins       = errors.New("stats: number of bins must be positive")
+	ErrNotFinite         = errors.New("stats: values must be finite")
+)
+
+// Sum returns the sum of values, 0 if there are none
+// like sums in main it wraps around on integer overflow
+func Sum[T mathutil.Real](values ...T) (total T) {
+	for _, value := range values {
+		total += value
+	}
+	return total
+}
+
+// Mean returns the arithmetic mean of values
+func Mean[T mathutil.Real](values ...T) (float64, error) {
+	if len(values) == 0 {
+		return 0, ErrEmpty
+	}
+	return accumulate(values).Mean(), nil
+}
+
+// sorted returns a sorted copy of values
+func sorted[T mathutil.Real](values []T) []T {
+	copied := slices.Clone(values)
+	slices.Sort(copied)
+	return copied
+}
+
+// Median returns the middle value of values, or the mean of the two middle
+// values if there is an even number of them
+func Median[T mathutil.Real](values ...T) (float64, error) {
+	return Percentile(50, values...)
+}
+
+// Mode returns the most frequent values, sorted, since there can be more than one
+func Mode[T mathutil.Real](values ...T) ([]T, error) {
+	if len(values) == 0 {
+		return nil, ErrEmpty
+	}
+
+	counts := make(map[T]int)
+	best := 0
+	for _, value := range values {
+		counts[value]++
+		best = max(best, counts[value])
+	}
+
+	var modes []T
+	for value, count := range counts {
+		if count == best {
+			modes = append(modes, value)
+		}
+	}
+	slices.Sort(modes)
+	return modes, nil
+}
+
+// Variance returns the population variance of values, the mean squared distance from the mean
+func Variance[T mathutil.Real](values ...T) (float64, error) {
+	if len(values) == 0 {
+		return 0, ErrEmpty
+	}
+	return accumulate(values).Variance(), nil
+}
+
+// SampleVariance returns the variance of values as an estimate of the variance of
+// the population they were drawn from, dividing by n - 1 instead of n
+// it fails with ErrEmpty without values and ErrTooFew with a single one
+func SampleVariance[T mathutil.Real](values ...T) (float64, error) {
+	if len(values) == 0 {
+		return 0, ErrEmpty
+	}
+	if len(values) < 2 {
+		return 0, ErrTooFew
+	}
+	return accumulate(values).SampleVariance(), nil
+}
+
+// StdDev returns the population standard deviation of values
+func StdDev[T mathutil.Real](values ...T) (float64, error) {
+	variance, err := Variance(values...)
+	return math.Sqrt(variance), err
+}
+
+// SampleStdDev returns the sample standard deviation of values
+func SampleStdDev[T mathutil.Real](values ...T) (float64, error) {
+	variance, err := SampleVariance(values...)
+	return math.Sqrt(variance), err
+}
+
+// Percentile returns the p-th percentile of values, for p between 0 and 100
+// values between two data points are linearly interpolated, so Percentile(50, ...)
+// is the median and Percentile(0, ...) and Percentile(100, ...) are the min and max
+func Percentile[T mathutil.Real](p float64, values ...T) (float64, error) {
+	results, err := Percentiles([]float64{p}, values...)
+	if err != nil {
+		return 0, err
+	}
+	return results[0], nil
+}
+
+// Percentiles returns the percentiles ps of values, sorting them only once
+func Percentiles[T mathutil.Real](ps []float64, values ...T) ([]float64, error) {
+	if len(values) == 0 {
+		return nil, ErrEmpty
+	}
+	for _, p := range ps {
+		if !(p >= 0 && p <= 100) { // also rejects NaN
+			return nil, ErrInvalidPercentile
+		}
+	}
+
+	data := sorted(values)
+	results := make([]float64, len(ps))
+	for index, p := range ps {
+		// the rank of the percentile in the sorted data, between two indices in general
+		rank := p / 100 * float64(len(data)-1)
+		low := int(math.Floor(rank))
+		high := int(math.Ceil(rank))
+		fraction := rank - float64(low)
+		results[index] = float64(data[low]) + fraction*(float64(data[high])-float64(data[low]))
+	}
+	return results, nil
+}
+
+// Bin is a bucket of a histogram, counting the values in [Low, High)
+// the last bin of a histogram also includes its High bound
+type Bin struct {
+	Low, High float64
+	Count     int
+}
+
+// Histogram splits the range of values into bins buckets of equal width and
+// counts how many values fall in each
+// NaN and infinite values have no bin, they fail with ErrNotFinite, and so do
+// values so far apart that the width of their range overflows
+func Histogram[T mathutil.Real](bins int, values ...T) ([]Bin, error) {
+	if bins <= 0 {
+		return nil, ErrInvalidBins
+	}
+	if len(values) == 0 {
+		return nil, ErrEmpty
+	}
+	for _, value := range values {
+		if math.IsNaN(float64(value)) || math.IsInf(float64(value), 0) {
+			return nil, fmt.Errorf("%w: %v", ErrNotFinite, value)
+		}
+	}
+
+	low, high := float64(slices.Min(values)), float64(slices.Max(values))
+	if math.IsInf(high-low, 0) {
+		return nil, fmt.Errorf("%w: the range from %v to %v overflows", ErrNotFinite, low, high)
+	}
+	width := (high - low) / float64(bins)
+	histogram := make([]Bin, bins)
+	for index := range histogram {
+		histogram[index].Low = low + float64(index)*width
+		histogram[index].High = low + float64(index+1)*width
+	}
+	histogram[bins-1].High = high
+
+	for _, value := range values {
+		index := bins - 1
+		if width > 0 {
+			index = min(int((float64(value)-low)/width), bins-1)
+		}
+		histogram[index].Count++
+	}
+	return histogram, nil
+}
+
+func accumulate[T mathutil.Real](values []T) *Accumulator {
+	accumulator := &Accumulator{}
+	for _, value := range values {
+		accumulator.Add(float64(value))
+	}
+	return accumulator
+}
@@ -0,0 +1,166 @@
+package stats
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"testing"
+)
+
+// near reports whether a and b agree to about 12 significant digits
+func near(a, b float64) bool {
+	return math.Abs(a-b) <= 1e-12*math.Max(1, math.Max(math.Abs(a), math.Abs(b)))
+}
+
+func TestDescriptive(t *testing.T) {
+	tests := []struct {
+		values                            []float64
+		mean, median, variance, sampleVar float64
+	}{
+		{[]float64{2, 4, 4, 4, 5, 5, 7, 9}, 5, 4.5, 4, 32.0 / 7},
+		{[]float64{1, 2, 3}, 2, 2, 2.0 / 3, 1},
+		{[]float64{-5, 5}, 0, 0, 25, 50},
+		// large and close together, where sum(x²)/n - mean² loses every digit
+		{[]float64{1e9 + 4, 1e9 + 7, 1e9 + 13, 1e9 + 16}, 1e9 + 10, 1e9 + 10, 22.5, 30},
+	}
+	for _, test := range tests {
+		mean, _ := Mean(test.values...)
+		median, _ := Median(test.values...)
+		variance, _ := Variance(test.values...)
+		sampleVar, _ := SampleVariance(test.values...)
+		if !near(mean, test.mean) || !near(median, test.median) ||
+			!near(variance, test.variance) || !near(sampleVar, test.sampleVar) {
+			t.Errorf("%v: mean %v median %v variance %v sample %v, want %v %v %v %v", test.values,
+				mean, median, variance, sampleVar, test.mean, test.median, test.variance, test.sampleVar)
+		}
+	}
+
+	if mean, _ := Mean(1, 2); mean != 1.5 {
+		t.Errorf("Mean of ints = %v, want 1.5", mean)
+	}
+	if sum := Sum[int8](100, 100); sum != -56 {
+		t.Errorf("Sum[int8](100, 100) = %d, want -56 (wrapped)", sum)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{"Mean", errOf(Mean[int]()), ErrEmpty},
+		{"Median", errOf(Median[int]()), ErrEmpty},
+		{"Variance", errOf(Variance[int]()), ErrEmpty},
+		{"SampleVariance empty", errOf(SampleVariance[int]()), ErrEmpty},
+		{"SampleVariance single", errOf(SampleVariance(3)), ErrTooFew},
+		{"SampleStdDev single", errOf(SampleStdDev(3)), ErrTooFew},
+		{"Percentile -1", errOf(Percentile(-1, 1, 2)), ErrInvalidPercentile},
+		{"Percentile NaN", errOf(Percentile(math.NaN(), 1, 2)), ErrInvalidPercentile},
+	}
+	for _, test := range tests {
+		if !errors.Is(test.err, test.want) {
+			t.Errorf("%s: error %v, want %v", test.name, test.err, test.want)
+		}
+	}
+	if _, err := Mode[int](); !errors.Is(err, ErrEmpty) {
+		t.Errorf("Mode(): error %v, want ErrEmpty", err)
+	}
+	if _, err := Histogram(0, 1, 2); !errors.Is(err, ErrInvalidBins) {
+		t.Errorf("Histogram(0): error %v, want ErrInvalidBins", err)
+	}
+}
+
+func errOf(_ float64, err error) error {
+	return err
+}
+
+func TestPercentiles(t *testing.T) {
+	values := []int{15, 20, 35, 40, 50}
+	got, err := Percentiles([]float64{0, 25, 40, 50, 100}, values...)
+	want := []float64{15, 20, 29, 35, 50}
+	if err != nil || !slices.Equal(got, want) {
+		t.Errorf("Percentiles = %v, %v, want %v", got, err, want)
+	}
+	if !slices.Equal(values, []int{15, 20, 35, 40, 50}) {
+		t.Errorf("Percentiles changed its input: %v", values)
+	}
+}
+
+func TestMode(t *testing.T) {
+	tests := []struct {
+		values []int
+		want   []int
+	}{
+		{[]int{1, 2, 2, 3}, []int{2}},
+		{[]int{3, 1, 3, 1, 2}, []int{1, 3}},
+		{[]int{7}, []int{7}},
+	}
+	for _, test := range tests {
+		if got, err := Mode(test.values...); err != nil || !slices.Equal(got, test.want) {
+			t.Errorf("Mode(%v) = %v, %v, want %v", test.values, got, err, test.want)
+		}
+	}
+}
+
+func TestHistogram(t *testing.T) {
+	tests := []struct {
+		bins   int
+		values []float64
+		want   []Bin
+	}{
+		{2, []float64{0, 1, 2, 3, 4}, []Bin{{0, 2, 2}, {2, 4, 3}}},
+		{4, []float64{0, 0.5, 1}, []Bin{{0, 0.25, 1}, {0.25, 0.5, 0}, {0.5, 0.75, 1}, {0.75, 1, 1}}},
+		// every value equal, they all land in the last bin
+		{3, []float64{5, 5}, []Bin{{5, 5, 0}, {5, 5, 0}, {5, 5, 2}}},
+	}
+	for _, test := range tests {
+		if got, err := Histogram(test.bins, test.values...); err != nil || !slices.Equal(got, test.want) {
+			t.Errorf("Histogram(%d, %v) = %v, %v, want %v", test.bins, test.values, got, err, test.want)
+		}
+	}
+
+	failures := [][]float64{
+		{1, math.Inf(1)},
+		{math.Inf(-1), 1},
+		{math.NaN()},
+		{1, math.NaN(), 2},
+		{-math.MaxFloat64, math.MaxFloat64},
+	}
+	for _, values := range failures {
+		if got, err := Histogram(3, values...); !errors.Is(err, ErrNotFinite) {
+			t.Errorf("Histogram(3, %v) = %v, %v, want ErrNotFinite", values, got, err)
+		}
+	}
+}
+
+func TestAccumulatorMerge(t *testing.T) {
+	values := []float64{3, -1, 4, 1, -5, 9, 2, 6, 5, 3, 5}
+	var whole Accumulator
+	for _, value := range values {
+		whole.Add(value)
+	}
+	for split := range len(values) + 1 {
+		var left, right Accumulator
+		for _, value := range values[:split] {
+			left.Add(value)
+		}
+		for _, value := range values[split:] {
+			right.Add(value)
+		}
+		left.Merge(&right)
+		if left.Count() != whole.Count() || !near(left.Mean(), whole.Mean()) ||
+			!near(left.Variance(), whole.Variance()) || left.Min() != -5 || left.Max() != 9 {
+			t.Errorf("merged at %d: %+v, want %+v", split, left, whole)
+		}
+	}
+
+	var empty Accumulator
+	if empty.Variance() != 0 || empty.SampleVariance() != 0 || empty.Mean() != 0 {
+		t.Errorf("empty accumulator = %+v", empty)
+	}
+	whole.Reset()
+	if whole != empty {
+		t.Errorf("Reset left %+v", whole)
+	}
+}
@@ -0,0 +1,300 @@
+package store
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+/*
+
+File is a UserStore keeping a log of every change, one JSON record per line:
+
+	{"op":"put","user":{"username":"raj","password":"$pbkdf2-sha256$...",...}}
+	{"op":"delete","username":"raj"}
+
+A change is written and synced to the log before it is applied in memory, so
+every change a method reported as done survives a crash. Open replays the log
+to rebuild the users; a crash in the middle of a write leaves a partial last
+line, which Open drops, like the change it never reported as done. A write
+that fails without a crash is cut off the log the same way, right away, so
+the next records don't follow a partial line.
+
+The log grows with every change while the users don't, so it is compacted
+from time to time: once it holds more than compactRatio records per user (and
+at least minCompact), it is replaced by a log with a single put per user,
+written next to it and renamed over it. Compact does it on demand.
+
+An automatic compaction happens after a change is safe in the log, so its
+failure doesn't fail the change: the log just stays longer until the next
+one. The store doesn't log it either, CompactErr reports it.
+
+*/
+
+var ErrCorrupt = errors.New("store: corrupt log")
+
+const (
+	compactRatio = 2
+	minCompact   = 1000
+)
+
+const (
+	opPut    = "put"
+	opDelete = "delete"
+)
+
+type record struct {
+	Op       string `json:"op"`
+	User     *User  `json:"user,omitempty"`
+	Username string `json:"username,omitempty"`
+}
+
+type File struct {
+	path string
+
+	mu      sync.RWMutex
+	table   table
+	log     *os.File
+	records int // in the log, to know when to compact
+	// of the last automatic compaction, nil if it succeeded
+	compactErr error
+}
+
+// Open returns the store logged in the file at path, created if needed
+func Open(path string) (*File, error) {
+	log, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	file := &File{path: path, table: newTable(), log: log}
+	if err := file.replay(); err != nil {
+		log.Close()
+		return nil, err
+	}
+	return file, nil
+}
+
+// replay applies the records of the log, and leaves it positioned at the end
+// of the last complete one for the next appends
+func (file *File) replay() error {
+	reader := bufio.NewReader(file.log)
+	var offset int64
+	for line := 1; ; line++ {
+		data, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			// a partial last line is a write cut short by a crash, drop it
+			if err := file.log.Truncate(offset); err != nil {
+				return err
+			}
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		var entry record
+		if err := json.Unmarshal(data, &entry); err != nil {
+			return fmt.Errorf("%w: %s line %d: %v", ErrCorrupt, file.path, line, err)
+		}
+		switch {
+		case entry.Op == opPut && entry.User != nil:
+			file.table.users[entry.User.Username] = *entry.User
+		case entry.Op == opDelete:
+			delete(file.table.users, entry.Username)
+		default:
+			return fmt.Errorf("%w: %s line %d: %s", ErrCorrupt, file.path, line, bytes.TrimSpace(data))
+		}
+		offset += int64(len(data))
+		file.records++
+	}
+	_, err := file.log.Seek(offset, io.SeekStart)
+	return err
+}
+
+// apply logs entry, then applies it to the users
+func (file *File) apply(entry record) error {
+	if file.log == nil {
+		return os.ErrClosed
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	offset, err := file.log.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	_, err = file.log.Write(append(data, '\n'))
+	if err == nil {
+		err = file.log.Sync()
+	}
+	if err != nil {
+		// drop whatever part of the record made it to the log, or the next
+		// record would follow a partial line and Open would reject the log
+		if truncateErr := file.log.Truncate(offset); truncateErr == nil {
+			file.log.Seek(offset, io.SeekStart)
+		}
+		return err
+	}
+	file.records++
+
+	if entry.Op == opPut {
+		file.table.users[entry.User.Username] = *entry.User
+	} else {
+		delete(file.table.users, entry.Username)
+	}
+
+	if file.records >= minCompact && file.records > compactRatio*len(file.table.users) {
+		file.compactErr = file.compact()
+	}
+	return nil
+}
+
+func (file *File) put(user User, err error) (User, error) {
+	if err != nil {
+		return User{}, err
+	}
+	if err := file.apply(record{Op: opPut, User: &user}); err != nil {
+		return User{}, err
+	}
+	return user, nil
+}
+
+func (file *File) Create(user User) (User, error) {
+	file.mu.Lock()
+	defer file.mu.Unlock()
+	return file.put(file.table.create(user))
+}
+
+func (file *File) Get(username string) (User, error) {
+	file.mu.RLock()
+	defer file.mu.RUnlock()
+	return file.table.get(username)
+}
+
+func (file *File) Update(user User) (User, error) {
+	file.mu.Lock()
+	defer file.mu.Unlock()
+	return file.put(file.table.update(user))
+}
+
+func (file *File) Deactivate(username string) (User, error) {
+	file.mu.Lock()
+	defer file.mu.Unlock()
+	existing, err := file.table.get(username)
+	if err != nil || !existing.Active {
+		// nothing changes, nothing to log
+		return existing, err
+	}
+	return file.put(file.table.deactivate(username))
+}
+
+func (file *File) Delete(username string) error {
+	file.mu.Lock()
+	defer file.mu.Unlock()
+	if _, err := file.table.get(username); err != nil {
+		return err
+	}
+	return file.apply(record{Op: opDelete, Username: username})
+}
+
+func (file *File) List(after string, limit int) ([]User, error) {
+	file.mu.RLock()
+	defer file.mu.RUnlock()
+	return file.table.list(after, limit)
+}
+
+// CompactErr returns the error of the last compaction done automatically
+// after a change, nil if there was none or it succeeded
+func (file *File) CompactErr() error {
+	file.mu.RLock()
+	defer file.mu.RUnlock()
+	return file.compactErr
+}
+
+// Compact rewrites the log with a single record per user
+func (file *File) Compact() error {
+	file.mu.Lock()
+	defer file.mu.Unlock()
+	if file.log == nil {
+		return os.ErrClosed
+	}
+	return file.compact()
+}
+
+// compact writes the users to a new log and renames it over the current one
+// the current log is closed for the rename, which Windows refuses on an open
+// file, and the log at path is reopened whatever happened: the new one, or
+// the old one, still complete, if the rename failed
+func (file *File) compact() error {
+	dir := filepath.Dir(file.path)
+	temp, err := os.CreateTemp(dir, filepath.Base(file.path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name()) // only there if the rename didn't happen
+
+	writer := bufio.NewWriter(temp)
+	encoder := json.NewEncoder(writer)
+	for _, user := range file.table.users {
+		if err := encoder.Encode(record{Op: opPut, User: &user}); err != nil {
+			temp.Close()
+			return err
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		temp.Close()
+		return err
+	}
+	if err := temp.Sync(); err != nil {
+		temp.Close()
+		return err
+	}
+	if err := temp.Close(); err != nil {
+		return err
+	}
+
+	file.log.Close()
+	renameErr := os.Rename(temp.Name(), file.path)
+	if renameErr == nil {
+		file.records = len(file.table.users)
+		// a crash before the directory is synced can bring the old log back,
+		// which holds the same users; some systems can't sync a directory
+		if dirFile, err := os.Open(dir); err == nil {
+			dirFile.Sync()
+			dirFile.Close()
+		}
+	}
+
+	log, err := os.OpenFile(file.path, os.O_RDWR, 0)
+	if err == nil {
+		if _, err = log.Seek(0, io.SeekEnd); err != nil {
+			log.Close()
+		}
+	}
+	if err != nil {
+		// without a log the store is closed, like after Close
+		file.log = nil
+		return fmt.Errorf("store: reopening %s after compaction: %w", file.path, err)
+	}
+	file.log = log
+	return renameErr
+}
+
+// Close closes the log, the store can't be used afterwards
+func (file *File) Close() error {
+	file.mu.Lock()
+	defer file.mu.Unlock()
+	if file.log == nil {
+		return os.ErrClosed
+	}
+	err := file.log.Close()
+	file.log = nil
+	return err
+}
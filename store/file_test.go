@@ -0,0 +1,172 @@
+package store
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func openFile(t *testing.T, path string) *File {
+	t.Helper()
+	file, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func TestReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "users.log")
+	file := openFile(t, path)
+	raj, _ := file.Create(User{Username: "raj", Password: "a", Active: true})
+	file.Create(User{Username: "sam", Active: true})
+	raj, _ = file.Update(User{Username: "raj", Password: "b", Active: true})
+	file.Delete("sam")
+	file.Deactivate("nobody")
+	file.Close()
+
+	reopened := openFile(t, path)
+	defer reopened.Close()
+	if got, err := reopened.Get("raj"); err != nil || got != raj {
+		t.Errorf("Get after reopening = %+v, %v, want %+v", got, err, raj)
+	}
+	if _, err := reopened.Get("sam"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("a deleted user is back after reopening: %v", err)
+	}
+	if reopened.records != 4 {
+		t.Errorf("%d records replayed, want 4", reopened.records)
+	}
+}
+
+func TestTornWrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "users.log")
+	file := openFile(t, path)
+	file.Create(User{Username: "raj"})
+	file.Close()
+
+	// a crash in the middle of the second record
+	content, _ := os.ReadFile(path)
+	torn := `{"op":"put","user":{"username":"zed","pass`
+	os.WriteFile(path, append(content, torn...), 0o600)
+
+	file = openFile(t, path)
+	if _, err := file.Get("raj"); err != nil {
+		t.Errorf("the complete record was lost: %v", err)
+	}
+	// the next record must start on a line of its own
+	if _, err := file.Create(User{Username: "sam"}); err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+
+	after, _ := os.ReadFile(path)
+	if strings.Contains(string(after), torn) {
+		t.Errorf("the partial line is still in the log:\n%s", after)
+	}
+	file = openFile(t, path)
+	defer file.Close()
+	if page, _ := file.List("", 10); !sameUsernames(page, []string{"raj", "sam"}) {
+		t.Errorf("users after reopening = %v", page)
+	}
+}
+
+func TestCorruptLog(t *testing.T) {
+	tests := []string{
+		"not json\n",
+		`{"op":"put"}` + "\n",
+		`{"op":"rename","username":"raj"}` + "\n",
+	}
+	for _, content := range tests {
+		path := filepath.Join(t.TempDir(), "users.log")
+		os.WriteFile(path, []byte(content), 0o600)
+		if _, err := Open(path); !errors.Is(err, ErrCorrupt) {
+			t.Errorf("Open with %q error %v, want ErrCorrupt", content, err)
+		}
+	}
+}
+
+func TestCompaction(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "users.log")
+	file := openFile(t, path)
+	file.Create(User{Username: "raj"})
+	file.Create(User{Username: "sam"})
+	for range minCompact {
+		file.Update(User{Username: "raj", Active: true})
+	}
+	// compacted on the way, so well below minCompact records
+	if file.records >= minCompact {
+		t.Errorf("%d records in the log, it was never compacted", file.records)
+	}
+	raj, _ := file.Get("raj")
+	if err := file.Compact(); err != nil {
+		t.Fatal(err)
+	}
+	if file.records != 2 {
+		t.Errorf("%d records after Compact, want 2", file.records)
+	}
+	// the compacted log is the one appended to
+	file.Delete("sam")
+	file.Close()
+
+	if leftovers, _ := filepath.Glob(path + ".tmp*"); len(leftovers) != 0 {
+		t.Errorf("temporary files left behind: %v", leftovers)
+	}
+	reopened := openFile(t, path)
+	defer reopened.Close()
+	if got, err := reopened.Get("raj"); err != nil || got != raj {
+		t.Errorf("Get after compaction = %+v, %v, want %+v", got, err, raj)
+	}
+	if _, err := reopened.Get("sam"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("a user deleted after compaction is back: %v", err)
+	}
+	if reopened.records != 3 {
+		t.Errorf("%d records after reopening, want 3", reopened.records)
+	}
+}
+
+func TestCompactionFailure(t *testing.T) {
+	var logged bytes.Buffer
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logged, nil)))
+
+	// the log stays open, but no temporary file can be created next to it
+	dir := filepath.Join(t.TempDir(), "gone")
+	os.Mkdir(dir, 0o700)
+	file := openFile(t, filepath.Join(dir, "users.log"))
+	defer file.Close()
+	os.RemoveAll(dir)
+
+	file.Create(User{Username: "raj"})
+	for index := range minCompact {
+		if _, err := file.Update(User{Username: "raj", Active: index%2 == 0}); err != nil {
+			t.Fatalf("Update %d failed with the compaction: %v", index, err)
+		}
+	}
+	if err := file.CompactErr(); err == nil {
+		t.Error("CompactErr = nil after a failed compaction")
+	}
+	if err := file.Compact(); err == nil {
+		t.Error("Compact succeeded without a directory")
+	}
+	if logged.Len() != 0 {
+		t.Errorf("the store logged %q", logged.String())
+	}
+}
+
+func TestClosed(t *testing.T) {
+	file := openFile(t, filepath.Join(t.TempDir(), "users.log"))
+	file.Close()
+	if _, err := file.Create(User{Username: "raj"}); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("Create after Close error %v, want os.ErrClosed", err)
+	}
+	if err := file.Compact(); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("Compact after Close error %v, want os.ErrClosed", err)
+	}
+	if err := file.Close(); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("second Close error %v, want os.ErrClosed", err)
+	}
+}
@@ -0,0 +1,75 @@
+package store
+
+import (
+	"sync"
+)
+
+// Memory is a UserStore keeping the users in memory only
+type Memory struct {
+	mu    sync.RWMutex
+	table table
+}
+
+func NewMemory() *Memory {
+	return &Memory{table: newTable()}
+}
+
+func (memory *Memory) Create(user User) (User, error) {
+	memory.mu.Lock()
+	defer memory.mu.Unlock()
+	user, err := memory.table.create(user)
+	if err != nil {
+		return User{}, err
+	}
+	memory.table.users[user.Username] = user
+	return user, nil
+}
+
+func (memory *Memory) Get(username string) (User, error) {
+	memory.mu.RLock()
+	defer memory.mu.RUnlock()
+	return memory.table.get(username)
+}
+
+func (memory *Memory) Update(user User) (User, error) {
+	memory.mu.Lock()
+	defer memory.mu.Unlock()
+	user, err := memory.table.update(user)
+	if err != nil {
+		return User{}, err
+	}
+	memory.table.users[user.Username] = user
+	return user, nil
+}
+
+func (memory *Memory) Deactivate(username string) (User, error) {
+	memory.mu.Lock()
+	defer memory.mu.Unlock()
+	user, err := memory.table.deactivate(username)
+	if err != nil {
+		return User{}, err
+	}
+	memory.table.users[username] = user
+	return user, nil
+}
+
+func (memory *Memory) Delete(username string) error {
+	memory.mu.Lock()
+	defer memory.mu.Unlock()
+	if _, err := memory.table.get(username); err != nil {
+		return err
+	}
+	delete(memory.table.users, username)
+	return nil
+}
+
+func (memory *Memory) List(after string, limit int) ([]User, error) {
+	memory.mu.RLock()
+	defer memory.mu.RUnlock()
+	return memory.table.list(after, limit)
+}
+
+// Close does nothing, the users are dropped with the store
+func (memory *Memory) Close() error {
+	return nil
+}
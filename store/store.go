@@ -0,0 +1,162 @@
+package store
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+/*
+
+UserStore keeps the users of main's user_t outside of local variables, with
+two implementations:
+
+	Memory  a map, gone when the process stops
+	File    the same map, rebuilt on Open from a log of every change
+
+Both copy users in and out, so changing a User returned by a store doesn't
+change the store, and both are safe for concurrent use.
+
+Errors a caller is expected to handle are Errors with a code, like error_t in
+main, and match the Err values of the same code with errors.Is:
+
+	_, err := users.Get("raj")
+	if errors.Is(err, store.ErrNotFound) {
+		fmt.Println(err) // code: 404, msg: user raj not found
+	}
+
+List pages through the users in username order: every page starts after
+the last username of the previous one, so users created or deleted between
+two pages don't shift the next one.
+
+*/
+
+type UserStore interface {
+	// Create adds user, whose username must not be taken
+	Create(user User) (User, error)
+	Get(username string) (User, error)
+	// Update replaces the password and the active flag of an existing user
+	Update(user User) (User, error)
+	// Deactivate clears the active flag, deactivating an inactive user is not an error
+	Deactivate(username string) (User, error)
+	Delete(username string) error
+	// List returns at most limit users with a username after after, sorted by
+	// username; "" starts from the first user
+	List(after string, limit int) ([]User, error)
+	Close() error
+}
+
+type User struct {
+	Username string    `json:"username"`
+	Password string    `json:"password"` // a hash from the password package
+	Active   bool      `json:"active"`
+	Created  time.Time `json:"created"`
+	Updated  time.Time `json:"updated"`
+}
+
+const (
+	CodeInvalid  = 400
+	CodeNotFound = 404
+	CodeConflict = 409
+)
+
+// Error is an error with a code, 404 when the user doesn't exist for instance
+type Error struct {
+	Code int
+	Msg  string
+}
+
+func (err Error) Error() string {
+	return fmt.Sprintf("code: %d, msg: %s", err.Code, err.Msg)
+}
+
+// Is makes errors.Is match errors by code, whatever their message
+func (err Error) Is(target error) bool {
+	other, ok := target.(Error)
+	return ok && other.Code == err.Code
+}
+
+var (
+	ErrInvalid  = Error{Code: CodeInvalid, Msg: "invalid request"}
+	ErrNotFound = Error{Code: CodeNotFound, Msg: "user not found"}
+	ErrConflict = Error{Code: CodeConflict, Msg: "user already exists"}
+)
+
+func notFound(username string) error {
+	return Error{Code: CodeNotFound, Msg: fmt.Sprintf("user %s not found", username)}
+}
+
+// table is the state shared by the implementations; its methods return the
+// result of an operation without applying it, so File can log it first
+type table struct {
+	users map[string]User
+	now   func() time.Time
+}
+
+func newTable() table {
+	// in UTC and without the monotonic clock reading, which the log can't
+	// keep, so a user compares equal before and after a restart of File
+	return table{users: make(map[string]User), now: func() time.Time { return time.Now().UTC().Round(0) }}
+}
+
+func (table *table) get(username string) (User, error) {
+	user, ok := table.users[username]
+	if !ok {
+		return User{}, notFound(username)
+	}
+	return user, nil
+}
+
+func (table *table) create(user User) (User, error) {
+	if strings.TrimSpace(user.Username) == "" {
+		return User{}, Error{Code: CodeInvalid, Msg: "empty username"}
+	}
+	if _, ok := table.users[user.Username]; ok {
+		return User{}, Error{Code: CodeConflict, Msg: fmt.Sprintf("user %s already exists", user.Username)}
+	}
+	user.Created = table.now()
+	user.Updated = user.Created
+	return user, nil
+}
+
+func (table *table) update(user User) (User, error) {
+	existing, err := table.get(user.Username)
+	if err != nil {
+		return User{}, err
+	}
+	existing.Password = user.Password
+	existing.Active = user.Active
+	existing.Updated = table.now()
+	return existing, nil
+}
+
+func (table *table) deactivate(username string) (User, error) {
+	existing, err := table.get(username)
+	if err != nil || !existing.Active {
+		return existing, err
+	}
+	existing.Active = false
+	existing.Updated = table.now()
+	return existing, nil
+}
+
+func (table *table) list(after string, limit int) ([]User, error) {
+	if limit <= 0 {
+		return nil, Error{Code: CodeInvalid, Msg: fmt.Sprintf("invalid page size %d", limit)}
+	}
+	usernames := make([]string, 0, len(table.users))
+	for username := range table.users {
+		if username > after {
+			usernames = append(usernames, username)
+		}
+	}
+	slices.Sort(usernames)
+	usernames = usernames[:min(limit, len(usernames))]
+
+	page := make([]User, len(usernames))
+	for index, username := range usernames {
+		page[index] = table.users[username]
+	}
+	return page, nil
+}
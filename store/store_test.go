@@ -0,0 +1,123 @@
+package store
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+// stores returns a new store of each implementation, the behavior tests run on all of them
+func stores(t *testing.T) map[string]UserStore {
+	t.Helper()
+	file, err := Open(filepath.Join(t.TempDir(), "users.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { file.Close() })
+	return map[string]UserStore{"Memory": NewMemory(), "File": file}
+}
+
+func TestCRUD(t *testing.T) {
+	for name, users := range stores(t) {
+		created, err := users.Create(User{Username: "raj", Password: "hash", Active: true})
+		if err != nil || created.Created.IsZero() || created.Updated != created.Created {
+			t.Fatalf("%s: Create = %+v, %v", name, created, err)
+		}
+
+		tests := []struct {
+			op   string
+			err  error
+			code int
+		}{
+			{"create taken", errOf(users.Create(User{Username: "raj"})), CodeConflict},
+			{"create empty", errOf(users.Create(User{Username: "  "})), CodeInvalid},
+			{"get missing", errOf(users.Get("sam")), CodeNotFound},
+			{"update missing", errOf(users.Update(User{Username: "sam"})), CodeNotFound},
+			{"deactivate missing", errOf(users.Deactivate("sam")), CodeNotFound},
+			{"delete missing", users.Delete("sam"), CodeNotFound},
+		}
+		for _, test := range tests {
+			var storeErr Error
+			if !errors.As(test.err, &storeErr) || storeErr.Code != test.code {
+				t.Errorf("%s: %s error %v, want code %d", name, test.op, test.err, test.code)
+			}
+		}
+		if err := users.Delete("sam"); !errors.Is(err, ErrNotFound) || errors.Is(err, ErrConflict) {
+			t.Errorf("%s: errors.Is doesn't match by code: %v", name, err)
+		}
+
+		updated, err := users.Update(User{Username: "raj", Password: "other", Active: true, Created: created.Created.Add(-1)})
+		if err != nil || updated.Password != "other" || updated.Created != created.Created {
+			t.Errorf("%s: Update = %+v, %v", name, updated, err)
+		}
+		deactivated, err := users.Deactivate("raj")
+		if err != nil || deactivated.Active {
+			t.Errorf("%s: Deactivate = %+v, %v", name, deactivated, err)
+		}
+		if again, err := users.Deactivate("raj"); err != nil || again != deactivated {
+			t.Errorf("%s: Deactivate twice = %+v, %v, want %+v", name, again, err, deactivated)
+		}
+		if got, err := users.Get("raj"); err != nil || got != deactivated {
+			t.Errorf("%s: Get = %+v, %v, want %+v", name, got, err, deactivated)
+		}
+
+		if err := users.Delete("raj"); err != nil {
+			t.Errorf("%s: Delete: %v", name, err)
+		}
+		if _, err := users.Get("raj"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("%s: Get after Delete error %v", name, err)
+		}
+	}
+}
+
+func errOf(_ User, err error) error {
+	return err
+}
+
+func TestList(t *testing.T) {
+	for name, users := range stores(t) {
+		for _, username := range []string{"dev", "amy", "cho", "bob", "eve"} {
+			users.Create(User{Username: username})
+		}
+		tests := []struct {
+			after string
+			limit int
+			want  []string
+		}{
+			{"", 2, []string{"amy", "bob"}},
+			{"bob", 2, []string{"cho", "dev"}},
+			{"dev", 2, []string{"eve"}},
+			{"eve", 2, []string{}},
+			{"b", 10, []string{"bob", "cho", "dev", "eve"}},
+			{"", 100, []string{"amy", "bob", "cho", "dev", "eve"}},
+		}
+		for _, test := range tests {
+			page, err := users.List(test.after, test.limit)
+			if err != nil || !sameUsernames(page, test.want) {
+				t.Errorf("%s: List(%q, %d) = %v, %v, want %v", name, test.after, test.limit, page, err, test.want)
+			}
+		}
+		if _, err := users.List("", 0); !errors.Is(err, ErrInvalid) {
+			t.Errorf("%s: List with limit 0 error %v, want ErrInvalid", name, err)
+		}
+
+		// deleting a user of a page doesn't shift the next one
+		page, _ := users.List("", 2)
+		users.Delete("amy")
+		if next, _ := users.List(page[len(page)-1].Username, 2); !sameUsernames(next, []string{"cho", "dev"}) {
+			t.Errorf("%s: next page after a delete = %v", name, next)
+		}
+	}
+}
+
+func sameUsernames(users []User, usernames []string) bool {
+	if len(users) != len(usernames) {
+		return false
+	}
+	for index, user := range users {
+		if user.Username != usernames[index] {
+			return false
+		}
+	}
+	return true
+}